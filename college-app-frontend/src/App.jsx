@@ -1,6 +1,6 @@
 // src/App.jsx (arquivo principal)
 
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import './App.css'; 
 import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
 import { 
@@ -10,6 +10,7 @@ import {
 
 // Importa o novo componente de gerenciamento de professores
 import TeacherManagement from './components/TeacherManagement';
+import { apiFetch } from './api';
 
 
 function App() {
@@ -30,6 +31,10 @@ function App() {
   const [formMessage, setFormMessage] = useState('');
 
   const [editingStudentId, setEditingStudentId] = useState(null);
+  const [editVersion, setEditVersion] = useState(null); // Versão usada no If-Match
+  // Idempotency-Key da criação em andamento: mantida após falha de rede para que o reenvio
+  // não crie um aluno duplicado, e descartada assim que o servidor responde.
+  const createStudentKeyRef = useRef(null);
   const [editName, setEditName] = useState('');
   const [editEnrollment, setEditEnrollment] = useState('');
   const [editCurrentYear, setEditCurrentYear] = useState('');
@@ -68,7 +73,7 @@ function App() {
     }
     
     try {
-      const response = await fetch(url);
+      const response = await apiFetch(url);
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar alunos');
@@ -85,7 +90,7 @@ function App() {
 
   const fetchAllStudentsForAssignment = async () => {
     try {
-      const response = await fetch('/api/students'); // Requisição sem filtros
+      const response = await apiFetch('/api/students'); // Requisição sem filtros
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar todos os alunos para atribuição');
@@ -108,7 +113,7 @@ function App() {
 
   const fetchAllSubjectsForAssignment = async () => {
     try {
-      const response = await fetch('/api/subjects');
+      const response = await apiFetch('/api/subjects');
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar todas as matérias para atribuição');
@@ -132,7 +137,7 @@ function App() {
       return;
     }
     try {
-      const response = await fetch(`/api/students/${studentId}`);
+      const response = await apiFetch(`/api/students/${studentId}`);
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar matérias atribuídas ao aluno');
@@ -167,7 +172,7 @@ function App() {
 
 
     try {
-      const response = await fetch(`/api/students/${selectedStudentIdForAssignment}/subjects/${selectedSubjectIdForAssignment}`, {
+      const response = await apiFetch(`/api/students/${selectedStudentIdForAssignment}/subjects/${selectedSubjectIdForAssignment}`, {
         method: 'POST',
       });
       const result = await response.json();
@@ -195,7 +200,7 @@ function App() {
     setAssignmentMessage('');
     if (window.confirm('Tem certeza que deseja remover esta matéria?')) {
       try {
-        const response = await fetch(`/api/students/${studentId}/subjects/${subjectId}`, {
+        const response = await apiFetch(`/api/students/${studentId}/subjects/${subjectId}`, {
           method: 'DELETE',
         });
         if (!response.ok) {
@@ -220,7 +225,9 @@ function App() {
     if (!newName || !newEnrollment || !newCurrentYear || !newShift) { setFormMessage('Erro: Todos os campos são obrigatórios!'); return; }
     const studentData = { name: newName, enrollment: newEnrollment, current_year: parseInt(newCurrentYear, 10), shift: newShift, };
     try {
-      const response = await fetch('/api/students', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(studentData), });
+      if (!createStudentKeyRef.current) { createStudentKeyRef.current = crypto.randomUUID(); }
+      const response = await apiFetch('/api/students', { method: 'POST', headers: { 'Content-Type': 'application/json', 'Idempotency-Key': createStudentKeyRef.current }, body: JSON.stringify(studentData), });
+      createStudentKeyRef.current = null;
       const result = await response.json(); if (!response.ok) { throw new Error(result.message || 'Erro ao criar aluno'); }
       setFormMessage('Sucesso: Aluno criado com sucesso!');
       setNewName(''); setNewEnrollment(''); setNewCurrentYear(''); setNewShift('');
@@ -228,10 +235,10 @@ function App() {
     } catch (err) { setFormMessage(`Erro: ${err.message}`); console.error("Erro ao enviar formulário:", err); }
   };
 
-  const handleDeleteStudent = async (id) => {
+  const handleDeleteStudent = async (id, version) => {
     if (window.confirm('Tem certeza que deseja excluir este aluno?')) {
       try {
-        const response = await fetch(`/api/students/${id}`, { method: 'DELETE' });
+        const response = await apiFetch(`/api/students/${id}`, { method: 'DELETE', headers: { 'If-Match': `"${version}"` } });
         if (!response.ok) { const errorData = await response.json(); throw new Error(errorData.message || `Erro ao excluir aluno com ID: ${id}`); }
         setFormMessage('Sucesso: Aluno excluído com sucesso!');
         fetchStudents(filterYear, filterShift); fetchAllStudentsForAssignment();
@@ -240,7 +247,7 @@ function App() {
   };
 
   const handleEditStudent = (student) => {
-    setEditingStudentId(student.id); setEditName(student.name); setEditEnrollment(student.enrollment); setEditCurrentYear(student.current_year.toString()); setEditShift(student.shift); setEditMessage('');
+    setEditingStudentId(student.id); setEditVersion(student.version); setEditName(student.name); setEditEnrollment(student.enrollment); setEditCurrentYear(student.current_year.toString()); setEditShift(student.shift); setEditMessage('');
   };
 
   const handleSaveEdit = async (e) => {
@@ -248,7 +255,7 @@ function App() {
     if (!editName || !editEnrollment || !editCurrentYear || !editShift) { setEditMessage('Erro: Todos os campos são obrigatórios!'); return; }
     const updatedStudentData = { id: editingStudentId, name: editName, enrollment: editEnrollment, current_year: parseInt(editCurrentYear, 10), shift: editShift, };
     try {
-      const response = await fetch(`/api/students/${editingStudentId}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(updatedStudentData), });
+      const response = await apiFetch(`/api/students/${editingStudentId}`, { method: 'PUT', headers: { 'Content-Type': 'application/json', 'If-Match': `"${editVersion}"` }, body: JSON.stringify(updatedStudentData), });
       const result = await response.json(); if (!response.ok) { throw new Error(result.message || 'Erro ao atualizar aluno'); }
       setEditMessage('Sucesso: Aluno atualizado com sucesso!'); setEditingStudentId(null); fetchStudents(filterYear, filterShift);
     } catch (err) { setEditMessage(`Erro: ${err.message}`); console.error("Erro ao atualizar aluno:", err); }
@@ -498,7 +505,7 @@ function App() {
                           <button className="edit-button" onClick={() => handleEditStudent(student)}>
                               <FontAwesomeIcon icon={faEdit} />
                           </button>
-                          <button className="delete-button" onClick={() => handleDeleteStudent(student.id)}>
+                          <button className="delete-button" onClick={() => handleDeleteStudent(student.id, student.version)}>
                               <FontAwesomeIcon icon={faTrashAlt} />
                           </button>
                       </div>
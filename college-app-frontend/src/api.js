@@ -0,0 +1,24 @@
+// src/api.js (acesso à API)
+
+// Gera `bytes` bytes aleatórios em hexadecimal, recusando o valor todo zero (inválido no traceparent).
+const randomHex = (bytes) => {
+  const values = new Uint8Array(bytes);
+  do {
+    crypto.getRandomValues(values);
+  } while (values.every((value) => value === 0));
+  return Array.from(values, (value) => value.toString(16).padStart(2, '0')).join('');
+};
+
+// Cabeçalho traceparent (W3C Trace Context) de uma nova requisição: versão 00, trace ID de
+// 16 bytes, span ID de 8 bytes e a flag "sampled", para que o backend continue o mesmo trace.
+export const newTraceparent = () => `00-${randomHex(16)}-${randomHex(8)}-01`;
+
+// fetch com o traceparent: use no lugar de fetch em todas as chamadas à API. Um traceparent
+// já informado em options.headers é mantido.
+export const apiFetch = (url, options = {}) => {
+  const headers = new Headers(options.headers);
+  if (!headers.has('traceparent')) {
+    headers.set('traceparent', newTraceparent());
+  }
+  return fetch(url, { ...options, headers });
+};
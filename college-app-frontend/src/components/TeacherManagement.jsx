@@ -6,6 +6,7 @@ import {
   faUserTie, faEdit, faTrashAlt, faPlus, faSave, faTimes, faFilter,
   faLink, faUnlink, faBook, faInfoCircle
 } from '@fortawesome/free-solid-svg-icons';
+import { apiFetch } from '../api';
 
 // O componente TeacherManagement será responsável por toda a lógica e UI dos professores
 function TeacherManagement() {
@@ -21,6 +22,7 @@ function TeacherManagement() {
 
   // Estados para edição de professor
   const [editingTeacherId, setEditingTeacherId] = useState(null);
+  const [editTeacherVersion, setEditTeacherVersion] = useState(null); // Versão usada no If-Match
   const [editTeacherName, setEditTeacherName] = useState('');
   const [editTeacherDepartment, setEditTeacherDepartment] = useState('');
   const [editTeacherEmail, setEditTeacherEmail] = useState(''); // <-- NOVO ESTADO PARA EMAIL DE EDIÇÃO
@@ -55,7 +57,7 @@ function TeacherManagement() {
     }
     
     try {
-      const response = await fetch(url);
+      const response = await apiFetch(url);
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar professores');
@@ -73,7 +75,7 @@ function TeacherManagement() {
   // --- Funções para Gerenciamento de Matérias do Professor ---
   const fetchAllSubjectsForTeacherAssignment = async () => {
     try {
-      const response = await fetch('/api/subjects');
+      const response = await apiFetch('/api/subjects');
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar matérias para atribuição');
@@ -99,7 +101,7 @@ function TeacherManagement() {
     try {
       // Sua API GetAllTeachersHandler já retorna as matérias associadas se o modelo de professor tiver 'Subjects'
       // Se não, você precisará de uma rota GET /api/teachers/{id}/subjects
-      const response = await fetch(`/api/teachers/${teacherId}`); // Buscar professor por ID, assumindo que ele tem subjects
+      const response = await apiFetch(`/api/teachers/${teacherId}`); // Buscar professor por ID, assumindo que ele tem subjects
       if (!response.ok) {
         const err = await response.json();
         throw new Error(err.message || 'Erro ao buscar matérias atribuídas ao professor');
@@ -131,7 +133,7 @@ function TeacherManagement() {
     }
 
     try {
-      const response = await fetch(`/api/teachers/${teacherId}/subjects/${subjectId}`, {
+      const response = await apiFetch(`/api/teachers/${teacherId}/subjects/${subjectId}`, {
         method: 'POST',
       });
       const result = await response.json();
@@ -156,7 +158,7 @@ function TeacherManagement() {
     setAssignmentMessage('');
     if (window.confirm('Tem certeza que deseja remover esta matéria do professor?')) {
       try {
-        const response = await fetch(`/api/teachers/${teacherId}/subjects/${subjectId}`, {
+        const response = await apiFetch(`/api/teachers/${teacherId}/subjects/${subjectId}`, {
           method: 'DELETE',
         });
         if (!response.ok) {
@@ -191,7 +193,7 @@ function TeacherManagement() {
       email: newTeacherEmail, // <-- INCLUÍDO NO PAYLOAD
     };
     try {
-      const response = await fetch('/api/teachers', {
+      const response = await apiFetch('/api/teachers', {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(teacherData),
@@ -212,6 +214,7 @@ function TeacherManagement() {
   // Handler para iniciar edição
   const handleEditTeacher = (teacher) => {
     setEditingTeacherId(teacher.id);
+    setEditTeacherVersion(teacher.version);
     setEditTeacherName(teacher.name);
     setEditTeacherDepartment(teacher.department);
     setEditTeacherEmail(teacher.email); // <-- CARREGAR EMAIL EXISTENTE
@@ -237,9 +240,9 @@ function TeacherManagement() {
       email: editTeacherEmail, // <-- INCLUÍDO NO PAYLOAD
     };
     try {
-      const response = await fetch(`/api/teachers/${editingTeacherId}`, {
+      const response = await apiFetch(`/api/teachers/${editingTeacherId}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', 'If-Match': `"${editTeacherVersion}"` },
         body: JSON.stringify(updatedTeacherData),
       });
       const result = await response.json();
@@ -261,10 +264,10 @@ function TeacherManagement() {
   };
 
   // Handler para deletar professor
-  const handleDeleteTeacher = async (id) => {
+  const handleDeleteTeacher = async (id, version) => {
     if (window.confirm('Tem certeza que deseja excluir este professor?')) {
       try {
-        const response = await fetch(`/api/teachers/${id}`, { method: 'DELETE' });
+        const response = await apiFetch(`/api/teachers/${id}`, { method: 'DELETE', headers: { 'If-Match': `"${version}"` } });
         if (!response.ok) {
           const errorData = await response.json();
           throw new Error(errorData.message || `Erro ao excluir professor com ID: ${id}`);
@@ -441,7 +444,7 @@ function TeacherManagement() {
                       <button className="edit-button" onClick={() => handleEditTeacher(teacher)}>
                           <FontAwesomeIcon icon={faEdit} />
                       </button>
-                      <button className="delete-button" onClick={() => handleDeleteTeacher(teacher.id)}>
+                      <button className="delete-button" onClick={() => handleDeleteTeacher(teacher.id, teacher.version)}>
                           <FontAwesomeIcon icon={faTrashAlt} />
                       </button>
                   </div>
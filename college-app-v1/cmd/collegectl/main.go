@@ -0,0 +1,270 @@
+// cmd/collegectl/main.go
+
+// collegectl é a ferramenta de linha de comando para tarefas de operação (cadastro e importação
+// de alunos, renumeração de matrículas, migrações, exportações, dados fictícios, cópia de segurança), executada diretamente contra o
+// banco e usando os mesmos serviços da API. A configuração é a mesma do servidor
+// (variáveis de ambiente e, opcionalmente, --config ou CONFIG_FILE).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
+
+	"college-app-v1/config"
+	"college-app-v1/logging"
+	"college-app-v1/repositories"
+	"college-app-v1/reqctx"
+	"college-app-v1/services"
+
+	"github.com/google/uuid"
+)
+
+const usage = `Uso: collegectl [opções globais] <comando> [opções]
+
+Comandos:
+  students create             cadastra um aluno
+  students import             importa alunos de um CSV
+  students list               lista alunos por ano e turno
+  students reassign-subjects  substitui as matérias de um aluno
+  teachers reassign-subjects  substitui as matérias de um professor
+  enrollments regenerate      gera novamente as matrículas no formato configurado
+  migrate                     cria ou atualiza o esquema do banco
+  export <entidade>           exporta students, teachers ou subjects (csv, xlsx ou json)
+  seed                        grava dados fictícios reproduzíveis (desenvolvimento e testes de carga)
+  backup                      copia todos os dados para um arquivo JSON lines
+  restore                     restaura uma cópia de segurança em um banco vazio
+
+Opções globais:
+`
+
+// errUsage indica erro de uso (comando ou opções inválidas); a mensagem já foi exibida.
+// errHelp indica que a ajuda foi pedida (--help) e exibida.
+var (
+	errUsage = errors.New("uso inválido")
+	errHelp  = errors.New("ajuda exibida")
+)
+
+// app reúne a configuração, a saída e os serviços compartilhados pelos comandos.
+type app struct {
+	cfg    *config.Config
+	out    *output
+	logger *slog.Logger
+
+	students     *services.StudentService
+	curriculum   *services.CurriculumService
+	imports      *services.ImportService
+	associations *services.AssociationService
+	enrollments  *services.EnrollmentService
+	teachers     *services.TeacherService
+	subjects     *services.SubjectService
+	departments  *services.DepartmentService
+	backups      *services.BackupService
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executa a linha de comando e retorna o código de saída: 0 em caso de sucesso, 1 em caso
+// de erro e 2 em caso de uso inválido.
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("collegectl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		global.PrintDefaults()
+	}
+	configPath := global.String("config", "", "arquivo de configuração YAML (padrão: CONFIG_FILE)")
+	outputFormat := global.String("output", outputTable, "formato da saída: table ou json")
+	verbose := global.Bool("verbose", false, "exibe os logs da aplicação (nível de LOG_LEVEL) na saída de erro")
+	wait := global.Duration("wait", 0, "espera pelo banco de dados, com novas tentativas (ex: 30s; padrão: uma única tentativa)")
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *outputFormat != outputTable && *outputFormat != outputJSON {
+		fmt.Fprintf(stderr, "collegectl: formato de saída inválido: %q (use table ou json)\n", *outputFormat)
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "collegectl: %v\n", err)
+		return 1
+	}
+	cfg.Database.StartupTimeout = *wait
+
+	// Os logs vão para a saída de erro, para não misturar com a saída do comando; sem --verbose,
+	// apenas avisos e erros.
+	level := slog.LevelWarn
+	if *verbose {
+		level = cfg.Logging.Level
+	}
+	logger := logging.New(stderr, logging.Options{Level: level, JSON: cfg.Logging.Format == "json", Redact: cfg.Logging.Redact})
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// As alterações feitas pelo collegectl aparecem na auditoria com o usuário do sistema.
+	ctx = reqctx.WithActor(ctx, "cli:"+currentUser())
+	ctx = reqctx.WithRequestID(ctx, uuid.NewString())
+
+	a := &app{cfg: cfg, out: &output{format: *outputFormat, w: stdout}, logger: logger}
+	err = a.dispatch(ctx, global.Args(), stderr)
+	if errors.Is(err, errHelp) {
+		return 0
+	}
+	if errors.Is(err, errUsage) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "collegectl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// dispatch executa o comando em args (ex: ["students", "list", "--year", "2"]).
+func (a *app) dispatch(ctx context.Context, args []string, stderr io.Writer) error {
+	defer config.CloseDB()
+	command := args[0]
+	var handler func(context.Context, []string, io.Writer) error
+	switch command {
+	case "students", "teachers", "enrollments":
+		if len(args) < 2 {
+			fmt.Fprintf(stderr, "collegectl: informe o subcomando de %s\n", command)
+			return errUsage
+		}
+		switch command + " " + args[1] {
+		case "students create":
+			handler = a.createStudent
+		case "students import":
+			handler = a.importStudents
+		case "students list":
+			handler = a.listStudents
+		case "students reassign-subjects":
+			handler = a.reassignStudentSubjects
+		case "teachers reassign-subjects":
+			handler = a.reassignTeacherSubjects
+		case "enrollments regenerate":
+			handler = a.regenerateEnrollments
+		default:
+			fmt.Fprintf(stderr, "collegectl: subcomando desconhecido: %s %s\n", command, args[1])
+			return errUsage
+		}
+		args = args[2:]
+	case "export":
+		handler = a.exportData
+		args = args[1:]
+	case "migrate":
+		handler = a.migrate
+		args = args[1:]
+	case "seed":
+		handler = a.seedData
+		args = args[1:]
+	case "backup":
+		handler = a.backup
+		args = args[1:]
+	case "restore":
+		handler = a.restore
+		args = args[1:]
+	default:
+		fmt.Fprintf(stderr, "collegectl: comando desconhecido: %s\n", command)
+		return errUsage
+	}
+
+	return handler(ctx, args, stderr)
+}
+
+// openDB abre o pool de conexões e aguarda o banco responder (por até --wait).
+func (a *app) openDB() error {
+	if err := config.OpenDB(a.cfg.Database); err != nil {
+		return err
+	}
+	return config.WaitForDB(a.cfg.Database)
+}
+
+// connect abre o banco, confere se o esquema está atualizado e monta os serviços. Os comandos
+// a chamam depois de validar as opções, para que erros de uso não dependam do banco.
+func (a *app) connect(ctx context.Context) error {
+	if err := a.openDB(); err != nil {
+		return err
+	}
+	healthRepo := repositories.NewHealthRepository(config.DB)
+	version, err := healthRepo.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < config.SchemaVersion {
+		return fmt.Errorf("esquema do banco na versão %d, esperada %d: execute 'collegectl migrate'", version, config.SchemaVersion)
+	}
+
+	subjectRepo := repositories.NewSubjectRepository(config.DB, a.logger)
+	studentRepo := repositories.NewStudentRepository(config.DB, a.logger)
+	teacherRepo := repositories.NewTeacherRepository(config.DB, a.logger)
+	programRepo := repositories.NewProgramRepository(config.DB, a.logger)
+	departmentRepo := repositories.NewDepartmentRepository(config.DB, a.logger)
+	auditRepo := repositories.NewAuditRepository(config.DB, a.logger)
+	transactor := repositories.NewTransactor(config.DB)
+
+	auditService := services.NewAuditService(auditRepo, a.logger)
+	enrollmentPolicy := services.EnrollmentPolicy{SequenceDigits: a.cfg.Enrollment.SequenceDigits, Shifts: a.cfg.Enrollment.Shifts}
+	workloadPolicy := services.WorkloadPolicy{HoursPerCredit: a.cfg.Workload.HoursPerCredit, MaxWeeklyHours: a.cfg.Workload.MaxWeeklyHours, Reject: a.cfg.Workload.Policy == "reject"}
+	a.students = services.NewStudentService(transactor, studentRepo, subjectRepo, programRepo, enrollmentPolicy, auditService, a.logger)
+	a.curriculum = services.NewCurriculumService(transactor, studentRepo, subjectRepo, programRepo, enrollmentPolicy, auditService, a.logger)
+	a.imports = services.NewImportService(transactor, studentRepo, teacherRepo, subjectRepo, departmentRepo, enrollmentPolicy, auditService, a.logger)
+	a.associations = services.NewAssociationService(transactor, studentRepo, teacherRepo, subjectRepo, workloadPolicy, auditService)
+	a.enrollments = services.NewEnrollmentService(transactor, studentRepo, enrollmentPolicy, auditService, a.logger)
+	a.teachers = services.NewTeacherService(transactor, teacherRepo, subjectRepo, departmentRepo, workloadPolicy, auditService, a.logger)
+	a.subjects = services.NewSubjectService(transactor, subjectRepo, auditService)
+	a.departments = services.NewDepartmentService(transactor, departmentRepo, teacherRepo, subjectRepo, auditService)
+	a.backups = services.NewBackupService(transactor, repositories.NewBackupRepository(config.DB, a.logger), config.SchemaVersion, a.logger)
+	return nil
+}
+
+// newFlagSet cria o conjunto de opções de um subcomando, com erros de uso na saída de erro.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("collegectl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags interpreta as opções de um subcomando, convertendo falhas em errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelp
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "%s: argumento inesperado: %s\n", fs.Name(), fs.Arg(0))
+		return errUsage
+	}
+	return nil
+}
+
+// currentUser identifica quem executa o comando, para a auditoria.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "desconhecido"
+}
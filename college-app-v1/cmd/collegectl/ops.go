@@ -0,0 +1,228 @@
+// cmd/collegectl/ops.go
+package main
+
+import (
+	"bufio"
+	"college-app-v1/config"
+	"college-app-v1/export"
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// regenerateEnrollments: enrollments regenerate [--year 2025] [--dry-run]
+func (a *app) regenerateEnrollments(ctx context.Context, args []string, stderr io.Writer) error {
+	fs := newFlagSet("enrollments regenerate", stderr)
+	year := fs.Int("year", 0, "apenas matrículas deste ano de ingresso (0 = todas)")
+	dryRun := fs.Bool("dry-run", false, "apenas mostra as alterações, sem gravar")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+	result, err := a.enrollments.RegenerateEnrollments(ctx, *year, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"ALUNO", "DE", "PARA", "OBSERVAÇÃO", "ID"}}
+	summary := fmt.Sprintf("%d alunos, %d matrículas alteradas, %d ignorados.", result.Total, result.Changed, len(result.Skipped))
+	if result.DryRun {
+		summary += " (dry run, nada foi gravado)"
+	}
+	t.summary = []string{summary}
+	for _, change := range result.Changes {
+		t.add(change.Name, change.From, change.To, "", change.StudentID)
+	}
+	for _, skipped := range result.Skipped {
+		t.add(skipped.Name, skipped.From, "-", skipped.Reason, skipped.StudentID)
+	}
+	return a.out.print(result, t)
+}
+
+// migrate: migrate. Cria ou atualiza o esquema, como na inicialização do servidor.
+func (a *app) migrate(ctx context.Context, args []string, stderr io.Writer) error {
+	if err := parseFlags(newFlagSet("migrate", stderr), args); err != nil {
+		return err
+	}
+	if err := a.openDB(); err != nil {
+		return err
+	}
+
+	healthRepo := repositories.NewHealthRepository(config.DB)
+	before, err := healthRepo.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if err := config.Migrate(a.cfg.Database); err != nil {
+		return err
+	}
+	after, err := healthRepo.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]int{"previous_version": before, "schema_version": after}
+	t := &table{summary: []string{fmt.Sprintf("Esquema na versão %d (antes: %d).", after, before)}}
+	return a.out.print(result, t)
+}
+
+// exportData: export <students|teachers|subjects> [--format csv|xlsx|json] [--columns a,b] [--out arquivo]
+// [--include-deleted] [--year N] [--shift M] [--department D]. Os registros são escritos em
+// streaming, com as mesmas colunas da exportação da API.
+func (a *app) exportData(ctx context.Context, args []string, stderr io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(stderr, "export: informe a entidade (students, teachers ou subjects)")
+		return errUsage
+	}
+	entity := args[0]
+	fs := newFlagSet("export "+entity, stderr)
+	format := fs.String("format", export.FormatCSV, "formato do arquivo: csv, xlsx ou json")
+	columnList := fs.String("columns", "", "colunas exportadas, separadas por vírgula (csv e xlsx; padrão: as da API)")
+	path := fs.String("out", "-", "arquivo de saída; - escreve na saída padrão")
+	includeDeleted := fs.Bool("include-deleted", false, "inclui registros excluídos")
+	year := fs.Int("year", 0, "students: filtra pelo ano atual (0 = todos)")
+	shift := fs.String("shift", "", "students: filtra pelo turno")
+	department := fs.String("department", "", "teachers: filtra pelo departamento")
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+
+	var available map[string]export.Column
+	var defaults []string
+	var stream func(fn func(record interface{}) error) error
+	switch entity {
+	case "students":
+		available, defaults = export.StudentColumns, export.DefaultStudentColumns
+		var yearFilter *int
+		if *year != 0 {
+			yearFilter = year
+		}
+		stream = func(fn func(record interface{}) error) error {
+			return a.students.StreamStudents(ctx, yearFilter, *shift, *includeDeleted, func(s *models.Student) error { return fn(s) })
+		}
+	case "teachers":
+		available, defaults = export.TeacherColumns, export.DefaultTeacherColumns
+		stream = func(fn func(record interface{}) error) error {
+			return a.teachers.StreamTeachers(ctx, "", *department, "", *includeDeleted, func(t *models.Teacher) error { return fn(t) })
+		}
+	case "subjects":
+		available, defaults = export.SubjectColumns, export.DefaultSubjectColumns
+		stream = func(fn func(record interface{}) error) error {
+			return a.subjects.StreamSubjects(ctx, *includeDeleted, func(s *models.Subject) error { return fn(s) })
+		}
+	default:
+		fmt.Fprintf(stderr, "export: entidade desconhecida: %s (use students, teachers ou subjects)\n", entity)
+		return errUsage
+	}
+
+	names := defaults
+	if *columnList != "" {
+		names = nil
+		for _, name := range strings.Split(*columnList, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	columns, err := export.SelectColumns(available, names)
+	if err != nil {
+		return err
+	}
+	if *format != export.FormatCSV && *format != export.FormatXLSX && *format != "json" {
+		return fmt.Errorf("formato de exportação inválido: %q (use csv, xlsx ou json)", *format)
+	}
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *path != "-" {
+		file, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+
+	count := 0
+	if *format == "json" {
+		err = exportJSON(buffered, stream, &count)
+	} else {
+		err = exportTable(buffered, *format, entity, names, columns, stream, &count)
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao exportar %s: %w", entity, err)
+	}
+	if *path != "-" {
+		fmt.Fprintf(stderr, "%d registros exportados para %s.\n", count, *path)
+	}
+	return nil
+}
+
+// exportTable escreve os registros como CSV ou XLSX, com a linha de cabeçalho.
+func exportTable(w io.Writer, format, entity string, names []string, columns []export.Column, stream func(func(interface{}) error) error, count *int) error {
+	tw, err := export.NewTableWriter(format, w, entity)
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(names))
+	for i, name := range names {
+		header[i] = name
+	}
+	if err := tw.WriteRow(header); err != nil {
+		return err
+	}
+	err = stream(func(record interface{}) error {
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			row[i] = column(record)
+		}
+		*count++
+		return tw.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// exportJSON escreve os registros completos como um array JSON, um registro por linha.
+func exportJSON(w io.Writer, stream func(func(interface{}) error) error, count *int) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	err := stream(func(record interface{}) error {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		separator := "\n"
+		if *count > 0 {
+			separator = ",\n"
+		}
+		*count++
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
@@ -0,0 +1,66 @@
+// cmd/collegectl/output.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formatos de saída (--output).
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// output escreve o resultado dos comandos como tabela alinhada ou como JSON.
+type output struct {
+	format string
+	w      io.Writer
+}
+
+// table é a representação tabular de um resultado; summary, se houver, vem antes das linhas
+// (ex: "3 alunos importados").
+type table struct {
+	summary []string
+	header  []string
+	rows    [][]interface{}
+}
+
+// add acrescenta uma linha à tabela.
+func (t *table) add(cells ...interface{}) {
+	t.rows = append(t.rows, cells)
+}
+
+// print escreve value em JSON ou t como tabela, conforme o formato escolhido.
+func (o *output) print(value interface{}, t *table) error {
+	if o.format == outputJSON {
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	for _, line := range t.summary {
+		if _, err := fmt.Fprintln(o.w, line); err != nil {
+			return err
+		}
+	}
+	if len(t.header) == 0 || len(t.rows) == 0 {
+		return nil
+	}
+	if len(t.summary) > 0 {
+		fmt.Fprintln(o.w)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprint(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
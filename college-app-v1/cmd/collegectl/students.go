@@ -0,0 +1,194 @@
+// cmd/collegectl/students.go
+package main
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/services"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// createStudent: students create --name N --shift M [--year 1] [--program ID] [--curriculum ID] [--with-curriculum]
+func (a *app) createStudent(ctx context.Context, args []string, stderr io.Writer) error {
+	fs := newFlagSet("students create", stderr)
+	name := fs.String("name", "", "nome do aluno (obrigatório)")
+	shift := fs.String("shift", "", "turno do aluno (obrigatório)")
+	year := fs.Int("year", 1, "ano atual do aluno no curso")
+	program := fs.String("program", "", "ID do curso")
+	curriculum := fs.String("curriculum", "", "ID da grade curricular (padrão: a mais recente do curso)")
+	withCurriculum := fs.Bool("with-curriculum", false, "matricula o aluno nas matérias obrigatórias do seu ano")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+	student := &models.Student{Name: *name, Shift: *shift, CurrentYear: *year, ProgramID: *program, CurriculumID: *curriculum}
+	if *withCurriculum {
+		if _, err := a.curriculum.CreateStudentWithCurriculum(ctx, student); err != nil {
+			return err
+		}
+	} else if err := a.students.CreateStudent(ctx, student); err != nil {
+		return err
+	}
+
+	t := &table{summary: []string{fmt.Sprintf("Aluno cadastrado com a matrícula %s.", student.Enrollment)}}
+	studentRows(t, []models.Student{*student})
+	return a.out.print(student, t)
+}
+
+// importStudents: students import --file alunos.csv [--dry-run]
+func (a *app) importStudents(ctx context.Context, args []string, stderr io.Writer) error {
+	fs := newFlagSet("students import", stderr)
+	path := fs.String("file", "", "arquivo CSV com as colunas name, shift e current_year; - lê da entrada padrão")
+	dryRun := fs.Bool("dry-run", false, "apenas valida o arquivo, sem gravar")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *path == "" {
+		fmt.Fprintln(stderr, "students import: informe --file")
+		return errUsage
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+	file := os.Stdin
+	if *path != "-" {
+		var err error
+		if file, err = os.Open(*path); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+	result, err := a.imports.ImportStudents(ctx, file, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"LINHA", "CAMPO", "ERRO"}}
+	switch {
+	case len(result.Errors) > 0:
+		t.summary = []string{fmt.Sprintf("%d linhas lidas, %d com erros; nada foi gravado.", result.TotalRows, len(result.Errors))}
+	case result.DryRun:
+		t.summary = []string{fmt.Sprintf("%d linhas válidas (dry run, nada foi gravado).", result.TotalRows)}
+	default:
+		t.summary = []string{fmt.Sprintf("%d alunos importados.", result.Imported)}
+	}
+	for _, rowErr := range result.Errors {
+		t.add(rowErr.Row, rowErr.Field, rowErr.Message)
+	}
+	if err := a.out.print(result, t); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("importação recusada: %d erros", len(result.Errors))
+	}
+	return nil
+}
+
+// listStudents: students list [--year N] [--shift M] [--include-deleted]
+func (a *app) listStudents(ctx context.Context, args []string, stderr io.Writer) error {
+	fs := newFlagSet("students list", stderr)
+	year := fs.Int("year", 0, "filtra pelo ano atual do aluno (0 = todos)")
+	shift := fs.String("shift", "", "filtra pelo turno")
+	includeDeleted := fs.Bool("include-deleted", false, "inclui alunos excluídos")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+	var yearFilter *int
+	if *year != 0 {
+		yearFilter = year
+	}
+	students, err := a.students.GetAllStudents(ctx, yearFilter, *shift, *includeDeleted)
+	if err != nil {
+		return err
+	}
+	if students == nil {
+		students = []models.Student{}
+	}
+
+	t := &table{summary: []string{fmt.Sprintf("%d alunos.", len(students))}}
+	studentRows(t, students)
+	return a.out.print(students, t)
+}
+
+// studentRows preenche t com uma linha por aluno.
+func studentRows(t *table, students []models.Student) {
+	t.header = []string{"MATRÍCULA", "NOME", "ANO", "TURNO", "MATÉRIAS", "ID"}
+	for _, student := range students {
+		enrollment := student.Enrollment
+		if student.DeletedAt != nil {
+			enrollment += " (excluído)"
+		}
+		t.add(enrollment, student.Name, student.CurrentYear, student.Shift, len(student.Subjects), student.ID)
+	}
+}
+
+// reassignStudentSubjects: students reassign-subjects --id ID --subjects ID1,ID2
+func (a *app) reassignStudentSubjects(ctx context.Context, args []string, stderr io.Writer) error {
+	return a.reassignSubjects(ctx, "students reassign-subjects", "aluno", args, stderr, func(ctx context.Context, id string, subjectIDs []string) (*services.AssociationResult, error) {
+		return a.associations.ReplaceStudentSubjects(ctx, id, subjectIDs) // Resolvido após connect
+	})
+}
+
+// reassignTeacherSubjects: teachers reassign-subjects --id ID --subjects ID1,ID2
+func (a *app) reassignTeacherSubjects(ctx context.Context, args []string, stderr io.Writer) error {
+	return a.reassignSubjects(ctx, "teachers reassign-subjects", "professor", args, stderr, func(ctx context.Context, id string, subjectIDs []string) (*services.AssociationResult, error) {
+		return a.associations.ReplaceTeacherSubjects(ctx, id, subjectIDs) // Resolvido após connect
+	})
+}
+
+// reassignSubjects substitui o conjunto de matérias de um aluno ou professor com replace.
+func (a *app) reassignSubjects(ctx context.Context, name, owner string, args []string, stderr io.Writer,
+	replace func(ctx context.Context, id string, subjectIDs []string) (*services.AssociationResult, error)) error {
+	fs := newFlagSet(name, stderr)
+	id := fs.String("id", "", "ID do "+owner+" (obrigatório)")
+	subjects := fs.String("subjects", "", "IDs das matérias, separados por vírgula (vazio remove todas)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == "" {
+		fmt.Fprintf(stderr, "%s: informe --id\n", name)
+		return errUsage
+	}
+
+	subjectIDs := []string{}
+	for _, subjectID := range strings.Split(*subjects, ",") {
+		if subjectID = strings.TrimSpace(subjectID); subjectID != "" {
+			subjectIDs = append(subjectIDs, subjectID)
+		}
+	}
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+	result, err := replace(ctx, *id, subjectIDs)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"MATÉRIA", "SITUAÇÃO", "MENSAGEM"}}
+	if result.Applied {
+		t.summary = []string{fmt.Sprintf("%d adicionadas, %d removidas, %d inalteradas.", result.Added, result.Removed, result.Unchanged)}
+	} else {
+		t.summary = []string{fmt.Sprintf("Nada foi gravado: %d matérias com falha.", result.Failed)}
+	}
+	for _, item := range result.Items {
+		t.add(item.ID, item.Status, item.Message)
+	}
+	if err := a.out.print(result, t); err != nil {
+		return err
+	}
+	if !result.Applied {
+		return fmt.Errorf("associação recusada: %d matérias com falha", result.Failed)
+	}
+	return nil
+}
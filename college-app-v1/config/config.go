@@ -0,0 +1,299 @@
+// config/config.go
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne toda a configuração da aplicação, já interpretada e validada.
+//
+// Cada campo vem de uma variável de ambiente (tag env) ou, opcionalmente, de um arquivo YAML
+// (CONFIG_FILE ou --config) em que as seções e chaves seguem as tags yaml, por exemplo:
+//
+//	server:
+//	  port: 8080
+//	  read_timeout: 30s
+//	cors:
+//	  allowed_origins: [https://app.exemplo.edu]
+//
+// As variáveis de ambiente têm precedência sobre o arquivo, e o arquivo sobre os padrões.
+type Config struct {
+	Env           string                `yaml:"env" env:"APP_ENV"`                     // "development" (padrão) ou "production"
+	AdminAPIToken string                `yaml:"admin_api_token" env:"ADMIN_API_TOKEN"` // Segredo: mascarado em --print-config
+	Server        ServerConfig          `yaml:"server"`
+	Database      DatabaseConfig        `yaml:"database"`
+	Logging       LoggingConfig         `yaml:"logging"`
+	Tracing       TracingConfig         `yaml:"tracing"`
+	CORS          CORSConfig            `yaml:"cors"`
+	Security      SecurityHeadersConfig `yaml:"security"`
+	RateLimit     RateLimitConfig       `yaml:"rate_limit"`
+	Idempotency   IdempotencyConfig     `yaml:"idempotency"`
+	Retention     RetentionConfig       `yaml:"retention"`
+	Workload      WorkloadConfig        `yaml:"workload"`
+	Degree        DegreeConfig          `yaml:"degree"`
+	Enrollment    EnrollmentConfig      `yaml:"enrollment"`
+	Features      FeaturesConfig        `yaml:"features"`
+}
+
+// fileValues guarda os valores lidos do arquivo de configuração, indexados pelo nome da
+// variável de ambiente correspondente. Preenchido por Load antes de executar os loaders.
+var fileValues = map[string]string{}
+
+// getenv retorna a variável de ambiente name ou, se não estiver definida, o valor do arquivo
+// de configuração. Todos os loaders do pacote leem por aqui.
+func getenv(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fileValues[name]
+}
+
+// Load lê o arquivo de configuração (path, ou CONFIG_FILE se path for vazio; nenhum se ambos
+// estiverem vazios), aplica as variáveis de ambiente e valida tudo. Os erros de todas as seções
+// são reunidos, para que a inicialização aponte todos os problemas de uma vez.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	fileValues = map[string]string{}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileValues = values
+	}
+
+	cfg := &Config{Env: AppEnv(), AdminAPIToken: AdminAPIToken()}
+	var errs []error
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var err error
+	cfg.Server, err = LoadServerConfig()
+	collect(err)
+	cfg.Database, err = LoadDatabaseConfig()
+	collect(err)
+	cfg.Logging, err = LoadLoggingConfig()
+	collect(err)
+	cfg.Tracing, err = LoadTracingConfig()
+	collect(err)
+	cfg.CORS, err = LoadCORSConfig()
+	collect(err)
+	cfg.Security, err = LoadSecurityHeadersConfig()
+	collect(err)
+	cfg.RateLimit, err = LoadRateLimitConfig()
+	collect(err)
+	cfg.Idempotency, err = LoadIdempotencyConfig()
+	collect(err)
+	cfg.Retention, err = LoadRetentionConfig()
+	collect(err)
+	cfg.Workload, err = LoadWorkloadConfig()
+	collect(err)
+	cfg.Degree, err = LoadDegreeConfig()
+	collect(err)
+	cfg.Enrollment, err = LoadEnrollmentConfig()
+	collect(err)
+	cfg.Features, err = LoadFeaturesConfig()
+	collect(err)
+	if cfg.Env != EnvDevelopment && cfg.Env != EnvProduction {
+		collect(fmt.Errorf("APP_ENV inválido: %q (use 'development' ou 'production')", cfg.Env))
+	}
+
+	if len(errs) > 0 {
+		return cfg, &InvalidConfigError{Errors: errs}
+	}
+	return cfg, nil
+}
+
+// InvalidConfigError lista todos os problemas encontrados na configuração.
+type InvalidConfigError struct {
+	Errors []error
+}
+
+func (e *InvalidConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString("configuração inválida:")
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *InvalidConfigError) Unwrap() []error {
+	return e.Errors
+}
+
+// readConfigFile lê o arquivo YAML e converte cada chave para o valor textual da variável de
+// ambiente correspondente (listas separadas por vírgula, mapas como "chave=valor,...").
+// Chaves desconhecidas são rejeitadas, para que erros de digitação não passem despercebidos.
+func readConfigFile(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de configuração: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("arquivo de configuração %s inválido: %w", file, err)
+	}
+
+	envNames := map[string]string{} // "server.read_timeout" -> "SERVER_READ_TIMEOUT"
+	collectEnvNames(reflect.TypeOf(Config{}), "", envNames)
+
+	values := map[string]string{}
+	var errs []error
+	var walk func(prefix string, node map[string]interface{})
+	walk = func(prefix string, node map[string]interface{}) {
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Erros em ordem estável
+		for _, key := range keys {
+			value := node[key]
+			path := prefix + key
+			envName, isField := envNames[path]
+			if section, isSection := value.(map[string]interface{}); isSection && !isField {
+				walk(path+".", section)
+				continue
+			}
+			if !isField {
+				errs = append(errs, fmt.Errorf("%s: chave desconhecida %s", file, path))
+				continue
+			}
+			text, err := configValueString(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", file, path, err))
+				continue
+			}
+			values[envName] = text
+		}
+	}
+	walk("", raw)
+	if len(errs) > 0 {
+		return nil, &InvalidConfigError{Errors: errs}
+	}
+	return values, nil
+}
+
+// collectEnvNames percorre as tags yaml/env de t, mapeando o caminho de cada campo no arquivo
+// para o nome da variável de ambiente.
+func collectEnvNames(t reflect.Type, prefix string, names map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		if env := field.Tag.Get("env"); env != "" {
+			names[prefix+key] = env
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			collectEnvNames(field.Type, prefix+key+".", names)
+		}
+	}
+}
+
+// configValueString converte um valor do YAML para o formato textual das variáveis de ambiente.
+func configValueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			text, err := configValueString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			text, err := configValueString(v[key])
+			if err != nil {
+				return "", err
+			}
+			entries[i] = key + "=" + text
+		}
+		return strings.Join(entries, ","), nil
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("tipo de valor não suportado: %T", value)
+	}
+}
+
+// Redacted retorna uma cópia da configuração com os segredos mascarados, para exibição.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.AdminAPIToken = maskSecret(c.AdminAPIToken)
+	redacted.Database.URL = maskDatabaseURL(c.Database.URL)
+	return &redacted
+}
+
+// WriteYAML escreve a configuração efetiva (com os segredos mascarados) em YAML, no mesmo
+// formato aceito pelo arquivo de configuração.
+func (c *Config) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+const maskedValue = "****"
+
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// dsnPasswordPattern encontra a senha em DSNs no formato "chave=valor" (ex: "password=segredo").
+var dsnPasswordPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// maskDatabaseURL mascara a senha de DATABASE_URL, mantendo usuário, host e banco visíveis.
+func maskDatabaseURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			parsed.User = url.UserPassword(parsed.User.Username(), maskedValue)
+		}
+		query := parsed.Query()
+		if query.Has("password") {
+			query.Set("password", maskedValue)
+			parsed.RawQuery = query.Encode()
+		}
+		// url.String escaparia os asteriscos como %2A
+		return strings.ReplaceAll(parsed.String(), url.PathEscape(maskedValue), maskedValue)
+	}
+	if dsnPasswordPattern.MatchString(raw) {
+		return dsnPasswordPattern.ReplaceAllString(raw, "${1}"+maskedValue)
+	}
+	return raw
+}
@@ -0,0 +1,133 @@
+// config/config_test.go
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		want       map[string]string
+		wantErrors []string // Trechos esperados nas mensagens de erro, em ordem
+	}{
+		{name: "arquivo vazio", content: "", want: map[string]string{}},
+		{
+			name: "seções, listas e mapas",
+			content: `env: production
+server:
+  port: 8080
+  read_timeout: 30s
+database:
+  url: postgres://app@db/college
+  pgbouncer: true
+  department_aliases:
+    Computação: Ciência da Computação
+cors:
+  allowed_origins: [https://app.exemplo.edu, https://admin.exemplo.edu]
+rate_limit:
+  groups:
+    teachers: 60/1m
+    students: 300/1m
+enrollment:
+  shifts:
+    M: Manhã
+    N: Noite
+`,
+			want: map[string]string{
+				"APP_ENV":               "production",
+				"PORT":                  "8080",
+				"SERVER_READ_TIMEOUT":   "30s",
+				"DATABASE_URL":          "postgres://app@db/college",
+				"DB_PGBOUNCER":          "true",
+				"DB_DEPARTMENT_ALIASES": "Computação=Ciência da Computação",
+				"CORS_ALLOWED_ORIGINS":  "https://app.exemplo.edu,https://admin.exemplo.edu",
+				"RATE_LIMIT_GROUPS":     "students=300/1m,teachers=60/1m",
+				"ENROLLMENT_SHIFTS":     "M=Manhã,N=Noite",
+			},
+		},
+		{name: "valor nulo", content: "admin_api_token:\n", want: map[string]string{"ADMIN_API_TOKEN": ""}},
+		{
+			name:       "chaves desconhecidas",
+			content:    "servr:\n  port: 8080\nserver:\n  prot: 8080\n  port: 9090\n",
+			wantErrors: []string{"chave desconhecida server.prot", "chave desconhecida servr.port"},
+		},
+		{name: "seção usada como valor", content: "server: 8080\n", wantErrors: []string{"chave desconhecida server"}},
+		{name: "tipo não suportado", content: "env: 2025-01-01\n", wantErrors: []string{"env: tipo de valor não suportado"}},
+		{name: "YAML inválido", content: "server:\n  port: [8080\n", wantErrors: []string{"inválido"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readConfigFile(file)
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("valores = %v, esperava %v", got, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("valores = %v, esperava erro", got)
+			}
+			messages := []string{err.Error()}
+			var invalid *InvalidConfigError
+			if errors.As(err, &invalid) {
+				messages = messages[:0]
+				for _, e := range invalid.Errors {
+					messages = append(messages, e.Error())
+				}
+			}
+			if len(messages) != len(tt.wantErrors) {
+				t.Fatalf("erros = %q, esperava %d", messages, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(messages[i], want) {
+					t.Errorf("erro %d = %q, esperava conter %q", i, messages[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadConfigFileMissing(t *testing.T) {
+	if _, err := readConfigFile(filepath.Join(t.TempDir(), "ausente.yaml")); err == nil {
+		t.Error("arquivo inexistente aceito")
+	}
+}
+
+func TestMaskDatabaseURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "vazia", input: "", want: ""},
+		{name: "URL com senha", input: "postgres://app:segredo@db:5432/college?sslmode=disable", want: "postgres://app:****@db:5432/college?sslmode=disable"},
+		{name: "senha com caracteres escapados", input: "postgres://app:p%40ss%2Fw@db/college", want: "postgres://app:****@db/college"},
+		{name: "URL sem senha", input: "postgres://app@db/college", want: "postgres://app@db/college"},
+		{name: "senha na query", input: "postgres://db/college?password=segredo&user=app", want: "postgres://db/college?password=****&user=app"},
+		{name: "DSN chave=valor", input: "host=db user=app password=segredo dbname=college", want: "host=db user=app password=**** dbname=college"},
+		{name: "DSN com senha entre aspas", input: "password='com espaço' host=db", want: "password=**** host=db"},
+		{name: "DSN com espaços e maiúsculas", input: "host=db PASSWORD = segredo", want: "host=db PASSWORD = ****"},
+		{name: "DSN sem senha", input: "host=db user=app dbname=college", want: "host=db user=app dbname=college"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskDatabaseURL(tt.input); got != tt.want {
+				t.Errorf("maskDatabaseURL(%q) = %q, esperava %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,36 +2,250 @@
 package config
 
 import (
+	"context"
 	"database/sql"
-	"log"
-	"os"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq" // Driver PostgreSQL
+	"github.com/lib/pq" // Driver PostgreSQL
 )
 
 var DB *sql.DB
 
-func InitDB() {
+// SchemaVersion é a versão do esquema criado por createTables, gravada em schema_version e
+// conferida pela verificação de prontidão (/readyz). Incrementar a cada alteração do esquema.
+//
+//	1: esquema inicial com schema_version
+//	2: teachers.email com índice único (teachers_email_key)
+//	3: teacher_subjects criada por createTables
+const SchemaVersion = 3
+
+// Modos de inicialização do banco (DB_STARTUP_MODE).
+const (
+	DBStartupWait       = "wait"       // Aguarda o banco (com backoff) antes de servir; encerra após DB_STARTUP_TIMEOUT
+	DBStartupBackground = "background" // Serve imediatamente e conecta em segundo plano; /readyz indica quando está pronto
+)
+
+// DatabaseConfig controla a conexão com o PostgreSQL.
+type DatabaseConfig struct {
+	URL             string        `yaml:"url" env:"DATABASE_URL"`                         // Obrigatória; segredo: a senha é mascarada em --print-config
+	StartupMode     string        `yaml:"startup_mode" env:"DB_STARTUP_MODE"`             // "wait" (padrão) ou "background"
+	StartupTimeout  time.Duration `yaml:"startup_timeout" env:"DB_STARTUP_TIMEOUT"`       // Espera máxima no modo wait (padrão: 60s; 0 = uma única tentativa)
+	PingTimeout     time.Duration `yaml:"ping_timeout" env:"DB_PING_TIMEOUT"`             // Tempo limite de cada ping, inclusive no /readyz (padrão: 2s)
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`         // Máximo de conexões abertas (padrão: 25; 0 = sem limite)
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`         // Máximo de conexões ociosas no pool (padrão: 5)
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`   // Tempo máximo de vida de uma conexão (padrão: 30m; 0 = sem limite)
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"` // Tempo máximo de uma conexão ociosa no pool (padrão: 5m; 0 = sem limite)
+	PgBouncer       bool          `yaml:"pgbouncer" env:"DB_PGBOUNCER"`                   // Conexão via PgBouncer em modo transaction pooling (sem prepared statements no servidor)
+
+	// Grafia -> nome oficial do departamento, aplicado ao migrar teachers.department para nomes
+	// que a normalização não une sozinha (ex: "Computação=Ciência da Computação")
+	DepartmentAliases map[string]string `yaml:"department_aliases" env:"DB_DEPARTMENT_ALIASES"`
+}
+
+// LoadDatabaseConfig carrega a configuração do banco do ambiente.
+func LoadDatabaseConfig() (DatabaseConfig, error) {
+	cfg := DatabaseConfig{
+		URL:             getenv("DATABASE_URL"),
+		StartupMode:     strings.ToLower(strings.TrimSpace(getenv("DB_STARTUP_MODE"))),
+		StartupTimeout:  60 * time.Second,
+		PingTimeout:     2 * time.Second,
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+	}
+	if cfg.URL == "" {
+		return DatabaseConfig{}, fmt.Errorf("variável de ambiente DATABASE_URL não definida")
+	}
+	switch cfg.StartupMode {
+	case "":
+		cfg.StartupMode = DBStartupWait
+	case DBStartupWait, DBStartupBackground:
+	default:
+		return DatabaseConfig{}, fmt.Errorf("DB_STARTUP_MODE inválido: %q (use 'wait' ou 'background')", cfg.StartupMode)
+	}
+	if raw := getenv("DB_STARTUP_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout < 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_STARTUP_TIMEOUT inválido: %q", raw)
+		}
+		cfg.StartupTimeout = timeout
+	}
+	if raw := getenv("DB_PING_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_PING_TIMEOUT inválido: %q", raw)
+		}
+		cfg.PingTimeout = timeout
+	}
+	if raw := getenv("DB_MAX_OPEN_CONNS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_MAX_OPEN_CONNS inválido: %q", raw)
+		}
+		cfg.MaxOpenConns = n
+	}
+	if raw := getenv("DB_MAX_IDLE_CONNS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_MAX_IDLE_CONNS inválido: %q", raw)
+		}
+		cfg.MaxIdleConns = n
+	}
+	if cfg.MaxOpenConns > 0 && cfg.MaxIdleConns > cfg.MaxOpenConns {
+		return DatabaseConfig{}, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) não pode ser maior que DB_MAX_OPEN_CONNS (%d)", cfg.MaxIdleConns, cfg.MaxOpenConns)
+	}
+	if raw := getenv("DB_CONN_MAX_LIFETIME"); raw != "" {
+		lifetime, err := time.ParseDuration(raw)
+		if err != nil || lifetime < 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_CONN_MAX_LIFETIME inválido: %q", raw)
+		}
+		cfg.ConnMaxLifetime = lifetime
+	}
+	if raw := getenv("DB_CONN_MAX_IDLE_TIME"); raw != "" {
+		idle, err := time.ParseDuration(raw)
+		if err != nil || idle < 0 {
+			return DatabaseConfig{}, fmt.Errorf("DB_CONN_MAX_IDLE_TIME inválido: %q", raw)
+		}
+		cfg.ConnMaxIdleTime = idle
+	}
 	var err error
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("Variável de ambiente DATABASE_URL não definida. Por favor, defina-a.")
+	if cfg.PgBouncer, err = parseToggle("DB_PGBOUNCER", false); err != nil {
+		return DatabaseConfig{}, err
+	}
+	if raw := getenv("DB_DEPARTMENT_ALIASES"); raw != "" {
+		cfg.DepartmentAliases = map[string]string{}
+		for _, entry := range splitList(raw) {
+			alias, name, ok := strings.Cut(entry, "=")
+			alias, name = strings.TrimSpace(alias), strings.TrimSpace(name)
+			if !ok || departmentKey(alias) == "" || departmentKey(name) == "" {
+				return DatabaseConfig{}, fmt.Errorf("DB_DEPARTMENT_ALIASES inválido: %q (use \"Grafia=Nome oficial\")", entry)
+			}
+			cfg.DepartmentAliases[alias] = name
+		}
 	}
+	return cfg, nil
+}
 
-	DB, err = sql.Open("postgres", dbURL)
+// DSN retorna a string de conexão para o lib/pq. Com PgBouncer em modo transaction pooling,
+// cada transação pode ir para uma conexão diferente do servidor, então prepared statements
+// do servidor não sobrevivem entre comandos; binary_parameters=yes faz o driver enviar
+// parse, bind e execute de uma só vez, com o statement sem nome, dentro da mesma transação
+// do PgBouncer.
+func (c DatabaseConfig) DSN() string {
+	if !c.PgBouncer || strings.Contains(c.URL, "binary_parameters") {
+		return c.URL
+	}
+	if strings.HasPrefix(c.URL, "postgres://") || strings.HasPrefix(c.URL, "postgresql://") {
+		if strings.Contains(c.URL, "?") {
+			return c.URL + "&binary_parameters=yes"
+		}
+		return c.URL + "?binary_parameters=yes"
+	}
+	return c.URL + " binary_parameters=yes" // Formato "chave=valor"
+}
+
+// InitDB abre o pool de conexões em DB e prepara o esquema. No modo wait, tenta conectar com
+// backoff exponencial até cfg.StartupTimeout e retorna o erro se não conseguir; no modo
+// background, retorna logo e continua tentando em segundo plano (até lá, /readyz responde 503).
+// Deve ser chamada uma única vez por processo.
+func InitDB(cfg DatabaseConfig) error {
+	if err := OpenDB(cfg); err != nil {
+		return err
+	}
+
+	if cfg.StartupMode == DBStartupBackground {
+		go func() {
+			if err := connectAndMigrate(context.Background(), cfg); err != nil {
+				slog.Error("erro ao preparar o banco de dados", "error", err)
+			}
+		}()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	defer cancel()
+	if err := connectAndMigrate(ctx, cfg); err != nil {
+		return fmt.Errorf("erro ao preparar o banco de dados PostgreSQL: %w", err)
+	}
+	return nil
+}
+
+// OpenDB abre o pool de conexões em DB com os limites de cfg, sem conectar nem alterar o
+// esquema. Usada por InitDB e pelo collegectl, que conecta e migra por conta própria.
+func OpenDB(cfg DatabaseConfig) error {
+	db, err := sql.Open("postgres", cfg.DSN())
 	if err != nil {
-		log.Fatalf("Erro ao abrir o banco de dados PostgreSQL: %v", err)
+		return fmt.Errorf("erro ao abrir o banco de dados PostgreSQL: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	DB = db
+	slog.Info("pool de conexões configurado", "max_open_conns", cfg.MaxOpenConns, "max_idle_conns", cfg.MaxIdleConns,
+		"conn_max_lifetime", cfg.ConnMaxLifetime.String(), "conn_max_idle_time", cfg.ConnMaxIdleTime.String(), "pgbouncer", cfg.PgBouncer)
+	return nil
+}
+
+// WaitForDB aguarda o banco aberto por OpenDB responder, com o mesmo backoff da inicialização,
+// por até cfg.StartupTimeout.
+func WaitForDB(cfg DatabaseConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	defer cancel()
+	if err := waitForDB(ctx, cfg.PingTimeout); err != nil {
+		return fmt.Errorf("falha ao conectar ao banco de dados PostgreSQL: %w", err)
+	}
+	return nil
+}
+
+// Migrate cria ou atualiza as tabelas do esquema até SchemaVersion. Pode ser executada
+// repetidamente: todas as alterações são idempotentes.
+func Migrate(cfg DatabaseConfig) error {
+	return createTables(cfg)
+}
 
-	if err = DB.Ping(); err != nil {
-		log.Fatalf("Erro ao conectar ao banco de dados PostgreSQL: %v", err)
+// connectAndMigrate aguarda o banco responder e cria/atualiza as tabelas.
+func connectAndMigrate(ctx context.Context, cfg DatabaseConfig) error {
+	if err := waitForDB(ctx, cfg.PingTimeout); err != nil {
+		return fmt.Errorf("falha ao conectar: %w", err)
 	}
+	slog.Info("conexão com o banco de dados PostgreSQL estabelecida")
+	return createTables(cfg)
+}
 
-	log.Println("Conexão com o banco de dados PostgreSQL estabelecida com sucesso!")
-	createTables()
+// waitForDB faz ping no banco até ele responder, dobrando o intervalo entre tentativas
+// (de 500ms até 30s). Quando ctx expira, retorna o erro da última tentativa; com ctx já
+// expirado, faz uma única tentativa.
+func waitForDB(ctx context.Context, pingTimeout time.Duration) error {
+	delay := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		// O ping tem prazo próprio, para que o erro retornado seja o do banco e não o de ctx.
+		pingCtx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := DB.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		slog.Warn("banco de dados indisponível, nova tentativa agendada", "attempt", attempt, "retry_in", delay.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 30*time.Second)
+	}
 }
 
-func createTables() {
+// createTables cria ou atualiza as tabelas (idempotente) e registra SchemaVersion.
+func createTables(cfg DatabaseConfig) error {
 	// ATUALIZADO: Adicionada a coluna 'shift' e removido 'UNIQUE' de 'enrollment' temporariamente
 	// para permitir a geração de matrículas mais flexíveis antes de definir a unicidade composta.
 	// A unicidade será garantida pela lógica de geração no serviço.
@@ -69,29 +283,278 @@ func createTables() {
         FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE CASCADE
     );`
 
-	_, err := DB.Exec(createStudentsTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela students: %v", err)
+	createTeacherSubjectsTableSQL := `
+    CREATE TABLE IF NOT EXISTS teacher_subjects (
+        teacher_id TEXT NOT NULL,
+        subject_id TEXT NOT NULL,
+        PRIMARY KEY (teacher_id, subject_id),
+        FOREIGN KEY (teacher_id) REFERENCES teachers(id) ON DELETE CASCADE,
+        FOREIGN KEY (subject_id) REFERENCES subjects(id) ON DELETE CASCADE
+    );
+    CREATE INDEX IF NOT EXISTS idx_teacher_subjects_subject_id ON teacher_subjects(subject_id);`
+
+	// Buckets do rate limiter quando RATE_LIMIT_STORE=postgres (compartilhados entre instâncias).
+	createRateLimitBucketsTableSQL := `
+    CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+        key TEXT PRIMARY KEY,
+        tokens DOUBLE PRECISION NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL,
+        expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );`
+
+	// Respostas guardadas por Idempotency-Key quando IDEMPOTENCY_STORE=postgres.
+	// status_code NULL indica uma requisição ainda em andamento.
+	createIdempotencyKeysTableSQL := `
+    CREATE TABLE IF NOT EXISTS idempotency_keys (
+        key TEXT PRIMARY KEY,
+        fingerprint TEXT NOT NULL,
+        status_code INTEGER,
+        response_headers JSONB,
+        response_body BYTEA,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        expires_at TIMESTAMPTZ NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);`
+
+	// Log de auditoria append-only: o trigger impede UPDATE/DELETE de eventos já gravados.
+	createAuditEventsTableSQL := `
+    CREATE TABLE IF NOT EXISTS audit_events (
+        id BIGSERIAL PRIMARY KEY,
+        occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        actor TEXT NOT NULL,
+        request_id TEXT,
+        entity_type TEXT NOT NULL,
+        entity_id TEXT NOT NULL,
+        action TEXT NOT NULL,
+        before JSONB,
+        after JSONB,
+        changes JSONB
+    );
+    CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id, id DESC);
+    CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+    BEGIN
+        RAISE EXCEPTION 'audit_events é append-only';
+    END;
+    $$ LANGUAGE plpgsql;
+    DROP TRIGGER IF EXISTS audit_events_no_mutation ON audit_events;
+    CREATE TRIGGER audit_events_no_mutation BEFORE UPDATE OR DELETE ON audit_events
+        FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();`
+
+	// Soft delete: registros excluídos recebem deleted_at e são removidos definitivamente
+	// apenas pela limpeza após o período de retenção.
+	addSoftDeleteColumnsSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+    ALTER TABLE subjects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;`
+
+	// Controle de concorrência otimista: cada UPDATE incrementa version (exposta como ETag).
+	addVersionColumnsSQL := `
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+    ALTER TABLE subjects ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;`
+
+	// Matrícula automática por currículo: matérias obrigatórias do ano e situação do aluno em cada
+	// matéria ('enrolled', 'passed' ou 'failed'); aprovadas não são matriculadas novamente.
+	addCurriculumColumnsSQL := `
+    ALTER TABLE subjects ADD COLUMN IF NOT EXISTS mandatory BOOLEAN NOT NULL DEFAULT TRUE;
+    ALTER TABLE student_subjects ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'enrolled'
+        CHECK (status IN ('enrolled', 'passed', 'failed'));
+    CREATE INDEX IF NOT EXISTS idx_subjects_year ON subjects(year) WHERE deleted_at IS NULL;`
+
+	// Cursos e grades curriculares versionadas; alunos pertencem a um curso e a uma versão da grade.
+	createProgramsTablesSQL := `
+    CREATE TABLE IF NOT EXISTS programs (
+        id TEXT PRIMARY KEY,
+        code TEXT NOT NULL UNIQUE,
+        name TEXT NOT NULL,
+        use_code_in_enrollment BOOLEAN NOT NULL DEFAULT FALSE,
+        version INTEGER NOT NULL DEFAULT 1
+    );
+    CREATE TABLE IF NOT EXISTS curricula (
+        id TEXT PRIMARY KEY,
+        program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+        version INTEGER NOT NULL,
+        description TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        UNIQUE (program_id, version)
+    );
+    CREATE TABLE IF NOT EXISTS curriculum_subjects (
+        curriculum_id TEXT NOT NULL REFERENCES curricula(id) ON DELETE CASCADE,
+        subject_id TEXT NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+        year INTEGER NOT NULL CHECK (year > 0),
+        mandatory BOOLEAN NOT NULL DEFAULT TRUE,
+        PRIMARY KEY (curriculum_id, subject_id)
+    );
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS program_id TEXT REFERENCES programs(id);
+    ALTER TABLE students ADD COLUMN IF NOT EXISTS curriculum_id TEXT REFERENCES curricula(id);
+    CREATE INDEX IF NOT EXISTS idx_students_program_id ON students(program_id);`
+
+	// Departamentos como entidades: teachers.department_id substitui o texto livre de
+	// teachers.department, mantido apenas como origem da normalização (normalizeTeacherDepartments).
+	createDepartmentsTableSQL := `
+    CREATE TABLE IF NOT EXISTS departments (
+        id TEXT PRIMARY KEY,
+        code TEXT NOT NULL UNIQUE,
+        name TEXT NOT NULL UNIQUE,
+        head_teacher_id TEXT REFERENCES teachers(id) ON DELETE SET NULL,
+        version INTEGER NOT NULL DEFAULT 1
+    );
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS department_id TEXT REFERENCES departments(id);
+    DO $$
+    BEGIN
+        IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'teachers' AND column_name = 'department') THEN
+            ALTER TABLE teachers ALTER COLUMN department DROP NOT NULL;
+        END IF;
+    END $$;
+    CREATE INDEX IF NOT EXISTS idx_teachers_department_id ON teachers(department_id);`
+
+	// Regime de contratação do professor, que define sua carga horária semanal máxima.
+	addContractTypeColumnSQL := `
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS contract_type TEXT NOT NULL DEFAULT 'integral'
+        CHECK (contract_type IN ('integral', 'parcial', 'horista'));`
+
+	// Requisitos de formatura da grade: total de créditos e grupos de eletivas com créditos mínimos.
+	addDegreeRequirementsSQL := `
+    ALTER TABLE curricula ADD COLUMN IF NOT EXISTS required_credits INTEGER NOT NULL DEFAULT 0 CHECK (required_credits >= 0);
+    CREATE TABLE IF NOT EXISTS curriculum_elective_buckets (
+        curriculum_id TEXT NOT NULL REFERENCES curricula(id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        min_credits INTEGER NOT NULL CHECK (min_credits > 0),
+        PRIMARY KEY (curriculum_id, name)
+    );
+    ALTER TABLE curriculum_subjects ADD COLUMN IF NOT EXISTS bucket TEXT;
+    DO $$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'curriculum_subjects_bucket_fkey') THEN
+            ALTER TABLE curriculum_subjects ADD CONSTRAINT curriculum_subjects_bucket_fkey
+                FOREIGN KEY (curriculum_id, bucket) REFERENCES curriculum_elective_buckets(curriculum_id, name);
+        END IF;
+    END $$;`
+
+	// Email dos professores; o índice único é criado por createTeacherEmailIndex.
+	addTeacherEmailSQL := `
+    ALTER TABLE teachers ADD COLUMN IF NOT EXISTS email TEXT;`
+
+	// Versão do esquema aplicada por último (linha única), conferida pelo /readyz.
+	schemaVersionSQL := `
+    CREATE TABLE IF NOT EXISTS schema_version (
+        id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+        version INT NOT NULL,
+        applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );`
+
+	if _, err := DB.Exec(createStudentsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela students: %w", err)
 	}
-	_, err = DB.Exec(createSubjectsTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela subjects: %v", err)
+	if _, err := DB.Exec(createSubjectsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela subjects: %w", err)
 	}
-	_, err = DB.Exec(createTeachersTableSQL)
-	if err != nil {
-		log.Fatalf("Erro ao criar tabela teachers: %v", err)
+	if _, err := DB.Exec(createTeachersTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela teachers: %w", err)
+	}
+	if _, err := DB.Exec(createStudentSubjectsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela student_subjects: %w", err)
+	}
+	if _, err := DB.Exec(createTeacherSubjectsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela teacher_subjects: %w", err)
+	}
+	if _, err := DB.Exec(addSoftDeleteColumnsSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar colunas de soft delete: %w", err)
+	}
+	if _, err := DB.Exec(addVersionColumnsSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar colunas de versão: %w", err)
+	}
+	if _, err := DB.Exec(addCurriculumColumnsSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar colunas de currículo: %w", err)
 	}
-	_, err = DB.Exec(createStudentSubjectsTableSQL)
+	if _, err := DB.Exec(createProgramsTablesSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabelas de cursos e grades curriculares: %w", err)
+	}
+	if _, err := DB.Exec(addDegreeRequirementsSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar requisitos de formatura às grades curriculares: %w", err)
+	}
+	if _, err := DB.Exec(createDepartmentsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela departments: %w", err)
+	}
+	if err := normalizeTeacherDepartments(DB, cfg.DepartmentAliases); err != nil {
+		return fmt.Errorf("erro ao normalizar departamentos dos professores: %w", err)
+	}
+	if _, err := DB.Exec(addContractTypeColumnSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar coluna de regime de contratação: %w", err)
+	}
+	if _, err := DB.Exec(addTeacherEmailSQL); err != nil {
+		return fmt.Errorf("erro ao adicionar coluna de email dos professores: %w", err)
+	}
+	if err := createTeacherEmailIndex(DB); err != nil {
+		return fmt.Errorf("erro ao criar índice único de email dos professores: %w", err)
+	}
+	if _, err := DB.Exec(createRateLimitBucketsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela rate_limit_buckets: %w", err)
+	}
+	if _, err := DB.Exec(createIdempotencyKeysTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela idempotency_keys: %w", err)
+	}
+	if _, err := DB.Exec(createAuditEventsTableSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela audit_events: %w", err)
+	}
+
+	if _, err := DB.Exec(schemaVersionSQL); err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_version: %w", err)
+	}
+	// GREATEST evita que uma instância com código antigo rebaixe a versão gravada por uma mais nova.
+	_, err := DB.Exec(`INSERT INTO schema_version (id, version) VALUES (TRUE, $1)
+    ON CONFLICT (id) DO UPDATE SET version = GREATEST(schema_version.version, EXCLUDED.version), applied_at = NOW()`, SchemaVersion)
 	if err != nil {
-		log.Fatalf("Erro ao criar tabela student_subjects: %v", err)
+		return fmt.Errorf("erro ao registrar versão do esquema: %w", err)
 	}
 
-	log.Println("Tabelas verificadas/criadas com sucesso!")
+	slog.Info("tabelas verificadas/criadas", "schema_version", SchemaVersion)
+	return nil
 }
 
 func CloseDB() {
 	if DB != nil {
 		DB.Close()
-		log.Println("Conexão com o banco de dados PostgreSQL fechada.")
+		slog.Info("conexão com o banco de dados PostgreSQL fechada")
+	}
+}
+
+// createTeacherEmailIndex cria o índice único teachers_email_key, usado para recusar emails
+// repetidos (como na verificação da importação). Em bancos com professores cadastrados antes
+// do índice, emails já repetidos impediriam a criação e a inicialização: nesse caso, cada email
+// repetido é reportado no log com os IDs dos professores e o índice fica para uma próxima
+// inicialização (ou 'collegectl migrate'), depois de corrigidos os cadastros.
+func createTeacherEmailIndex(db *sql.DB) error {
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass('teachers_email_key') IS NOT NULL`).Scan(&exists); err != nil || exists {
+		return err
 	}
+
+	rows, err := db.Query(`SELECT email, array_agg(id ORDER BY id) FROM teachers
+	WHERE email IS NOT NULL GROUP BY email HAVING COUNT(*) > 1 ORDER BY email`)
+	if err != nil {
+		return fmt.Errorf("falha ao procurar emails repetidos: %w", err)
+	}
+	duplicates := 0
+	for rows.Next() {
+		var email string
+		var teacherIDs []string
+		if err := rows.Scan(&email, pq.Array(&teacherIDs)); err != nil {
+			rows.Close()
+			return err
+		}
+		duplicates++
+		slog.Warn("email repetido entre professores", "email", email, "teacher_ids", teacherIDs)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		slog.Warn("índice único de email dos professores não criado: corrija os emails repetidos e reinicie", "duplicates", duplicates)
+		return nil
+	}
+
+	_, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS teachers_email_key ON teachers(email)`)
+	return err
 }
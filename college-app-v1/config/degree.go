@@ -0,0 +1,26 @@
+// config/degree.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DegreeConfig define os requisitos gerais de formatura, usados na auditoria de alunos sem grade
+// curricular (os demais seguem os requisitos da própria grade).
+type DegreeConfig struct {
+	RequiredCredits int `yaml:"required_credits" env:"DEGREE_REQUIRED_CREDITS"` // Créditos aprovados exigidos (padrão: 0, sem mínimo)
+}
+
+// LoadDegreeConfig carrega os requisitos gerais de formatura do ambiente.
+func LoadDegreeConfig() (DegreeConfig, error) {
+	cfg := DegreeConfig{}
+	if raw := getenv("DEGREE_REQUIRED_CREDITS"); raw != "" {
+		credits, err := strconv.Atoi(raw)
+		if err != nil || credits < 0 {
+			return DegreeConfig{}, fmt.Errorf("DEGREE_REQUIRED_CREDITS inválido: %q", raw)
+		}
+		cfg.RequiredCredits = credits
+	}
+	return cfg, nil
+}
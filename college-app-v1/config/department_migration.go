@@ -0,0 +1,198 @@
+// config/department_migration.go
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// accentReplacer remove os acentos usados em português, para comparar nomes de departamento
+// digitados de formas diferentes ("Computação", "computacao").
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// departmentStopWords não entram no código gerado a partir do nome (ex: Ciência da Computação -> CC).
+var departmentStopWords = map[string]bool{"DA": true, "DAS": true, "DE": true, "DO": true, "DOS": true, "E": true}
+
+// departmentKey normaliza o nome de um departamento: minúsculas, sem acentos e com espaços únicos.
+func departmentKey(name string) string {
+	return accentReplacer.Replace(strings.Join(strings.Fields(strings.ToLower(name)), " "))
+}
+
+// departmentCode gera um código a partir do nome: as iniciais das palavras ou, para uma palavra
+// só, suas três primeiras letras.
+func departmentCode(name string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToUpper(departmentKey(name))) {
+		word = strings.Map(func(r rune) rune {
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" && !departmentStopWords[word] {
+			words = append(words, word)
+		}
+	}
+	code := ""
+	if len(words) == 1 {
+		code = words[0]
+		if len(code) > 3 {
+			code = code[:3]
+		}
+	} else {
+		for _, word := range words {
+			code += word[:1]
+		}
+	}
+	if len(code) > 8 {
+		code = code[:8]
+	}
+	if len(code) < 2 {
+		code = "DEP"
+	}
+	return code
+}
+
+// departmentGroup retorna a chave normalizada do departamento de spelling e, se spelling for
+// uma grafia de aliases (DB_DEPARTMENT_ALIASES, indexado por departmentKey), o nome oficial.
+func departmentGroup(spelling string, aliases map[string]string) (key, name string) {
+	key = departmentKey(spelling)
+	if name, ok := aliases[key]; ok {
+		return departmentKey(name), name
+	}
+	return key, ""
+}
+
+// normalizeTeacherDepartments migra o texto livre de teachers.department para a tabela departments:
+// grafias equivalentes (maiúsculas, acentos, espaços) ou mapeadas em aliases (grafia -> nome
+// oficial) viram um único departamento, nomeado pelo alias ou pela grafia mais usada, e os
+// professores recebem department_id. Só processa professores ainda sem department_id, então
+// pode ser executada a cada inicialização.
+func normalizeTeacherDepartments(db *sql.DB, aliases map[string]string) error {
+	var hasLegacyColumn bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'teachers' AND column_name = 'department')`).Scan(&hasLegacyColumn)
+	if err != nil || !hasLegacyColumn {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT department, COUNT(*) FROM teachers
+	WHERE department_id IS NULL AND department IS NOT NULL AND TRIM(department) <> ''
+	GROUP BY department`)
+	if err != nil {
+		return fmt.Errorf("falha ao ler departamentos em texto livre: %w", err)
+	}
+	aliasNames := map[string]string{} // chave normalizada da grafia -> nome oficial
+	for alias, name := range aliases {
+		aliasNames[departmentKey(alias)] = name
+	}
+	counts := map[string]int{}      // grafia -> professores
+	groups := map[string][]string{} // chave normalizada -> grafias
+	names := map[string]string{}    // chave normalizada -> nome oficial definido por alias
+	for rows.Next() {
+		var spelling string
+		var count int
+		if err := rows.Scan(&spelling, &count); err != nil {
+			rows.Close()
+			return err
+		}
+		counts[spelling] = count
+		key, name := departmentGroup(spelling, aliasNames)
+		groups[key] = append(groups[key], spelling)
+		if name != "" {
+			names[key] = name
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	existing := map[string]string{} // chave normalizada -> ID
+	codes := map[string]bool{}
+	deptRows, err := tx.Query(`SELECT id, code, name FROM departments`)
+	if err != nil {
+		return fmt.Errorf("falha ao ler departamentos: %w", err)
+	}
+	for deptRows.Next() {
+		var id, code, name string
+		if err := deptRows.Scan(&id, &code, &name); err != nil {
+			deptRows.Close()
+			return err
+		}
+		existing[departmentKey(name)] = id
+		codes[code] = true
+	}
+	deptRows.Close()
+	if err := deptRows.Err(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	created, teachers := 0, int64(0)
+	for _, key := range keys {
+		spellings := groups[key]
+		id, ok := existing[key]
+		if !ok {
+			// Sem alias, a grafia mais usada (em empate, a primeira em ordem alfabética) vira o nome oficial.
+			sort.Slice(spellings, func(i, j int) bool {
+				if counts[spellings[i]] != counts[spellings[j]] {
+					return counts[spellings[i]] > counts[spellings[j]]
+				}
+				return spellings[i] < spellings[j]
+			})
+			name := names[key]
+			if name == "" {
+				name = strings.Join(strings.Fields(spellings[0]), " ")
+			}
+			base := departmentCode(name)
+			code := base
+			for n := 2; codes[code]; n++ {
+				code = fmt.Sprintf("%s%d", base, n)
+			}
+			codes[code] = true
+			id = uuid.New().String()
+			if _, err := tx.Exec(`INSERT INTO departments (id, code, name) VALUES ($1, $2, $3)`, id, code, name); err != nil {
+				return fmt.Errorf("falha ao criar departamento %s: %w", name, err)
+			}
+			created++
+		}
+		res, err := tx.Exec(`UPDATE teachers SET department_id = $1 WHERE department_id IS NULL AND department = ANY($2)`, id, pq.Array(spellings))
+		if err != nil {
+			return fmt.Errorf("falha ao vincular professores ao departamento: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		teachers += affected
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("departamentos dos professores normalizados", "spellings", len(counts), "departments", len(groups), "created", created, "teachers", teachers)
+	return nil
+}
@@ -0,0 +1,79 @@
+// config/department_migration_test.go
+package config
+
+import "testing"
+
+func TestDepartmentKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "acentos e maiúsculas", input: "Ciência da Computação", want: "ciencia da computacao"},
+		{name: "sem acentos digitados", input: "ciencia da computacao", want: "ciencia da computacao"},
+		{name: "espaços repetidos e nas pontas", input: "  Engenharia   Elétrica ", want: "engenharia eletrica"},
+		{name: "tudo em maiúsculas", input: "MATEMÁTICA", want: "matematica"},
+		{name: "cedilha e til", input: "Educação Física", want: "educacao fisica"},
+		{name: "vazio", input: "   ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := departmentKey(tt.input); got != tt.want {
+				t.Errorf("departmentKey(%q) = %q, esperava %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDepartmentCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "iniciais sem preposições", input: "Ciência da Computação", want: "CC"},
+		{name: "conjunção ignorada", input: "Engenharia Elétrica e de Computação", want: "EEC"},
+		{name: "uma palavra usa três letras", input: "Matemática", want: "MAT"},
+		{name: "palavra curta", input: "TI", want: "TI"},
+		{name: "pontuação descartada", input: "Letras - Português e Inglês", want: "LPI"},
+		{name: "dígitos mantidos", input: "Departamento 2", want: "D2"},
+		{name: "limitado a oito caracteres", input: "Alfa Beta Gama Delta Épsilon Zeta Eta Teta Iota", want: "ABGDEZET"},
+		{name: "curto demais usa o padrão", input: "X", want: "DEP"},
+		{name: "só preposições usa o padrão", input: "de da do", want: "DEP"},
+		{name: "vazio usa o padrão", input: "", want: "DEP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := departmentCode(tt.input); got != tt.want {
+				t.Errorf("departmentCode(%q) = %q, esperava %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDepartmentGroup(t *testing.T) {
+	aliases := map[string]string{
+		departmentKey("Computação"):    "Ciência da Computação",
+		departmentKey("Eng. Elétrica"): "Engenharia Elétrica",
+	}
+	tests := []struct {
+		name     string
+		spelling string
+		wantKey  string
+		wantName string
+	}{
+		{name: "alias une nomes diferentes", spelling: "Computação", wantKey: "ciencia da computacao", wantName: "Ciência da Computação"},
+		{name: "alias com outra grafia", spelling: "  COMPUTACAO ", wantKey: "ciencia da computacao", wantName: "Ciência da Computação"},
+		{name: "nome oficial sem alias", spelling: "Ciência da Computação", wantKey: "ciencia da computacao"},
+		{name: "abreviação", spelling: "eng. eletrica", wantKey: "engenharia eletrica", wantName: "Engenharia Elétrica"},
+		{name: "sem alias", spelling: "Matemática", wantKey: "matematica"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, name := departmentGroup(tt.spelling, aliases)
+			if key != tt.wantKey || name != tt.wantName {
+				t.Errorf("departmentGroup(%q) = %q, %q, esperava %q, %q", tt.spelling, key, name, tt.wantKey, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// config/enrollment.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnrollmentConfig controla o formato das matrículas (ano + turno + sequência, ex: 2024M0001).
+type EnrollmentConfig struct {
+	SequenceDigits int               `yaml:"sequence_digits" env:"ENROLLMENT_SEQUENCE_DIGITS"` // Dígitos da sequência anual (padrão: 4)
+	Shifts         map[string]string `yaml:"shifts" env:"ENROLLMENT_SHIFTS"`                   // Código -> nome do turno (padrão: "M=Manhã,T=Tarde,N=Noite")
+}
+
+// LoadEnrollmentConfig carrega o formato de matrícula do ambiente.
+func LoadEnrollmentConfig() (EnrollmentConfig, error) {
+	cfg := EnrollmentConfig{
+		SequenceDigits: 4,
+		Shifts:         map[string]string{"M": "Manhã", "T": "Tarde", "N": "Noite"},
+	}
+	if raw := getenv("ENROLLMENT_SEQUENCE_DIGITS"); raw != "" {
+		digits, err := strconv.Atoi(raw)
+		if err != nil || digits < 1 || digits > 9 {
+			return EnrollmentConfig{}, fmt.Errorf("ENROLLMENT_SEQUENCE_DIGITS inválido: %q (use um número de 1 a 9)", raw)
+		}
+		cfg.SequenceDigits = digits
+	}
+	if raw := getenv("ENROLLMENT_SHIFTS"); raw != "" {
+		shifts := map[string]string{}
+		for _, entry := range splitList(raw) {
+			code, name, ok := strings.Cut(entry, "=")
+			code = strings.TrimSpace(code)
+			if !ok || len(code) != 1 || code[0] < 'A' || code[0] > 'Z' {
+				return EnrollmentConfig{}, fmt.Errorf("ENROLLMENT_SHIFTS inválido: %q (use \"CÓDIGO=Nome\", com código de uma letra maiúscula)", entry)
+			}
+			shifts[code] = strings.TrimSpace(name)
+		}
+		cfg.Shifts = shifts
+	}
+	return cfg, nil
+}
+
+// FeaturesConfig liga ou desliga funcionalidades opcionais da API.
+type FeaturesConfig struct {
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS"` // Expõe /metrics, restrito a administradores (padrão: true)
+	Imports bool `yaml:"imports" env:"FEATURE_IMPORTS"` // Habilita as rotas /imports (padrão: true)
+}
+
+// LoadFeaturesConfig carrega os toggles de funcionalidades do ambiente.
+func LoadFeaturesConfig() (FeaturesConfig, error) {
+	cfg := FeaturesConfig{Metrics: true, Imports: true}
+	var err error
+	if cfg.Metrics, err = parseToggle("FEATURE_METRICS", cfg.Metrics); err != nil {
+		return FeaturesConfig{}, err
+	}
+	if cfg.Imports, err = parseToggle("FEATURE_IMPORTS", cfg.Imports); err != nil {
+		return FeaturesConfig{}, err
+	}
+	return cfg, nil
+}
+
+// parseToggle interpreta uma variável booleana, retornando def se estiver vazia.
+func parseToggle(name string, def bool) (bool, error) {
+	raw := getenv(name)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s inválido: %q (use 'true' ou 'false')", name, raw)
+	}
+	return value, nil
+}
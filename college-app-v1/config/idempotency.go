@@ -0,0 +1,38 @@
+// config/idempotency.go
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IdempotencyConfig controla o suporte ao header Idempotency-Key nas rotas POST.
+type IdempotencyConfig struct {
+	Enabled bool          `yaml:"enabled" env:"IDEMPOTENCY_ENABLED"` // Padrão: true
+	Store   string        `yaml:"store" env:"IDEMPOTENCY_STORE"`     // "memory" (padrão) ou "postgres"
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`         // Por quanto tempo uma resposta fica guardada (padrão: 24h)
+}
+
+// LoadIdempotencyConfig carrega a configuração de idempotência do ambiente.
+func LoadIdempotencyConfig() (IdempotencyConfig, error) {
+	cfg := IdempotencyConfig{
+		Enabled: getenv("IDEMPOTENCY_ENABLED") != "false",
+		Store:   strings.ToLower(getenv("IDEMPOTENCY_STORE")),
+		TTL:     24 * time.Hour,
+	}
+	if cfg.Store == "" {
+		cfg.Store = "memory"
+	}
+	if cfg.Store != "memory" && cfg.Store != "postgres" {
+		return IdempotencyConfig{}, fmt.Errorf("IDEMPOTENCY_STORE inválido: %q (use 'memory' ou 'postgres')", cfg.Store)
+	}
+	if raw := getenv("IDEMPOTENCY_TTL"); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			return IdempotencyConfig{}, fmt.Errorf("IDEMPOTENCY_TTL inválido: %q", raw)
+		}
+		cfg.TTL = ttl
+	}
+	return cfg, nil
+}
@@ -0,0 +1,43 @@
+// config/logging.go
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LoggingConfig controla o formato e o nível dos logs.
+type LoggingConfig struct {
+	Level  slog.Level `yaml:"level" env:"LOG_LEVEL"`   // debug, info (padrão), warn ou error
+	Format string     `yaml:"format" env:"LOG_FORMAT"` // "json" (padrão em produção) ou "text" (padrão nos demais ambientes)
+	Redact bool       `yaml:"redact" env:"LOG_REDACT"` // Mascara nomes e emails (padrão: true)
+}
+
+// LoadLoggingConfig carrega a configuração de logs do ambiente.
+func LoadLoggingConfig() (LoggingConfig, error) {
+	cfg := LoggingConfig{Level: slog.LevelInfo, Format: "text", Redact: true}
+	if AppEnv() == EnvProduction {
+		cfg.Format = "json"
+	}
+	if raw := getenv("LOG_LEVEL"); raw != "" {
+		if err := cfg.Level.UnmarshalText([]byte(raw)); err != nil {
+			return LoggingConfig{}, fmt.Errorf("LOG_LEVEL inválido: %q (use debug, info, warn ou error)", raw)
+		}
+	}
+	switch format := strings.ToLower(getenv("LOG_FORMAT")); format {
+	case "":
+	case "json", "text":
+		cfg.Format = format
+	default:
+		return LoggingConfig{}, fmt.Errorf("LOG_FORMAT inválido: %q (use 'json' ou 'text')", format)
+	}
+	switch raw := strings.ToLower(getenv("LOG_REDACT")); raw {
+	case "", "true":
+	case "false":
+		cfg.Redact = false
+	default:
+		return LoggingConfig{}, fmt.Errorf("LOG_REDACT inválido: %q (use 'true' ou 'false')", raw)
+	}
+	return cfg, nil
+}
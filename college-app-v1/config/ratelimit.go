@@ -0,0 +1,48 @@
+// config/ratelimit.go
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RateLimitConfig reúne as configurações de limitação de requisições lidas do ambiente.
+// Os limites são mantidos como texto ("100/1m") e interpretados pelo pacote middleware.
+type RateLimitConfig struct {
+	Enabled    bool              `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`         // Padrão: true
+	Store      string            `yaml:"store" env:"RATE_LIMIT_STORE"`             // "memory" (padrão) ou "postgres"
+	Default    string            `yaml:"default" env:"RATE_LIMIT_DEFAULT"`         // Limite aplicado a rotas sem grupo próprio (padrão: "100/1m")
+	Groups     map[string]string `yaml:"groups" env:"RATE_LIMIT_GROUPS"`           // Ex: "students=300/1m,teachers=60/1m"
+	TrustProxy bool              `yaml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY"` // Usar X-Forwarded-For para identificar o IP do cliente
+}
+
+// LoadRateLimitConfig carrega a configuração de rate limiting a partir das variáveis de ambiente.
+func LoadRateLimitConfig() (RateLimitConfig, error) {
+	cfg := RateLimitConfig{
+		Enabled:    getenv("RATE_LIMIT_ENABLED") != "false",
+		Store:      strings.ToLower(getenv("RATE_LIMIT_STORE")),
+		Default:    getenv("RATE_LIMIT_DEFAULT"),
+		Groups:     map[string]string{},
+		TrustProxy: getenv("RATE_LIMIT_TRUST_PROXY") == "true",
+	}
+	if cfg.Store == "" {
+		cfg.Store = "memory"
+	}
+	if cfg.Store != "memory" && cfg.Store != "postgres" {
+		return RateLimitConfig{}, fmt.Errorf("RATE_LIMIT_STORE inválido: %q (use 'memory' ou 'postgres')", cfg.Store)
+	}
+	if cfg.Default == "" {
+		cfg.Default = "100/1m"
+	}
+
+	// Formato: grupo=limite separados por vírgula. Entradas malformadas são ignoradas aqui
+	// e o limite em si é validado ao construir o limitador.
+	for _, entry := range strings.Split(getenv("RATE_LIMIT_GROUPS"), ",") {
+		name, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" {
+			continue
+		}
+		cfg.Groups[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(limit)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,42 @@
+// config/retention.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RetentionConfig controla a limpeza de registros excluídos (soft delete).
+type RetentionConfig struct {
+	SoftDeleteRetentionDays int           `yaml:"soft_delete_retention_days" env:"SOFT_DELETE_RETENTION_DAYS"` // Padrão: 90 dias
+	PurgeInterval           time.Duration `yaml:"purge_interval" env:"PURGE_INTERVAL"`                         // Intervalo da limpeza automática no servidor local; 0 desabilita
+}
+
+// SoftDeleteRetention retorna o período de retenção como duração.
+func (c RetentionConfig) SoftDeleteRetention() time.Duration {
+	return time.Duration(c.SoftDeleteRetentionDays) * 24 * time.Hour
+}
+
+// LoadRetentionConfig carrega a configuração de retenção do ambiente.
+func LoadRetentionConfig() (RetentionConfig, error) {
+	cfg := RetentionConfig{
+		SoftDeleteRetentionDays: 90,
+		PurgeInterval:           24 * time.Hour,
+	}
+	if raw := getenv("SOFT_DELETE_RETENTION_DAYS"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			return RetentionConfig{}, fmt.Errorf("SOFT_DELETE_RETENTION_DAYS inválido: %q", raw)
+		}
+		cfg.SoftDeleteRetentionDays = days
+	}
+	if raw := getenv("PURGE_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return RetentionConfig{}, fmt.Errorf("PURGE_INTERVAL inválido: %q", raw)
+		}
+		cfg.PurgeInterval = interval
+	}
+	return cfg, nil
+}
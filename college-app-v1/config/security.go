@@ -0,0 +1,132 @@
+// config/security.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ambientes suportados em APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// AppEnv retorna o ambiente atual (APP_ENV), com "development" como padrão.
+// Na Vercel, VERCEL_ENV=production também é reconhecido.
+func AppEnv() string {
+	env := strings.ToLower(strings.TrimSpace(getenv("APP_ENV")))
+	if env == "" && getenv("VERCEL_ENV") == "production" {
+		env = EnvProduction
+	}
+	if env == "" {
+		env = EnvDevelopment
+	}
+	return env
+}
+
+// CORSConfig define as origens, métodos e headers aceitos pelo CORS.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // Separadas por vírgula no ambiente
+	AllowedMethods   []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           int      `yaml:"max_age" env:"CORS_MAX_AGE"` // Segundos de cache do preflight
+}
+
+// Padrões por ambiente. Em produção nenhuma origem é liberada por padrão:
+// o frontend na Vercel acessa a API pela mesma origem (/api).
+var defaultCORSOrigins = map[string][]string{
+	EnvDevelopment: {"http://localhost:5173", "http://127.0.0.1:5173"}, // Servidor de desenvolvimento do Vite
+	EnvProduction:  {},
+}
+
+// LoadCORSConfig carrega a configuração de CORS do ambiente, aplicando os padrões do APP_ENV atual.
+func LoadCORSConfig() (CORSConfig, error) {
+	env := AppEnv()
+	cfg := CORSConfig{
+		AllowedOrigins:   splitList(getenv("CORS_ALLOWED_ORIGINS")),
+		AllowedMethods:   splitList(getenv("CORS_ALLOWED_METHODS")),
+		AllowedHeaders:   splitList(getenv("CORS_ALLOWED_HEADERS")),
+		ExposedHeaders:   splitList(getenv("CORS_EXPOSED_HEADERS")),
+		AllowCredentials: getenv("CORS_ALLOW_CREDENTIALS") == "true",
+		MaxAge:           600,
+	}
+	if cfg.AllowedOrigins == nil {
+		cfg.AllowedOrigins = defaultCORSOrigins[env]
+	}
+	if cfg.AllowedMethods == nil {
+		cfg.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if cfg.AllowedHeaders == nil {
+		cfg.AllowedHeaders = []string{"Content-Type", "Authorization", "X-User-ID", "X-Request-ID", "If-Match", "If-None-Match", "Idempotency-Key", "traceparent", "tracestate"}
+	}
+	if cfg.ExposedHeaders == nil {
+		cfg.ExposedHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID", "ETag", "Idempotent-Replayed", "Content-Disposition"}
+	}
+	if raw := getenv("CORS_MAX_AGE"); raw != "" {
+		maxAge, err := strconv.Atoi(raw)
+		if err != nil || maxAge < 0 {
+			return CORSConfig{}, fmt.Errorf("CORS_MAX_AGE inválido: %q", raw)
+		}
+		cfg.MaxAge = maxAge
+	}
+
+	// A especificação de CORS proíbe "*" com credenciais; o navegador rejeitaria as respostas.
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" && cfg.AllowCredentials {
+			return CORSConfig{}, fmt.Errorf("CORS_ALLOWED_ORIGINS='*' não pode ser usado com CORS_ALLOW_CREDENTIALS=true; liste as origens explicitamente")
+		}
+	}
+	return cfg, nil
+}
+
+// SecurityHeadersConfig controla os headers de segurança adicionados a todas as respostas.
+type SecurityHeadersConfig struct {
+	HSTSMaxAge            int    `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"` // Em segundos; 0 desabilita (padrão: 1 ano em produção)
+	ContentSecurityPolicy string `yaml:"csp" env:"SECURITY_CSP"`                   // Aplicada apenas a respostas HTML
+}
+
+// LoadSecurityHeadersConfig carrega a configuração dos headers de segurança.
+func LoadSecurityHeadersConfig() (SecurityHeadersConfig, error) {
+	cfg := SecurityHeadersConfig{
+		ContentSecurityPolicy: getenv("SECURITY_CSP"),
+	}
+	if AppEnv() == EnvProduction {
+		cfg.HSTSMaxAge = 31536000
+	}
+	if raw := getenv("SECURITY_HSTS_MAX_AGE"); raw != "" {
+		maxAge, err := strconv.Atoi(raw)
+		if err != nil || maxAge < 0 {
+			return SecurityHeadersConfig{}, fmt.Errorf("SECURITY_HSTS_MAX_AGE inválido: %q", raw)
+		}
+		cfg.HSTSMaxAge = maxAge
+	}
+	if cfg.ContentSecurityPolicy == "" {
+		cfg.ContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+	}
+	return cfg, nil
+}
+
+// AdminAPIToken retorna o token (ADMIN_API_TOKEN) exigido como "Authorization: Bearer <token>"
+// nas rotas administrativas. Vazio desabilita o acesso administrativo.
+func AdminAPIToken() string {
+	return getenv("ADMIN_API_TOKEN")
+}
+
+// splitList separa uma lista por vírgulas, descartando itens vazios.
+// Retorna nil se a variável estiver vazia, para que os padrões sejam aplicados.
+func splitList(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+// config/server.go
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ServerConfig controla o servidor HTTP local (fora da Vercel) e seu desligamento.
+type ServerConfig struct {
+	Port              string        `yaml:"port" env:"PORT"`                                      // Padrão: 8080
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"` // Padrão: 5s
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`               // Leitura da requisição inteira, inclusive uploads CSV (padrão: 30s)
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`             // Escrita da resposta, inclusive exportações (padrão: 60s)
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`               // Conexões keep-alive ociosas (padrão: 120s)
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY"`           // Tempo com /readyz em 503 antes de parar de aceitar conexões (padrão: 5s em produção, 0 nos demais)
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`       // Espera máxima pelas requisições em andamento (padrão: 30s)
+}
+
+// LoadServerConfig carrega a configuração do servidor HTTP do ambiente.
+func LoadServerConfig() (ServerConfig, error) {
+	cfg := ServerConfig{
+		Port:              getenv("PORT"),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	if AppEnv() == EnvProduction {
+		cfg.ShutdownDelay = 5 * time.Second
+	}
+
+	durations := []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", &cfg.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", &cfg.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", &cfg.IdleTimeout},
+		{"SERVER_SHUTDOWN_DELAY", &cfg.ShutdownDelay},
+		{"SERVER_SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout},
+	}
+	for _, d := range durations {
+		raw := getenv(d.env)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value < 0 {
+			return ServerConfig{}, fmt.Errorf("%s inválido: %q (use uma duração, ex: 30s)", d.env, raw)
+		}
+		*d.target = value
+	}
+	return cfg, nil
+}
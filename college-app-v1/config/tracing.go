@@ -0,0 +1,45 @@
+// config/tracing.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TracingConfig controla a exportação dos spans do OpenTelemetry.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`         // "none" (padrão), "otlp" ou "stdout"
+	File        string  `yaml:"file" env:"TRACING_FILE"`                 // Arquivo de saída do exporter stdout (padrão: saída padrão)
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`    // Nome do serviço nos spans (padrão: "college-app")
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"` // Fração de traces amostrados, de 0 a 1 (padrão: 1)
+}
+
+// LoadTracingConfig carrega a configuração de tracing do ambiente. O destino do exporter OTLP
+// segue as variáveis padrão do OpenTelemetry (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS...).
+func LoadTracingConfig() (TracingConfig, error) {
+	cfg := TracingConfig{
+		Exporter:    strings.ToLower(strings.TrimSpace(getenv("TRACING_EXPORTER"))),
+		File:        getenv("TRACING_FILE"),
+		ServiceName: getenv("OTEL_SERVICE_NAME"),
+		SampleRatio: 1,
+	}
+	switch cfg.Exporter {
+	case "":
+		cfg.Exporter = "none"
+	case "none", "otlp", "stdout":
+	default:
+		return TracingConfig{}, fmt.Errorf("TRACING_EXPORTER inválido: %q (use 'none', 'otlp' ou 'stdout')", cfg.Exporter)
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "college-app"
+	}
+	if raw := getenv("TRACING_SAMPLE_RATIO"); raw != "" {
+		ratio, err := strconv.ParseFloat(raw, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return TracingConfig{}, fmt.Errorf("TRACING_SAMPLE_RATIO inválido: %q (use um número entre 0 e 1)", raw)
+		}
+		cfg.SampleRatio = ratio
+	}
+	return cfg, nil
+}
@@ -0,0 +1,54 @@
+// config/workload.go
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WorkloadConfig controla o cálculo e o limite da carga horária semanal dos professores.
+type WorkloadConfig struct {
+	HoursPerCredit int            `yaml:"hours_per_credit" env:"WORKLOAD_HOURS_PER_CREDIT"` // Horas semanais por crédito (padrão: 1)
+	MaxWeeklyHours map[string]int `yaml:"limits" env:"WORKLOAD_LIMITS"`                     // Limite por regime, ex: "integral=20,parcial=12,horista=8"
+	Policy         string         `yaml:"policy" env:"WORKLOAD_POLICY"`                     // "warn" (padrão) apenas avisa; "reject" recusa a associação
+}
+
+// LoadWorkloadConfig carrega a configuração de carga horária do ambiente. WORKLOAD_LIMITS
+// substitui apenas os regimes informados; um limite 0 desativa o limite do regime.
+func LoadWorkloadConfig() (WorkloadConfig, error) {
+	cfg := WorkloadConfig{
+		HoursPerCredit: 1,
+		MaxWeeklyHours: map[string]int{"integral": 20, "parcial": 12, "horista": 8},
+		Policy:         "warn",
+	}
+	if raw := getenv("WORKLOAD_HOURS_PER_CREDIT"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 1 {
+			return WorkloadConfig{}, fmt.Errorf("WORKLOAD_HOURS_PER_CREDIT inválido: %q", raw)
+		}
+		cfg.HoursPerCredit = hours
+	}
+	if raw := getenv("WORKLOAD_LIMITS"); raw != "" {
+		for _, entry := range strings.Split(raw, ",") {
+			contractType, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			contractType = strings.ToLower(strings.TrimSpace(contractType))
+			if _, known := cfg.MaxWeeklyHours[contractType]; !ok || !known {
+				return WorkloadConfig{}, fmt.Errorf("WORKLOAD_LIMITS inválido: %q (use integral=20,parcial=12,horista=8)", entry)
+			}
+			hours, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || hours < 0 {
+				return WorkloadConfig{}, fmt.Errorf("WORKLOAD_LIMITS inválido para %s: %q", contractType, value)
+			}
+			cfg.MaxWeeklyHours[contractType] = hours
+		}
+	}
+	switch policy := strings.ToLower(getenv("WORKLOAD_POLICY")); policy {
+	case "":
+	case "warn", "reject":
+		cfg.Policy = policy
+	default:
+		return WorkloadConfig{}, fmt.Errorf("WORKLOAD_POLICY inválido: %q (use 'warn' ou 'reject')", policy)
+	}
+	return cfg, nil
+}
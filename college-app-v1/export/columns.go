@@ -0,0 +1,92 @@
+// export/columns.go
+package export
+
+import (
+	"college-app-v1/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Column extrai o valor de uma coluna exportada a partir de um registro.
+type Column func(record interface{}) interface{}
+
+// StudentColumns são as colunas disponíveis na exportação de alunos.
+var StudentColumns = map[string]Column{
+	"id":            func(r interface{}) interface{} { return r.(*models.Student).ID },
+	"enrollment":    func(r interface{}) interface{} { return r.(*models.Student).Enrollment },
+	"name":          func(r interface{}) interface{} { return r.(*models.Student).Name },
+	"current_year":  func(r interface{}) interface{} { return r.(*models.Student).CurrentYear },
+	"shift":         func(r interface{}) interface{} { return r.(*models.Student).Shift },
+	"program_id":    func(r interface{}) interface{} { return r.(*models.Student).ProgramID },
+	"curriculum_id": func(r interface{}) interface{} { return r.(*models.Student).CurriculumID },
+	"version":       func(r interface{}) interface{} { return r.(*models.Student).Version },
+	"deleted_at":    func(r interface{}) interface{} { return formatTime(r.(*models.Student).DeletedAt) },
+	"subjects":      func(r interface{}) interface{} { return subjectNames(r.(*models.Student).Subjects) },
+}
+
+// DefaultStudentColumns são as colunas exportadas quando nenhuma é escolhida.
+var DefaultStudentColumns = []string{"enrollment", "name", "current_year", "shift", "subjects"}
+
+// TeacherColumns são as colunas disponíveis na exportação de professores.
+var TeacherColumns = map[string]Column{
+	"id":            func(r interface{}) interface{} { return r.(*models.Teacher).ID },
+	"name":          func(r interface{}) interface{} { return r.(*models.Teacher).Name },
+	"email":         func(r interface{}) interface{} { return r.(*models.Teacher).Email },
+	"department":    func(r interface{}) interface{} { return r.(*models.Teacher).Department },
+	"department_id": func(r interface{}) interface{} { return r.(*models.Teacher).DepartmentID },
+	"contract_type": func(r interface{}) interface{} { return r.(*models.Teacher).ContractType },
+	"version":       func(r interface{}) interface{} { return r.(*models.Teacher).Version },
+	"deleted_at":    func(r interface{}) interface{} { return formatTime(r.(*models.Teacher).DeletedAt) },
+	"subjects":      func(r interface{}) interface{} { return subjectNames(r.(*models.Teacher).Subjects) },
+}
+
+// DefaultTeacherColumns são as colunas exportadas quando nenhuma é escolhida.
+var DefaultTeacherColumns = []string{"name", "email", "department", "subjects"}
+
+// SubjectColumns são as colunas disponíveis na exportação de matérias.
+var SubjectColumns = map[string]Column{
+	"id":         func(r interface{}) interface{} { return r.(*models.Subject).ID },
+	"name":       func(r interface{}) interface{} { return r.(*models.Subject).Name },
+	"year":       func(r interface{}) interface{} { return r.(*models.Subject).Year },
+	"credits":    func(r interface{}) interface{} { return r.(*models.Subject).Credits },
+	"mandatory":  func(r interface{}) interface{} { return r.(*models.Subject).Mandatory },
+	"version":    func(r interface{}) interface{} { return r.(*models.Subject).Version },
+	"deleted_at": func(r interface{}) interface{} { return formatTime(r.(*models.Subject).DeletedAt) },
+}
+
+// DefaultSubjectColumns são as colunas exportadas quando nenhuma é escolhida.
+var DefaultSubjectColumns = []string{"id", "name", "year", "credits", "mandatory"}
+
+// subjectNames junta os nomes das matérias associadas em uma única célula.
+func subjectNames(subjects []models.Subject) string {
+	names := make([]string, len(subjects))
+	for i, subject := range subjects {
+		names[i] = subject.Name
+	}
+	return strings.Join(names, "; ")
+}
+
+// formatTime formata um instante opcional em RFC 3339 (vazio se nil).
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// SelectColumns resolve os nomes de colunas escolhidos contra as colunas disponíveis da entidade.
+func SelectColumns(available map[string]Column, names []string) ([]Column, error) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		column, known := available[name]
+		if !known {
+			return nil, fmt.Errorf("coluna de exportação desconhecida: %s", name)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("informe ao menos uma coluna para exportação")
+	}
+	return columns, nil
+}
@@ -0,0 +1,98 @@
+// export/table.go
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formatos de exportação suportados e seus tipos MIME.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	ContentTypeCSV  = "text/csv"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// TableWriter escreve uma tabela linha a linha, sem manter as linhas em memória.
+// Células int são gravadas como números; as demais como texto.
+type TableWriter interface {
+	WriteRow(cells []interface{}) error
+	// Close finaliza o arquivo. Deve ser chamado mesmo que nenhuma linha tenha sido escrita.
+	Close() error
+}
+
+// NewTableWriter cria o TableWriter do formato informado (FormatCSV ou FormatXLSX).
+func NewTableWriter(format string, w io.Writer, sheetName string) (TableWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheetName)
+	default:
+		return nil, fmt.Errorf("formato de exportação desconhecido: %q", format)
+	}
+}
+
+// ContentType retorna o tipo MIME do formato de exportação.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return ContentTypeXLSX
+	}
+	return ContentTypeCSV + "; charset=utf-8"
+}
+
+// CSVWriter escreve a tabela em CSV (UTF-8 com BOM, para que o Excel reconheça a acentuação).
+type CSVWriter struct {
+	csv *csv.Writer
+}
+
+// NewCSVWriter cria um CSVWriter e escreve o BOM.
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &CSVWriter{csv: csv.NewWriter(w)}, nil
+}
+
+// csvFormulaPrefixes são os caracteres que, no início de uma célula, fazem planilhas a
+// interpretarem como fórmula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// WriteRow escreve uma linha do CSV. Textos que começam com =, +, -, @, tabulação ou retorno de
+// carro recebem um apóstrofo na frente, para que planilhas não os interpretem como fórmulas (ex:
+// um nome cadastrado como "=HYPERLINK(...)"). Números não são alterados.
+func (w *CSVWriter) WriteRow(cells []interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cellText(cell)
+		if text, ok := cell.(string); ok && text != "" && strings.IndexByte(csvFormulaPrefixes, text[0]) >= 0 {
+			record[i] = "'" + text
+		}
+	}
+	return w.csv.Write(record)
+}
+
+// Close descarrega o buffer do CSV.
+func (w *CSVWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// cellText converte uma célula para texto.
+func cellText(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
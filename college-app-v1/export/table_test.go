@@ -0,0 +1,144 @@
+// export/table_test.go
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]interface{}
+		want string
+	}{
+		{name: "sem linhas", want: ""},
+		{name: "tipos de célula", rows: [][]interface{}{{"name", "year", "mandatory", "deleted_at"}, {"Cálculo I", 1, true, nil}},
+			want: "name,year,mandatory,deleted_at\nCálculo I,1,true,\n"},
+		{name: "aspas, separador e quebra de linha", rows: [][]interface{}{{`Ana "Bia"`, "Silva, Ana", "linha 1\nlinha 2"}},
+			want: "\"Ana \"\"Bia\"\"\",\"Silva, Ana\",\"linha 1\nlinha 2\"\n"},
+		{name: "fórmulas neutralizadas", rows: [][]interface{}{{"=HYPERLINK(\"x\")", "+1", "-2", "@SUM(A1)", "\tTab", "Ana=Bia"}},
+			want: "\"'=HYPERLINK(\"\"x\"\")\",'+1,'-2,'@SUM(A1),'\tTab,Ana=Bia\n"},
+		{name: "números negativos mantidos", rows: [][]interface{}{{-3, ""}}, want: "-3,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewTableWriter(FormatCSV, &buf, "ignorado")
+			if err != nil {
+				t.Fatalf("NewTableWriter: %v", err)
+			}
+			for _, row := range tt.rows {
+				if err := w.WriteRow(row); err != nil {
+					t.Fatalf("WriteRow: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			got, hasBOM := strings.CutPrefix(buf.String(), "\xef\xbb\xbf")
+			if !hasBOM {
+				t.Error("CSV sem BOM UTF-8")
+			}
+			if got != tt.want {
+				t.Errorf("CSV = %q, esperava %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetName string
+		rows      [][]interface{}
+		wantSheet string // Conteúdo de <sheetData>
+	}{
+		{name: "sem linhas", sheetName: "Alunos", wantSheet: ""},
+		{name: "número e texto", sheetName: "Matérias", rows: [][]interface{}{{"name", "year"}, {"Cálculo I", 1}},
+			wantSheet: `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">name</t></is></c><c t="inlineStr"><is><t xml:space="preserve">year</t></is></c></row>` +
+				`<row r="2"><c t="inlineStr"><is><t xml:space="preserve">Cálculo I</t></is></c><c><v>1</v></c></row>`},
+		{name: "texto escapado e célula vazia", sheetName: "P&D <1>", rows: [][]interface{}{{"<b>&</b>", nil, false}},
+			wantSheet: `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">&lt;b&gt;&amp;&lt;/b&gt;</t></is></c>` +
+				`<c t="inlineStr"><is><t xml:space="preserve"></t></is></c><c t="inlineStr"><is><t xml:space="preserve">false</t></is></c></row>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewTableWriter(FormatXLSX, &buf, tt.sheetName)
+			if err != nil {
+				t.Fatalf("NewTableWriter: %v", err)
+			}
+			for _, row := range tt.rows {
+				if err := w.WriteRow(row); err != nil {
+					t.Fatalf("WriteRow: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("zip inválido: %v", err)
+			}
+			parts := map[string]string{}
+			for _, f := range archive.File {
+				rc, err := f.Open()
+				if err != nil {
+					t.Fatalf("abrir %s: %v", f.Name, err)
+				}
+				content, err := io.ReadAll(rc)
+				rc.Close()
+				if err != nil {
+					t.Fatalf("ler %s: %v", f.Name, err)
+				}
+				parts[f.Name] = string(content)
+			}
+			for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+				content, ok := parts[name]
+				if !ok {
+					t.Fatalf("parte %s ausente", name)
+				}
+				if err := checkWellFormed(content); err != nil {
+					t.Errorf("%s não é XML válido: %v", name, err)
+				}
+			}
+
+			var workbook struct {
+				Sheets []struct {
+					Name string `xml:"name,attr"`
+				} `xml:"sheets>sheet"`
+			}
+			if err := xml.Unmarshal([]byte(parts["xl/workbook.xml"]), &workbook); err != nil || len(workbook.Sheets) != 1 || workbook.Sheets[0].Name != tt.sheetName {
+				t.Errorf("planilhas = %+v (%v), esperava uma chamada %q", workbook.Sheets, err, tt.sheetName)
+			}
+			sheet := parts["xl/worksheets/sheet1.xml"]
+			if got := sheet[strings.Index(sheet, "<sheetData>")+len("<sheetData>") : strings.Index(sheet, "</sheetData>")]; got != tt.wantSheet {
+				t.Errorf("sheetData = %s, esperava %s", got, tt.wantSheet)
+			}
+		})
+	}
+}
+
+func TestNewTableWriterUnknownFormat(t *testing.T) {
+	if _, err := NewTableWriter("pdf", io.Discard, "Alunos"); err == nil {
+		t.Error("formato desconhecido aceito")
+	}
+}
+
+// checkWellFormed percorre todos os tokens de content, falhando no primeiro erro de sintaxe.
+func checkWellFormed(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
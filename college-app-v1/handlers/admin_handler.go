@@ -0,0 +1,36 @@
+// handlers/admin_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// AdminHandler agrupa as operações administrativas de manutenção.
+type AdminHandler struct {
+	purgeService *services.PurgeService
+	logger       *slog.Logger
+}
+
+// NewAdminHandler cria uma nova instância de AdminHandler.
+func NewAdminHandler(ps *services.PurgeService, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{purgeService: ps, logger: logger}
+}
+
+// PurgeDeletedHandler remove definitivamente os registros excluídos além do período de retenção.
+// Pode ser agendado como cron job na Vercel.
+// POST /admin/purge-deleted
+func (h *AdminHandler) PurgeDeletedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	result, err := h.purgeService.PurgeDeleted(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "erro ao purgar registros excluídos", "error", err)
+		http.Error(w, `{"message": "Erro ao purgar registros excluídos: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
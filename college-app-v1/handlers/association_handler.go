@@ -0,0 +1,90 @@
+// handlers/association_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AssociationHandler gerencia as associações em lote entre matérias, alunos e professores.
+type AssociationHandler struct {
+	service *services.AssociationService
+	logger  *slog.Logger
+}
+
+// NewAssociationHandler cria uma nova instância de AssociationHandler.
+func NewAssociationHandler(s *services.AssociationService, logger *slog.Logger) *AssociationHandler {
+	return &AssociationHandler{service: s, logger: logger}
+}
+
+// associateFunc é a assinatura comum dos métodos de associação em lote do AssociationService.
+type associateFunc func(ctx context.Context, id string, itemIDs []string) (*services.AssociationResult, error)
+
+// ReplaceStudentSubjectsHandler substitui o conjunto de matérias de um aluno.
+// PUT /students/{id}/subjects  {"subject_ids": ["BSI101", ...]}
+func (h *AssociationHandler) ReplaceStudentSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleAssociation(w, r, "subject_ids", h.service.ReplaceStudentSubjects)
+}
+
+// EnrollStudentsHandler associa vários alunos a uma matéria.
+// POST /subjects/{id}/students  {"student_ids": ["...", ...]}
+func (h *AssociationHandler) EnrollStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleAssociation(w, r, "student_ids", h.service.EnrollStudentsInSubject)
+}
+
+// ReplaceTeacherSubjectsHandler substitui o conjunto de matérias de um professor.
+// PUT /teachers/{id}/subjects  {"subject_ids": ["BSI101", ...]}
+func (h *AssociationHandler) ReplaceTeacherSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleAssociation(w, r, "subject_ids", h.service.ReplaceTeacherSubjects)
+}
+
+// AssignTeachersHandler associa vários professores a uma matéria.
+// POST /subjects/{id}/teachers  {"teacher_ids": ["...", ...]}
+func (h *AssociationHandler) AssignTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleAssociation(w, r, "teacher_ids", h.service.AssignTeachersToSubject)
+}
+
+// handleAssociation lê a lista de IDs do campo field e executa a associação. Responde 200 com
+// o resumo por item, ou 422 com o mesmo resumo se algum ID impediu a gravação do lote.
+func (h *AssociationHandler) handleAssociation(w http.ResponseWriter, r *http.Request, field string, associate associateFunc) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+	var itemIDs []string
+	raw, present := body[field]
+	if !present || json.Unmarshal(raw, &itemIDs) != nil || itemIDs == nil {
+		http.Error(w, `{"message": "Requisição inválida: informe `+field+` como uma lista de IDs."}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := associate(r.Context(), id, itemIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAssociationOwnerNotFound):
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrInvalidAssociationRequest):
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusBadRequest)
+		default:
+			h.logger.ErrorContext(r.Context(), "erro na associação em lote", "owner_id", id, "field", field, "error", err)
+			http.Error(w, `{"message": "Erro ao associar em lote: `+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !result.Applied {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(result)
+}
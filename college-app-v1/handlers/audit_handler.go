@@ -0,0 +1,55 @@
+// handlers/audit_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// AuditHandler expõe o log de auditoria para administradores.
+type AuditHandler struct {
+	service *services.AuditService
+	logger  *slog.Logger
+}
+
+// NewAuditHandler cria uma nova instância de AuditHandler.
+func NewAuditHandler(s *services.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{service: s, logger: logger}
+}
+
+// GetAuditEventsHandler lista os eventos de auditoria, com filtros opcionais.
+// GET /audit?entity=student&id=X&limit=N
+func (h *AuditHandler) GetAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	entityFilter := query.Get("entity")
+	idFilter := query.Get("id")
+
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			http.Error(w, `{"message": "Limite inválido fornecido. Deve ser um número inteiro positivo."}`, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	events, err := h.service.ListEvents(r.Context(), entityFilter, idFilter, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAuditEntity) {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar eventos de auditoria", "error", err)
+		http.Error(w, `{"message": "Erro ao buscar eventos de auditoria."}`, http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,68 @@
+// handlers/curriculum_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CurriculumHandler gerencia a matrícula automática de alunos nas matérias obrigatórias do ano.
+type CurriculumHandler struct {
+	service *services.CurriculumService
+	logger  *slog.Logger
+}
+
+// NewCurriculumHandler cria uma nova instância de CurriculumHandler.
+func NewCurriculumHandler(s *services.CurriculumService, logger *slog.Logger) *CurriculumHandler {
+	return &CurriculumHandler{service: s, logger: logger}
+}
+
+// EnrollStudentHandler matricula um aluno nas matérias obrigatórias do seu ano atual.
+// POST /students/{id}/enroll-curriculum (administradores)
+func (h *CurriculumHandler) EnrollStudentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	result, err := h.service.EnrollStudent(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrAssociationOwnerNotFound) {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao matricular aluno no currículo", "student_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao matricular aluno no currículo: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// EnrollBatchHandler matricula pelo currículo todos os alunos de um ano e, opcionalmente, turno.
+// POST /students/enroll-curriculum?current_year=1&shift=M (administradores)
+func (h *CurriculumHandler) EnrollBatchHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	year, err := strconv.Atoi(query.Get("current_year"))
+	if err != nil {
+		http.Error(w, `{"message": "Parâmetro current_year obrigatório e deve ser um número inteiro."}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.EnrollYearAndShift(r.Context(), year, query.Get("shift"))
+	if err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro na matrícula por currículo em lote", "year", year, "shift", query.Get("shift"), "error", err)
+		http.Error(w, `{"message": "Erro na matrícula por currículo: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
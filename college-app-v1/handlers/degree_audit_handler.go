@@ -0,0 +1,70 @@
+// handlers/degree_audit_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// DegreeAuditHandler gerencia a auditoria de formatura dos alunos.
+type DegreeAuditHandler struct {
+	service *services.DegreeAuditService
+	logger  *slog.Logger
+}
+
+// NewDegreeAuditHandler cria uma nova instância de DegreeAuditHandler.
+func NewDegreeAuditHandler(s *services.DegreeAuditService, logger *slog.Logger) *DegreeAuditHandler {
+	return &DegreeAuditHandler{service: s, logger: logger}
+}
+
+// StudentDegreeAuditHandler compara as matérias do aluno com os requisitos de formatura,
+// listando os cumpridos e os que faltam.
+// GET /students/{id}/degree-audit
+func (h *DegreeAuditHandler) StudentDegreeAuditHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	audit, err := h.service.AuditStudent(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrStudentNotFound) {
+			http.Error(w, `{"message": "Aluno não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro na auditoria de formatura", "student_id", id, "error", err)
+		http.Error(w, `{"message": "Erro na auditoria de formatura: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(audit)
+}
+
+// GraduationCandidatesHandler lista os alunos ativos aptos a se formar.
+// GET /reports/graduation-candidates?program_id=...&current_year=4 (filtros opcionais)
+func (h *DegreeAuditHandler) GraduationCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	var year *int
+	if raw := query.Get("current_year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, `{"message": "Parâmetro current_year deve ser um número inteiro."}`, http.StatusBadRequest)
+			return
+		}
+		year = &parsed
+	}
+
+	programID := query.Get("program_id")
+	report, err := h.service.GetGraduationCandidates(r.Context(), programID, year)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "erro ao listar alunos aptos a se formar", "program_id", programID, "error", err)
+		http.Error(w, `{"message": "Erro ao listar alunos aptos a se formar: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
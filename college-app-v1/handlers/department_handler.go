@@ -0,0 +1,182 @@
+// handlers/department_handler.go
+package handlers
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// DepartmentHandler gerencia as requisições HTTP de departamentos.
+type DepartmentHandler struct {
+	service *services.DepartmentService
+	logger  *slog.Logger
+}
+
+// NewDepartmentHandler cria uma nova instância de DepartmentHandler.
+func NewDepartmentHandler(s *services.DepartmentService, logger *slog.Logger) *DepartmentHandler {
+	return &DepartmentHandler{service: s, logger: logger}
+}
+
+// CreateDepartmentHandler cria um departamento.
+// POST /departments  {"code": "DCC", "name": "Ciência da Computação"}
+func (h *DepartmentHandler) CreateDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var department models.Department
+	if err := json.NewDecoder(r.Body).Decode(&department); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateDepartment(r.Context(), &department); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar departamento", "error", err)
+		http.Error(w, `{"message": "Erro ao criar departamento: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(department.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(department)
+}
+
+// GetAllDepartmentsHandler lista os departamentos.
+// GET /departments
+func (h *DepartmentHandler) GetAllDepartmentsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	departments, err := h.service.GetAllDepartments(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "erro ao listar departamentos", "error", err)
+		http.Error(w, `{"message": "Erro ao buscar departamentos: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(departments)
+}
+
+// GetDepartmentByIDHandler busca um departamento pelo ID.
+// GET /departments/{id}
+func (h *DepartmentHandler) GetDepartmentByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	department, err := h.service.GetDepartmentByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "não encontrado") {
+			http.Error(w, `{"message": "Departamento não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar departamento", "department_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar departamento: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if writeETag(w, r, department.Version) {
+		return
+	}
+	json.NewEncoder(w).Encode(department)
+}
+
+// UpdateDepartmentHandler atualiza código, nome e chefe de um departamento. Exige If-Match com a versão atual.
+// PUT /departments/{id}  {"code": "DCC", "name": "Ciência da Computação", "head_teacher_id": "..."}
+func (h *DepartmentHandler) UpdateDepartmentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	var department models.Department
+	if err := json.NewDecoder(r.Body).Decode(&department); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	department.ID = id           // Garante que o ID da URL seja usado
+	department.Version = version // Versão conhecida pelo cliente (If-Match)
+	if err := h.service.UpdateDepartment(r.Context(), &department); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if err.Error() == "departamento não encontrado para atualização" {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar departamento", "department_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao atualizar departamento: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(department.Version))
+	json.NewEncoder(w).Encode(department)
+}
+
+// GetDepartmentTeachersHandler lista os professores ativos de um departamento.
+// GET /departments/{id}/teachers
+func (h *DepartmentHandler) GetDepartmentTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	teachers, err := h.service.GetDepartmentTeachers(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "departamento com ID") {
+			http.Error(w, `{"message": "Departamento não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar professores do departamento", "department_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar professores do departamento: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(teachers)
+}
+
+// GetDepartmentSubjectsHandler lista as matérias lecionadas pelos professores de um departamento.
+// GET /departments/{id}/subjects
+func (h *DepartmentHandler) GetDepartmentSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	subjects, err := h.service.GetDepartmentSubjects(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "departamento com ID") {
+			http.Error(w, `{"message": "Departamento não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar matérias do departamento", "department_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar matérias do departamento: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(subjects)
+}
+
+// currentVersion retorna a versão atual do departamento id para If-Match: * (0 se não existir).
+func (h *DepartmentHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		department, err := h.service.GetDepartmentByID(r.Context(), id)
+		if err != nil {
+			if strings.Contains(err.Error(), "não encontrado") {
+				return 0, nil
+			}
+			h.logger.ErrorContext(r.Context(), "erro ao buscar versão atual do departamento", "department_id", id, "error", err)
+			return 0, err
+		}
+		return department.Version, nil
+	}
+}
@@ -0,0 +1,140 @@
+// handlers/export_handler.go
+package handlers
+
+import (
+	"college-app-v1/export"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// exportFormatParam lê o formato de exportação de uma listagem: ?format=csv|xlsx|json tem
+// precedência; sem ele, o header Accept é consultado (text/csv ou o tipo MIME do XLSX).
+// Retorna "" para a resposta JSON padrão. Em caso de erro, a resposta já foi escrita e ok é false.
+func exportFormatParam(w http.ResponseWriter, r *http.Request) (format string, ok bool) {
+	w.Header().Add("Vary", "Accept")
+
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "":
+	case "json":
+		return "", true
+	case export.FormatCSV:
+		return export.FormatCSV, true
+	case export.FormatXLSX:
+		return export.FormatXLSX, true
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"message": "Formato inválido. Use json, csv ou xlsx."}`, http.StatusBadRequest)
+		return "", false
+	}
+
+	// Vale o primeiro tipo suportado na ordem do Accept (q-values não são considerados).
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return "", true
+		case export.ContentTypeCSV:
+			return export.FormatCSV, true
+		case export.ContentTypeXLSX:
+			return export.FormatXLSX, true
+		}
+	}
+	return "", true
+}
+
+// tableExport escreve uma listagem como CSV/XLSX em streaming. Os headers e a linha de
+// cabeçalho só são escritos na primeira linha (ou em finish), para que erros anteriores
+// ao início dos dados ainda possam ser respondidos com o status adequado.
+type tableExport struct {
+	w       http.ResponseWriter
+	ctx     context.Context
+	logger  *slog.Logger
+	format  string
+	entity  string
+	names   []string
+	columns []export.Column
+	table   export.TableWriter
+}
+
+// newTableExport valida o parâmetro ?columns= (lista separada por vírgulas) contra as colunas
+// disponíveis da entidade. Em caso de erro, a resposta já foi escrita e ok é false.
+func newTableExport(w http.ResponseWriter, r *http.Request, logger *slog.Logger, format, entity string, available map[string]export.Column, defaults []string) (*tableExport, bool) {
+	names := defaults
+	if raw := r.URL.Query().Get("columns"); raw != "" {
+		names = nil
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	columns, err := export.SelectColumns(available, names)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()}) // A mensagem inclui o nome informado pelo cliente
+		return nil, false
+	}
+	e := &tableExport{w: w, ctx: r.Context(), logger: logger, format: format, entity: entity, names: names, columns: columns}
+	return e, true
+}
+
+// start define os headers da resposta e escreve a linha de cabeçalho.
+func (e *tableExport) start() error {
+	e.w.Header().Set("Content-Type", export.ContentType(e.format))
+	e.w.Header().Set("Content-Disposition", `attachment; filename="`+e.entity+"."+e.format+`"`)
+	table, err := export.NewTableWriter(e.format, e.w, e.entity)
+	if err != nil {
+		return err
+	}
+	e.table = table
+	header := make([]interface{}, len(e.names))
+	for i, name := range e.names {
+		header[i] = name
+	}
+	return e.table.WriteRow(header)
+}
+
+// writeRecord escreve uma linha com as colunas selecionadas do registro.
+func (e *tableExport) writeRecord(record interface{}) error {
+	if e.table == nil {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	row := make([]interface{}, len(e.columns))
+	for i, column := range e.columns {
+		row[i] = column(record)
+	}
+	return e.table.WriteRow(row)
+}
+
+// finish encerra a exportação com o resultado do streaming. Um erro antes da primeira linha
+// é respondido com 500; depois dela o status já foi enviado, então a conexão é abortada
+// para que o cliente não receba um arquivo truncado como se estivesse completo.
+func (e *tableExport) finish(err error) {
+	if err != nil && e.table == nil {
+		e.logger.ErrorContext(e.ctx, "erro ao exportar", "entity", e.entity, "format", e.format, "error", err)
+		e.w.Header().Set("Content-Type", "application/json")
+		http.Error(e.w, `{"message": "Erro ao exportar: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	if err == nil && e.table == nil {
+		err = e.start() // Nenhum registro: arquivo apenas com o cabeçalho
+	}
+	if err == nil {
+		err = e.table.Close()
+	}
+	if err != nil {
+		e.logger.ErrorContext(e.ctx, "erro durante o envio da exportação", "entity", e.entity, "format", e.format, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
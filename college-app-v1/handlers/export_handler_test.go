@@ -0,0 +1,55 @@
+// handlers/export_handler_test.go
+package handlers
+
+import (
+	"college-app-v1/export"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewTableExportColumns(t *testing.T) {
+	available := export.SubjectColumns
+	tests := []struct {
+		name        string
+		columns     string
+		wantStatus  int    // 0: colunas aceitas
+		wantMessage string // Mensagem esperada no corpo do erro
+	}{
+		{name: "colunas padrão"},
+		{name: "colunas informadas", columns: "year, name"},
+		{name: "coluna desconhecida", columns: "name,idade", wantStatus: http.StatusBadRequest,
+			wantMessage: "coluna de exportação desconhecida: idade"},
+		{name: "aspas e barras no nome", columns: `x"}, "y\`, wantStatus: http.StatusBadRequest,
+			wantMessage: `coluna de exportação desconhecida: x"}`},
+		{name: "só separadores", columns: ", ,", wantStatus: http.StatusBadRequest,
+			wantMessage: "informe ao menos uma coluna para exportação"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/subjects?format=csv&columns="+url.QueryEscape(tt.columns), nil)
+			w := httptest.NewRecorder()
+			_, ok := newTableExport(w, r, slog.New(slog.DiscardHandler), export.FormatCSV, "subjects", available, export.DefaultSubjectColumns)
+
+			if tt.wantStatus == 0 {
+				if !ok {
+					t.Fatalf("colunas recusadas (status %d): %s", w.Code, w.Body.String())
+				}
+				return
+			}
+			if ok || w.Code != tt.wantStatus {
+				t.Fatalf("ok = %v, status = %d, esperava status %d", ok, w.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("corpo não é JSON válido: %q (%v)", w.Body.String(), err)
+			}
+			if body["message"] != tt.wantMessage {
+				t.Errorf("mensagem = %q, esperava %q", body["message"], tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// handlers/health_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// HealthHandler expõe as sondas de vida (/healthz) e de prontidão (/readyz).
+type HealthHandler struct {
+	service *services.HealthService
+	logger  *slog.Logger
+}
+
+// NewHealthHandler cria uma nova instância de HealthHandler.
+func NewHealthHandler(s *services.HealthService, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{service: s, logger: logger}
+}
+
+// LivenessHandler indica que o processo está de pé. Não consulta dependências, para que uma
+// falha do banco não faça o orquestrador reiniciar a aplicação.
+// GET /healthz
+func (h *HealthHandler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": services.HealthStatusOK})
+}
+
+// ReadinessHandler verifica as dependências e responde 503 se alguma estiver indisponível.
+// GET /readyz
+func (h *HealthHandler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Readiness(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != services.HealthStatusOK {
+		var failures []any // dependência -> motivo
+		for name, check := range report.Checks {
+			if check.Status != services.HealthStatusOK {
+				failures = append(failures, name, check.Error)
+			}
+		}
+		h.logger.WarnContext(r.Context(), "aplicação não está pronta", failures...)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,122 @@
+// handlers/import_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// maxImportBytes limita o tamanho dos arquivos CSV enviados para importação.
+const maxImportBytes = 10 << 20
+
+// ImportHandler gerencia a importação em lote de alunos, professores e matérias via CSV.
+type ImportHandler struct {
+	service *services.ImportService
+	logger  *slog.Logger
+}
+
+// NewImportHandler cria uma nova instância de ImportHandler.
+func NewImportHandler(s *services.ImportService, logger *slog.Logger) *ImportHandler {
+	return &ImportHandler{service: s, logger: logger}
+}
+
+// importFunc é a assinatura comum dos métodos de importação do ImportService.
+type importFunc func(ctx context.Context, file io.Reader, dryRun bool) (*services.ImportResult, error)
+
+// ImportStudentsHandler importa alunos de um CSV (colunas: name, shift, current_year).
+// POST /imports/students?dry_run=true
+func (h *ImportHandler) ImportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleImport(w, r, h.service.ImportStudents)
+}
+
+// ImportTeachersHandler importa professores de um CSV (colunas: name, email, department — código ou nome — e contract_type opcional).
+// POST /imports/teachers?dry_run=true
+func (h *ImportHandler) ImportTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleImport(w, r, h.service.ImportTeachers)
+}
+
+// ImportSubjectsHandler importa matérias de um CSV (colunas: name, year, credits, mandatory).
+// POST /imports/subjects?dry_run=true
+func (h *ImportHandler) ImportSubjectsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleImport(w, r, h.service.ImportSubjects)
+}
+
+// handleImport lê o CSV (corpo text/csv ou campo "file" de um multipart/form-data) e responde:
+// 200 com o relatório em dry run, 201 se tudo foi gravado ou 422 com os erros por linha.
+func (h *ImportHandler) handleImport(w http.ResponseWriter, r *http.Request, importCSV importFunc) {
+	w.Header().Set("Content-Type", "application/json")
+
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			http.Error(w, `{"message": "Parâmetro dry_run inválido. Use true ou false."}`, http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	file, err := importFile(r)
+	if err != nil {
+		http.Error(w, `{"message": "Requisição inválida: `+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := importCSV(r.Context(), file, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, `{"message": "Arquivo excede o limite de 10 MB."}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidImportFile) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": err.Error()}) // Escapa aspas vindas do CSV
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao importar CSV", "error", err)
+		http.Error(w, `{"message": "Erro ao importar arquivo. Nenhum registro foi gravado."}`, http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case len(result.Errors) > 0 && !dryRun:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case !dryRun:
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// importFile retorna o conteúdo do CSV: o campo "file" de um upload multipart/form-data,
+// lido em streaming, ou o próprio corpo da requisição (ex: Content-Type text/csv).
+func importFile(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.New("formulário multipart malformado")
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("campo 'file' ausente no formulário")
+		}
+		if err != nil {
+			return nil, errors.New("formulário multipart malformado")
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// handlers/merge_patch.go
+package handlers
+
+import (
+	"bytes"
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// readOnlyPatchFields são campos presentes nas representações das entidades que não podem
+// ser alterados via PATCH (gerados pelo servidor ou alterados por rotas próprias).
+var readOnlyPatchFields = map[string]bool{
+	"id": true, "enrollment": true, "registry": true, "version": true, "deleted_at": true, "subjects": true,
+}
+
+// decodeMergePatch lê um JSON Merge Patch (RFC 7396) do corpo da requisição para dst,
+// um ponteiro para um dos models.*Patch. Campos ausentes ficam nil; como todos os campos
+// das entidades são obrigatórios, null (remoção) é rejeitado. Em caso de erro a resposta
+// já foi escrita e ok é false.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request, dst interface{}) (ok bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"message": "Content-Type deve ser application/merge-patch+json."}`, http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"message": "Requisição inválida: não foi possível ler o corpo."}`, http.StatusBadRequest)
+		return false
+	}
+	// Um merge patch que não é objeto substituiria a entidade inteira, o que não faz sentido aqui.
+	var patch map[string]json.RawMessage
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) || json.Unmarshal(body, &patch) != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"message": "Requisição inválida: o merge patch deve ser um objeto JSON."}`, http.StatusBadRequest)
+		return false
+	}
+
+	fields := patchFields(dst)
+	invalid := map[string]string{}
+	for name, raw := range patch {
+		field, known := fields[name]
+		switch {
+		case readOnlyPatchFields[name]:
+			invalid[name] = "campo somente leitura"
+		case !known:
+			invalid[name] = "campo desconhecido"
+		case string(raw) == "null":
+			invalid[name] = "campo obrigatório não pode ser removido"
+		default:
+			if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+				invalid[name] = "tipo inválido"
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		writeValidationError(w, &services.ValidationError{Fields: invalid})
+		return false
+	}
+	return true
+}
+
+// patchFields mapeia o nome JSON de cada campo de um *Patch para o campo correspondente.
+func patchFields(dst interface{}) map[string]reflect.Value {
+	v := reflect.ValueOf(dst).Elem()
+	fields := make(map[string]reflect.Value, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+// writeValidationError responde 422 com os erros por campo:
+// {"message": "...", "errors": {"campo": "motivo"}}.
+func writeValidationError(w http.ResponseWriter, err *services.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Dados inválidos: " + err.Error(),
+		"errors":  err.Fields,
+	})
+}
+
+// asValidationError extrai um *services.ValidationError de err, se houver.
+func asValidationError(err error) (*services.ValidationError, bool) {
+	var validationErr *services.ValidationError
+	ok := errors.As(err, &validationErr)
+	return validationErr, ok
+}
@@ -0,0 +1,85 @@
+// handlers/merge_patch_test.go
+package handlers
+
+import (
+	"college-app-v1/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMergePatch(t *testing.T) {
+	name, shift, year := "Ana", "N", 3
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        models.StudentPatch
+		wantStatus  int               // 0: patch aceito
+		wantErrors  map[string]string // Erros por campo esperados (422)
+	}{
+		{name: "objeto vazio não altera nada", contentType: "application/merge-patch+json", body: `{}`},
+		{name: "um campo", contentType: "application/merge-patch+json", body: `{"name":"Ana"}`, want: models.StudentPatch{Name: &name}},
+		{name: "vários campos", contentType: "application/merge-patch+json; charset=utf-8", body: `{"name":"Ana","current_year":3,"shift":"N"}`,
+			want: models.StudentPatch{Name: &name, CurrentYear: &year, Shift: &shift}},
+		{name: "application/json também é aceito", contentType: "application/json", body: `{"shift":"N"}`, want: models.StudentPatch{Shift: &shift}},
+		{name: "Content-Type não suportado", contentType: "text/plain", body: `{}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "sem Content-Type", body: `{}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "array não é merge patch", contentType: "application/merge-patch+json", body: `[{"name":"Ana"}]`, wantStatus: http.StatusBadRequest},
+		{name: "null substituiria a entidade", contentType: "application/merge-patch+json", body: `null`, wantStatus: http.StatusBadRequest},
+		{name: "JSON inválido", contentType: "application/merge-patch+json", body: `{"name":`, wantStatus: http.StatusBadRequest},
+		{name: "remoção de campo obrigatório", contentType: "application/merge-patch+json", body: `{"name":null}`,
+			wantStatus: http.StatusUnprocessableEntity, wantErrors: map[string]string{"name": "campo obrigatório não pode ser removido"}},
+		{name: "campos somente leitura e desconhecidos", contentType: "application/merge-patch+json", body: `{"enrollment":"2025M0001","version":2,"idade":20}`,
+			wantStatus: http.StatusUnprocessableEntity, wantErrors: map[string]string{"enrollment": "campo somente leitura", "version": "campo somente leitura", "idade": "campo desconhecido"}},
+		{name: "tipo inválido", contentType: "application/merge-patch+json", body: `{"current_year":"terceiro"}`,
+			wantStatus: http.StatusUnprocessableEntity, wantErrors: map[string]string{"current_year": "tipo inválido"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/students/1", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			var patch models.StudentPatch
+			ok := decodeMergePatch(w, r, &patch)
+
+			if tt.wantStatus == 0 {
+				if !ok {
+					t.Fatalf("patch recusado: %d %s", w.Code, w.Body.String())
+				}
+				if !reflect.DeepEqual(patch, tt.want) {
+					t.Errorf("patch = %s, esperava %s", describePatch(patch), describePatch(tt.want))
+				}
+				return
+			}
+			if ok {
+				t.Fatalf("patch aceito, esperava status %d", tt.wantStatus)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, esperava %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantErrors != nil {
+				var response struct {
+					Errors map[string]string `json:"errors"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("resposta inválida: %v", err)
+				}
+				if !reflect.DeepEqual(response.Errors, tt.wantErrors) {
+					t.Errorf("erros = %v, esperava %v", response.Errors, tt.wantErrors)
+				}
+			}
+		})
+	}
+}
+
+// describePatch serializa um patch para as mensagens de erro (os campos são ponteiros).
+func describePatch(patch models.StudentPatch) string {
+	raw, _ := json.Marshal(patch)
+	return string(raw)
+}
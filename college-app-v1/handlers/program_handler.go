@@ -0,0 +1,213 @@
+// handlers/program_handler.go
+package handlers
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/services"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ProgramHandler gerencia as requisições HTTP de cursos e grades curriculares.
+type ProgramHandler struct {
+	service *services.ProgramService
+	logger  *slog.Logger
+}
+
+// NewProgramHandler cria uma nova instância de ProgramHandler.
+func NewProgramHandler(s *services.ProgramService, logger *slog.Logger) *ProgramHandler {
+	return &ProgramHandler{service: s, logger: logger}
+}
+
+// CreateProgramHandler cria um curso.
+// POST /programs  {"code": "SI", "name": "Sistemas de Informação", "use_code_in_enrollment": true}
+func (h *ProgramHandler) CreateProgramHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var program models.Program
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateProgram(r.Context(), &program); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar curso", "error", err)
+		http.Error(w, `{"message": "Erro ao criar curso: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(program.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(program)
+}
+
+// GetAllProgramsHandler lista os cursos.
+// GET /programs
+func (h *ProgramHandler) GetAllProgramsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	programs, err := h.service.GetAllPrograms(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "erro ao listar cursos", "error", err)
+		http.Error(w, `{"message": "Erro ao buscar cursos: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(programs)
+}
+
+// GetProgramByIDHandler busca um curso pelo ID.
+// GET /programs/{id}
+func (h *ProgramHandler) GetProgramByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	program, err := h.service.GetProgramByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "não encontrado") {
+			http.Error(w, `{"message": "Curso não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar curso", "program_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar curso: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if writeETag(w, r, program.Version) {
+		return
+	}
+	json.NewEncoder(w).Encode(program)
+}
+
+// UpdateProgramHandler atualiza um curso. Exige If-Match com a versão atual.
+// PUT /programs/{id}
+func (h *ProgramHandler) UpdateProgramHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	var program models.Program
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	program.ID = id           // Garante que o ID da URL seja usado
+	program.Version = version // Versão conhecida pelo cliente (If-Match)
+	if err := h.service.UpdateProgram(r.Context(), &program); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if err.Error() == "curso não encontrado para atualização" {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar curso", "program_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao atualizar curso: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(program.Version))
+	json.NewEncoder(w).Encode(program)
+}
+
+// CreateCurriculumHandler cria a próxima versão da grade curricular de um curso. Versões
+// anteriores não são alteradas: alunos continuam vinculados à grade em que ingressaram.
+// POST /programs/{id}/curricula  {"description": "...", "subjects": [{"subject_id": "BSI101", "year": 1, "mandatory": true}]}
+func (h *ProgramHandler) CreateCurriculumHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	programID := mux.Vars(r)["id"]
+	var curriculum models.Curriculum
+	if err := json.NewDecoder(r.Body).Decode(&curriculum); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateCurriculum(r.Context(), programID, &curriculum); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if strings.Contains(err.Error(), "curso com ID") {
+			http.Error(w, `{"message": "Curso não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar grade curricular", "program_id", programID, "error", err)
+		http.Error(w, `{"message": "Erro ao criar grade curricular: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(curriculum)
+}
+
+// GetCurriculaHandler lista as versões da grade de um curso (sem as matérias).
+// GET /programs/{id}/curricula
+func (h *ProgramHandler) GetCurriculaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	programID := mux.Vars(r)["id"]
+	curricula, err := h.service.GetCurricula(r.Context(), programID)
+	if err != nil {
+		if strings.Contains(err.Error(), "curso com ID") {
+			http.Error(w, `{"message": "Curso não encontrado."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar grades do curso", "program_id", programID, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar grades curriculares: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(curricula)
+}
+
+// GetCurriculumByIDHandler busca uma versão da grade com suas matérias por ano.
+// GET /curricula/{id}
+func (h *ProgramHandler) GetCurriculumByIDHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	curriculum, err := h.service.GetCurriculumByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "não encontrada") {
+			http.Error(w, `{"message": "Grade curricular não encontrada."}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao buscar grade curricular", "curriculum_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao buscar grade curricular: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(curriculum)
+}
+
+// currentVersion retorna a versão atual do curso id para If-Match: * (0 se não existir).
+func (h *ProgramHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		program, err := h.service.GetProgramByID(r.Context(), id)
+		if err != nil {
+			if strings.Contains(err.Error(), "não encontrado") {
+				return 0, nil
+			}
+			h.logger.ErrorContext(r.Context(), "erro ao buscar versão atual do curso", "program_id", id, "error", err)
+			return 0, err
+		}
+		return program.Version, nil
+	}
+}
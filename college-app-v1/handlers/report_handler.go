@@ -0,0 +1,36 @@
+// handlers/report_handler.go
+package handlers
+
+import (
+	"college-app-v1/services"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// ReportHandler gerencia os relatórios da universidade.
+type ReportHandler struct {
+	teacherService *services.TeacherService
+	logger         *slog.Logger
+}
+
+// NewReportHandler cria uma nova instância de ReportHandler.
+func NewReportHandler(ts *services.TeacherService, logger *slog.Logger) *ReportHandler {
+	return &ReportHandler{teacherService: ts, logger: logger}
+}
+
+// TeacherWorkloadReportHandler retorna a carga horária semanal dos professores ativos, com o
+// limite do regime de cada um e os que estão acima dele.
+// GET /reports/teacher-workload?department=DCC (ID, código ou parte do nome do departamento)
+func (h *ReportHandler) TeacherWorkloadReportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	department := r.URL.Query().Get("department")
+	report, err := h.teacherService.GetWorkloadReport(r.Context(), department)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "erro ao gerar relatório de carga horária", "department", department, "error", err)
+		http.Error(w, `{"message": "Erro ao gerar relatório de carga horária: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
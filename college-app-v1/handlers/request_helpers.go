@@ -0,0 +1,78 @@
+// handlers/request_helpers.go
+package handlers
+
+import (
+	"college-app-v1/reqctx"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// includeDeletedParam lê o parâmetro ?include_deleted=true, permitido apenas para administradores.
+// Em caso de erro, a resposta já foi escrita e ok é false.
+func includeDeletedParam(w http.ResponseWriter, r *http.Request) (includeDeleted bool, ok bool) {
+	raw := r.URL.Query().Get("include_deleted")
+	if raw == "" {
+		return false, true
+	}
+	includeDeleted, err := strconv.ParseBool(raw)
+	if err != nil {
+		http.Error(w, `{"message": "Valor inválido para include_deleted. Use true ou false."}`, http.StatusBadRequest)
+		return false, false
+	}
+	if includeDeleted && !reqctx.IsAdmin(r.Context()) {
+		http.Error(w, `{"message": "include_deleted é restrito a administradores."}`, http.StatusForbidden)
+		return false, false
+	}
+	return includeDeleted, true
+}
+
+// etagForVersion formata a versão de um registro como ETag forte (ex: "3").
+func etagForVersion(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// writeETag define o header ETag e informa se a resposta pode ser 304 Not Modified
+// (If-None-Match igual à versão atual). Nesse caso o 304 já foi escrito.
+func writeETag(w http.ResponseWriter, r *http.Request, version int) (notModified bool) {
+	etag := etagForVersion(version)
+	w.Header().Set("ETag", etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.TrimPrefix(strings.TrimSpace(match), "W/") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// ifMatchVersion extrai a versão do header If-Match, obrigatório em PUT, PATCH e DELETE.
+// Sem o header responde 428 Precondition Required; com um valor que não corresponde
+// a nenhuma versão possível responde 412. If-Match: * aceita a versão atual, obtida de
+// current (0 se o registro não existir, o que também resulta em 412). Em caso de erro, ok é false.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request, current func() (int, error)) (version int, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		http.Error(w, `{"message": "Header If-Match obrigatório: envie o ETag obtido no GET do registro."}`, http.StatusPreconditionRequired)
+		return 0, false
+	}
+	if raw == "*" {
+		version, err := current()
+		if err != nil {
+			http.Error(w, `{"message": "Erro ao buscar a versão atual do registro."}`, http.StatusInternalServerError)
+			return 0, false
+		}
+		if version <= 0 {
+			http.Error(w, `{"message": "If-Match: * exige um registro existente."}`, http.StatusPreconditionFailed)
+			return 0, false
+		}
+		return version, true
+	}
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(raw, "W/"))
+	if err == nil {
+		version, err = strconv.Atoi(unquoted)
+	}
+	if err != nil || version <= 0 {
+		http.Error(w, `{"message": "If-Match não corresponde à versão atual do registro."}`, http.StatusPreconditionFailed)
+		return 0, false
+	}
+	return version, true
+}
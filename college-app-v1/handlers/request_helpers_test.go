@@ -0,0 +1,65 @@
+// handlers/request_helpers_test.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		current     int   // Versão atual do registro (0: inexistente)
+		currentErr  error // Erro ao buscar a versão atual
+		wantVersion int
+		wantStatus  int // 0: precondição aceita
+	}{
+		{name: "ETag forte", ifMatch: `"3"`, wantVersion: 3},
+		{name: "ETag fraco", ifMatch: `W/"3"`, wantVersion: 3},
+		{name: "espaços", ifMatch: `  "7" `, wantVersion: 7},
+		{name: "asterisco usa a versão atual", ifMatch: "*", current: 5, wantVersion: 5},
+		{name: "asterisco sem registro", ifMatch: "*", wantStatus: http.StatusPreconditionFailed},
+		{name: "asterisco com erro na busca", ifMatch: "*", currentErr: errors.New("conexão recusada"), wantStatus: http.StatusInternalServerError},
+		{name: "sem header", wantStatus: http.StatusPreconditionRequired},
+		{name: "sem aspas", ifMatch: "3", wantStatus: http.StatusPreconditionFailed},
+		{name: "versão não numérica", ifMatch: `"abc"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "versão zero", ifMatch: `"0"`, wantStatus: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/students/1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			calls := 0
+			current := func() (int, error) {
+				calls++
+				return tt.current, tt.currentErr
+			}
+			version, ok := ifMatchVersion(w, r, current)
+
+			if tt.wantStatus == 0 {
+				if !ok || version != tt.wantVersion {
+					t.Fatalf("ifMatchVersion = %d, %v (status %d), esperava %d, true", version, ok, w.Code, tt.wantVersion)
+				}
+			} else if ok || w.Code != tt.wantStatus {
+				t.Fatalf("ifMatchVersion = %d, %v (status %d), esperava status %d", version, ok, w.Code, tt.wantStatus)
+			}
+			if tt.currentErr != nil && strings.Contains(w.Body.String(), tt.currentErr.Error()) {
+				t.Errorf("corpo expõe o erro interno: %s", w.Body.String())
+			}
+			wantCalls := 0
+			if tt.ifMatch == "*" {
+				wantCalls = 1
+			}
+			if calls != wantCalls {
+				t.Errorf("versão atual buscada %d vezes, esperava %d", calls, wantCalls)
+			}
+		})
+	}
+}
@@ -2,39 +2,64 @@
 package handlers
 
 import (
+	"college-app-v1/export"
 	"college-app-v1/models"   // Certifique-se de que este caminho está correto
 	"college-app-v1/services" // Certifique-se de que este caminho está correto
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv" // Adicionado para converter string de query param para int
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
 // StudentHandler gerencia as requisições HTTP para alunos.
 type StudentHandler struct {
-	service *services.StudentService // Ponteiro para o serviço, conforme sua definição original
+	service    *services.StudentService // Ponteiro para o serviço, conforme sua definição original
+	curriculum *services.CurriculumService
+	logger     *slog.Logger
 }
 
 // NewStudentHandler cria uma nova instância de StudentHandler.
-func NewStudentHandler(s *services.StudentService) *StudentHandler {
-	return &StudentHandler{service: s}
+func NewStudentHandler(s *services.StudentService, c *services.CurriculumService, logger *slog.Logger) *StudentHandler {
+	return &StudentHandler{service: s, curriculum: c, logger: logger}
 }
 
 // CreateStudentHandler lida com a criação de um novo aluno.
-// POST /students
+// POST /students?enroll_curriculum=true (opcional: matricula nas matérias obrigatórias do ano)
 func (h *StudentHandler) CreateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json") // Define o Content-Type no início
 
+	enrollCurriculum := false
+	if raw := r.URL.Query().Get("enroll_curriculum"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, `{"message": "Valor inválido para enroll_curriculum. Use true ou false."}`, http.StatusBadRequest)
+			return
+		}
+		enrollCurriculum = parsed
+	}
+
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
 		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.CreateStudent(&student); err != nil {
-		log.Printf("CreateStudentHandler: Erro ao criar aluno no serviço: %v", err)
+	var err error
+	if enrollCurriculum {
+		_, err = h.curriculum.CreateStudentWithCurriculum(r.Context(), &student)
+	} else {
+		err = h.service.CreateStudent(r.Context(), &student)
+	}
+	if err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar aluno", "error", err)
 		// Você pode adicionar tratamento de erro mais granular aqui com base no tipo de erro retornado pelo serviço.
 		// Ex: if strings.Contains(err.Error(), "turno inválido") { http.Error(w, err.Error(), http.StatusBadRequest) }
 		http.Error(w, `{"message": "Erro ao criar aluno: `+err.Error()+`"}`, http.StatusInternalServerError)
@@ -53,26 +78,38 @@ func (h *StudentHandler) GetStudentByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	student, err := h.service.GetStudentByID(id)
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
+	student, err := h.service.GetStudentByID(r.Context(), id, includeDeleted)
 	if err != nil {
-		// Use errors.Is para verificar tipos de erro específicos, se seus erros forem tratados assim
-		if err.Error() == "aluno com ID "+id+" não encontrado" { // Mensagem de erro específica do serviço
+		if errors.Is(err, services.ErrStudentNotFound) {
 			http.Error(w, `{"message": "Aluno não encontrado."}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("GetStudentByIDHandler: Erro ao buscar aluno no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao buscar aluno", "student_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao buscar aluno: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	if writeETag(w, r, student.Version) {
+		return
+	}
 	json.NewEncoder(w).Encode(student)
 }
 
 // GetAllStudentsHandler lida com a busca de todos os alunos, com filtros opcionais.
-// GET /students?current_year=X&shift=Y
+// GET /students?current_year=X&shift=Y&include_deleted=true
 func (h *StudentHandler) GetAllStudentsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json") // Define o Content-Type no início
 
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
 	// Extrair parâmetros de consulta (query parameters)
 	query := r.URL.Query()
 	yearStr := query.Get("current_year")
@@ -88,10 +125,26 @@ func (h *StudentHandler) GetAllStudentsHandler(w http.ResponseWriter, r *http.Re
 		yearFilter = &parsedYear
 	}
 
+	format, ok := exportFormatParam(w, r)
+	if !ok {
+		return
+	}
+	if format != "" {
+		table, ok := newTableExport(w, r, h.logger, format, "students", export.StudentColumns, export.DefaultStudentColumns)
+		if !ok {
+			return
+		}
+		err := h.service.StreamStudents(r.Context(), yearFilter, shiftFilter, includeDeleted, func(student *models.Student) error {
+			return table.writeRecord(student)
+		})
+		table.finish(err)
+		return
+	}
+
 	// Chamar o serviço com os filtros
-	students, err := h.service.GetAllStudents(yearFilter, shiftFilter)
+	students, err := h.service.GetAllStudents(r.Context(), yearFilter, shiftFilter, includeDeleted)
 	if err != nil {
-		log.Printf("GetAllStudentsHandler: Erro ao buscar todos os alunos no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao listar alunos", "error", err)
 		// Aqui, você pode adicionar tratamento mais específico para erros do serviço (ex: turno inválido no filtro)
 		http.Error(w, `{"message": "Erro ao buscar alunos: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -109,16 +162,26 @@ func (h *StudentHandler) UpdateStudentHandler(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
 		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
 		return
 	}
 
-	student.ID = id // Garante que o ID da URL seja usado para a atualização
+	student.ID = id           // Garante que o ID da URL seja usado para a atualização
+	student.Version = version // Versão conhecida pelo cliente (If-Match)
 
-	if err := h.service.UpdateStudent(&student); err != nil {
-		if err.Error() == "aluno não encontrado para atualização" || err.Error() == "ID do aluno é obrigatório para atualização" {
+	if err := h.service.UpdateStudent(r.Context(), &student); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrStudentNotFound) || err.Error() == "ID do aluno é obrigatório para atualização" {
 			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound) // Use 404 para não encontrado
 			return
 		}
@@ -127,15 +190,57 @@ func (h *StudentHandler) UpdateStudentHandler(w http.ResponseWriter, r *http.Req
 		//    http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusBadRequest)
 		//    return
 		// }
-		log.Printf("UpdateStudentHandler: Erro ao atualizar aluno no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar aluno", "student_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao atualizar aluno: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("ETag", etagForVersion(student.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(student) // Retorna o aluno atualizado
 }
 
+// PatchStudentHandler lida com a atualização parcial de um aluno (JSON Merge Patch, RFC 7396).
+// PATCH /students/{id}
+func (h *StudentHandler) PatchStudentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	var patch models.StudentPatch
+	if !decodeMergePatch(w, r, &patch) {
+		return
+	}
+
+	student, err := h.service.PatchStudent(r.Context(), id, version, &patch)
+	if err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrStudentNotFound) {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar parcialmente aluno", "student_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao atualizar aluno."}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(student.Version))
+	json.NewEncoder(w).Encode(student)
+}
+
 // DeleteStudentHandler lida com a exclusão de um aluno por ID.
 // DELETE /students/{id}
 func (h *StudentHandler) DeleteStudentHandler(w http.ResponseWriter, r *http.Request) {
@@ -144,12 +249,21 @@ func (h *StudentHandler) DeleteStudentHandler(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteStudent(id); err != nil {
-		if err.Error() == "aluno não encontrado para exclusão" {
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteStudent(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrStudentNotFound) {
 			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("DeleteStudentHandler: Erro ao deletar aluno no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao excluir aluno", "student_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao deletar aluno: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -157,6 +271,28 @@ func (h *StudentHandler) DeleteStudentHandler(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusNoContent) // 204 No Content
 }
 
+// RestoreStudentHandler lida com a restauração de um aluno excluído.
+// POST /students/{id}/restore
+func (h *StudentHandler) RestoreStudentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.service.RestoreStudent(r.Context(), id); err != nil {
+		if err.Error() == "aluno excluído não encontrado para restauração" {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao restaurar aluno", "student_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao restaurar aluno: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Aluno restaurado com sucesso."})
+}
+
 // AddSubjectToStudentHandler lida com a adição de uma matéria a um aluno.
 // POST /students/{studentID}/subjects/{subjectID}
 func (h *StudentHandler) AddSubjectToStudentHandler(w http.ResponseWriter, r *http.Request) {
@@ -166,7 +302,7 @@ func (h *StudentHandler) AddSubjectToStudentHandler(w http.ResponseWriter, r *ht
 	studentID := vars["studentID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.AddSubjectToStudent(studentID, subjectID); err != nil {
+	if err := h.service.AddSubjectToStudent(r.Context(), studentID, subjectID); err != nil {
 		// Use um switch ou if-else if para erros mais específicos do serviço
 		errorMessage := err.Error()
 		if errorMessage == "aluno com ID "+studentID+" não encontrado para associação" || errorMessage == "matéria com ID "+subjectID+" não encontrada para associação" {
@@ -178,7 +314,7 @@ func (h *StudentHandler) AddSubjectToStudentHandler(w http.ResponseWriter, r *ht
 			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusConflict) // 409 Conflict
 			return
 		}
-		log.Printf("AddSubjectToStudentHandler: Erro ao adicionar matéria ao aluno no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao associar matéria ao aluno", "student_id", studentID, "subject_id", subjectID, "error", err)
 		http.Error(w, `{"message": "Erro ao adicionar matéria ao aluno: `+errorMessage+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -196,16 +332,65 @@ func (h *StudentHandler) RemoveSubjectFromStudentHandler(w http.ResponseWriter,
 	studentID := vars["studentID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.RemoveSubjectFromStudent(studentID, subjectID); err != nil {
+	if err := h.service.RemoveSubjectFromStudent(r.Context(), studentID, subjectID); err != nil {
 		errorMessage := err.Error()
 		if errorMessage == "aluno com ID "+studentID+" não encontrado para desassociação" || errorMessage == "associação entre aluno "+studentID+" e matéria "+subjectID+" não encontrada para desassociação" {
 			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusNotFound) // 404 Not Found
 			return
 		}
-		log.Printf("RemoveSubjectFromStudentHandler: Erro ao remover matéria do aluno no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao desassociar matéria do aluno", "student_id", studentID, "subject_id", subjectID, "error", err)
 		http.Error(w, `{"message": "Erro ao remover matéria do aluno: `+errorMessage+`"}`, http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent) // 204 No Content
 }
+
+// SetSubjectStatusHandler altera a situação do aluno em uma matéria associada.
+// PUT /students/{studentID}/subjects/{subjectID}/status  {"status": "passed"}
+func (h *StudentHandler) SetSubjectStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	studentID := vars["studentID"]
+	subjectID := vars["subjectID"]
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SetSubjectStatus(r.Context(), studentID, subjectID, body.Status); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if err.Error() == "associação entre aluno "+studentID+" e matéria "+subjectID+" não encontrada" {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao alterar situação do aluno na matéria", "student_id", studentID, "subject_id", subjectID, "error", err)
+		http.Error(w, `{"message": "Erro ao alterar situação do aluno na matéria: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"subject_id": subjectID, "status": strings.ToLower(strings.TrimSpace(body.Status))})
+}
+
+// currentVersion retorna a versão atual do aluno id para If-Match: * (0 se não existir).
+func (h *StudentHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		student, err := h.service.GetStudentByID(r.Context(), id, false)
+		if err != nil {
+			if errors.Is(err, services.ErrStudentNotFound) {
+				return 0, nil
+			}
+			h.logger.ErrorContext(r.Context(), "erro ao buscar versão atual do aluno", "student_id", id, "error", err)
+			return 0, err
+		}
+		return student.Version, nil
+	}
+}
@@ -2,10 +2,12 @@
 package handlers
 
 import (
+	"college-app-v1/export"
 	"college-app-v1/models"
 	"college-app-v1/services"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -14,24 +16,29 @@ import (
 // SubjectHandler gerencia as requisições HTTP para matérias.
 type SubjectHandler struct {
 	service *services.SubjectService
+	logger  *slog.Logger
 }
 
 // NewSubjectHandler cria uma nova instância de SubjectHandler.
-func NewSubjectHandler(s *services.SubjectService) *SubjectHandler {
-	return &SubjectHandler{service: s}
+func NewSubjectHandler(s *services.SubjectService, logger *slog.Logger) *SubjectHandler {
+	return &SubjectHandler{service: s, logger: logger}
 }
 
 // CreateSubjectHandler lida com a criação de uma nova matéria.
 // POST /subjects
 func (h *SubjectHandler) CreateSubjectHandler(w http.ResponseWriter, r *http.Request) {
-	var subject models.Subject
+	subject := models.Subject{Mandatory: true} // Sem o campo no corpo, a matéria é obrigatória
 	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
 		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.CreateSubject(&subject); err != nil {
-		log.Printf("Erro ao criar matéria no serviço: %v", err)
+	if err := h.service.CreateSubject(r.Context(), &subject); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar matéria", "error", err)
 		http.Error(w, "Erro ao criar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -47,27 +54,56 @@ func (h *SubjectHandler) GetSubjectByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	subject, err := h.service.GetSubjectByID(id)
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
+	subject, err := h.service.GetSubjectByID(r.Context(), id, includeDeleted)
 	if err != nil {
-		if err.Error() == "matéria não encontrada" { // Erro personalizado do serviço
+		if errors.Is(err, services.ErrSubjectNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao buscar matéria no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao buscar matéria", "subject_id", id, "error", err)
 		http.Error(w, "Erro ao buscar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if writeETag(w, r, subject.Version) {
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(subject)
 }
 
 // GetAllSubjectsHandler lida com a busca de todas as matérias.
-// GET /subjects
+// GET /subjects?include_deleted=true
 func (h *SubjectHandler) GetAllSubjectsHandler(w http.ResponseWriter, r *http.Request) {
-	subjects, err := h.service.GetAllSubjects()
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
+	format, ok := exportFormatParam(w, r)
+	if !ok {
+		return
+	}
+	if format != "" {
+		table, ok := newTableExport(w, r, h.logger, format, "subjects", export.SubjectColumns, export.DefaultSubjectColumns)
+		if !ok {
+			return
+		}
+		err := h.service.StreamSubjects(r.Context(), includeDeleted, func(subject *models.Subject) error {
+			return table.writeRecord(subject)
+		})
+		table.finish(err)
+		return
+	}
+
+	subjects, err := h.service.GetAllSubjects(r.Context(), includeDeleted)
 	if err != nil {
-		log.Printf("Erro ao buscar todas as matérias no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao listar matérias", "error", err)
 		http.Error(w, "Erro ao buscar matérias: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -82,41 +118,101 @@ func (h *SubjectHandler) UpdateSubjectHandler(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var subject models.Subject
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	subject := models.Subject{Mandatory: true} // Sem o campo no corpo, a matéria é obrigatória
 	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
 		http.Error(w, "Requisição inválida: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	subject.ID = id // Garante que o ID da URL seja usado
+	subject.ID = id           // Garante que o ID da URL seja usado
+	subject.Version = version // Versão conhecida pelo cliente (If-Match)
 
-	if err := h.service.UpdateSubject(&subject); err != nil {
-		if err.Error() == "matéria não encontrada para atualização" {
+	if err := h.service.UpdateSubject(r.Context(), &subject); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "Registro modificado por outra requisição. Recarregue e tente novamente.", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrSubjectNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao atualizar matéria no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar matéria", "subject_id", id, "error", err)
 		http.Error(w, "Erro ao atualizar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagForVersion(subject.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(subject)
 }
 
+// PatchSubjectHandler lida com a atualização parcial de uma matéria (JSON Merge Patch, RFC 7396).
+// PATCH /subjects/{id}
+func (h *SubjectHandler) PatchSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	var patch models.SubjectPatch
+	if !decodeMergePatch(w, r, &patch) {
+		return
+	}
+
+	subject, err := h.service.PatchSubject(r.Context(), id, version, &patch)
+	if err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "Registro modificado por outra requisição. Recarregue e tente novamente.", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrSubjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar parcialmente matéria", "subject_id", id, "error", err)
+		http.Error(w, "Erro ao atualizar matéria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etagForVersion(subject.Version))
+	json.NewEncoder(w).Encode(subject)
+}
+
 // DeleteSubjectHandler lida com a exclusão de uma matéria por ID.
 // DELETE /subjects/{id}
 func (h *SubjectHandler) DeleteSubjectHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteSubject(id); err != nil {
-		if err.Error() == "matéria não encontrada para exclusão" {
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSubject(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, "Registro modificado por outra requisição. Recarregue e tente novamente.", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrSubjectNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		log.Printf("Erro ao deletar matéria no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao excluir matéria", "subject_id", id, "error", err)
 		http.Error(w, "Erro ao deletar matéria: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -124,3 +220,39 @@ func (h *SubjectHandler) DeleteSubjectHandler(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent) // 204 No Content para deleção bem-sucedida
 }
+
+// RestoreSubjectHandler lida com a restauração de uma matéria excluída.
+// POST /subjects/{id}/restore
+func (h *SubjectHandler) RestoreSubjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.service.RestoreSubject(r.Context(), id); err != nil {
+		if err.Error() == "matéria excluída não encontrada para restauração" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao restaurar matéria", "subject_id", id, "error", err)
+		http.Error(w, "Erro ao restaurar matéria: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Matéria restaurada com sucesso."})
+}
+
+// currentVersion retorna a versão atual da matéria id para If-Match: * (0 se não existir).
+func (h *SubjectHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		subject, err := h.service.GetSubjectByID(r.Context(), id, false)
+		if err != nil {
+			if errors.Is(err, services.ErrSubjectNotFound) {
+				return 0, nil
+			}
+			h.logger.ErrorContext(r.Context(), "erro ao buscar versão atual da matéria", "subject_id", id, "error", err)
+			return 0, err
+		}
+		return subject.Version, nil
+	}
+}
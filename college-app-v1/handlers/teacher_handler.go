@@ -3,13 +3,14 @@
 package handlers
 
 import (
+	"college-app-v1/export"
 	"college-app-v1/models"   // Certifique-se de que este caminho está correto
 	"college-app-v1/services" // Certifique-se de que este caminho está correto
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-
-	// "strconv" // Não é necessário aqui, pois os filtros são strings (ou ponteiro para int se fosse numérico)
+	"strconv"
 
 	"github.com/gorilla/mux"
 )
@@ -23,11 +24,12 @@ import (
 // TeacherHandler gerencia as requisições HTTP para professores.
 type TeacherHandler struct {
 	service *services.TeacherService // Ponteiro para o serviço de professor
+	logger  *slog.Logger
 }
 
 // NewTeacherHandler cria uma nova instância de TeacherHandler.
-func NewTeacherHandler(s *services.TeacherService) *TeacherHandler {
-	return &TeacherHandler{service: s}
+func NewTeacherHandler(s *services.TeacherService, logger *slog.Logger) *TeacherHandler {
+	return &TeacherHandler{service: s, logger: logger}
 }
 
 // CreateTeacherHandler lida com a criação de um novo professor.
@@ -41,8 +43,12 @@ func (h *TeacherHandler) CreateTeacherHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.service.CreateTeacher(&teacher); err != nil {
-		log.Printf("CreateTeacherHandler: Erro ao criar professor no serviço: %v", err)
+	if err := h.service.CreateTeacher(r.Context(), &teacher); err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao criar professor", "error", err)
 		http.Error(w, `{"message": "Erro ao criar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -59,40 +65,64 @@ func (h *TeacherHandler) GetTeacherByIDHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	teacher, err := h.service.GetTeacherByID(id)
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
+	teacher, err := h.service.GetTeacherByID(r.Context(), id, includeDeleted)
 	if err != nil {
-		if err.Error() == "professor não encontrado" { // Mensagem de erro específica do serviço
+		if errors.Is(err, services.ErrTeacherNotFound) {
 			http.Error(w, `{"message": "Professor não encontrado."}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("GetTeacherByIDHandler: Erro ao buscar professor no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao buscar professor", "teacher_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao buscar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	if writeETag(w, r, teacher.Version) {
+		return
+	}
 	json.NewEncoder(w).Encode(teacher)
 }
 
 // GetAllTeachersHandler lida com a busca de todos os professores, com filtros opcionais.
-// GET /teachers?name=X&department=Y&email=Z
+// GET /teachers?name=X&department=Y&email=Z&include_deleted=true
 func (h *TeacherHandler) GetAllTeachersHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	includeDeleted, ok := includeDeletedParam(w, r)
+	if !ok {
+		return
+	}
+
 	// Extrair parâmetros de consulta (query parameters)
 	query := r.URL.Query()
 	nameFilter := query.Get("name")
 	departmentFilter := query.Get("department")
 	emailFilter := query.Get("email")
 
-	// --- ADICIONE ESTES LOGS TEMPORÁRIOS PARA DEPURAR (REMOVER EM PRODUÇÃO) ---
-	log.Printf("GetAllTeachersHandler: Requisição recebida. URL: %s", r.URL.String())
-	log.Printf("GetAllTeachersHandler: Filtro Nome: '%s', Departamento: '%s', Email: '%s'", nameFilter, departmentFilter, emailFilter)
-	// --- FIM DOS LOGS TEMPORÁRIOS ---
+	format, ok := exportFormatParam(w, r)
+	if !ok {
+		return
+	}
+	if format != "" {
+		table, ok := newTableExport(w, r, h.logger, format, "teachers", export.TeacherColumns, export.DefaultTeacherColumns)
+		if !ok {
+			return
+		}
+		err := h.service.StreamTeachers(r.Context(), nameFilter, departmentFilter, emailFilter, includeDeleted, func(teacher *models.Teacher) error {
+			return table.writeRecord(teacher)
+		})
+		table.finish(err)
+		return
+	}
 
 	// Chamar o serviço com os filtros
-	teachers, err := h.service.GetAllTeachers(nameFilter, departmentFilter, emailFilter) // <-- NOVA ASSINATURA
+	teachers, err := h.service.GetAllTeachers(r.Context(), nameFilter, departmentFilter, emailFilter, includeDeleted) // <-- NOVA ASSINATURA
 	if err != nil {
-		log.Printf("GetAllTeachersHandler: Erro ao buscar professores no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao listar professores", "name_filter", nameFilter, "department", departmentFilter, "email_filter", emailFilter, "error", err)
 		http.Error(w, `{"message": "Erro ao buscar professores: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -108,27 +138,83 @@ func (h *TeacherHandler) UpdateTeacherHandler(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
 	var teacher models.Teacher
 	if err := json.NewDecoder(r.Body).Decode(&teacher); err != nil {
 		http.Error(w, `{"message": "Requisição inválida: corpo JSON malformado."}`, http.StatusBadRequest)
 		return
 	}
 
-	teacher.ID = id // Garante que o ID da URL seja usado
-	if err := h.service.UpdateTeacher(&teacher); err != nil {
-		if err.Error() == "professor não encontrado para atualização" {
+	teacher.ID = id           // Garante que o ID da URL seja usado
+	teacher.Version = version // Versão conhecida pelo cliente (If-Match)
+	if err := h.service.UpdateTeacher(r.Context(), &teacher); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if errors.Is(err, services.ErrTeacherNotFound) {
 			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("UpdateTeacherHandler: Erro ao atualizar professor no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar professor", "teacher_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao atualizar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("ETag", etagForVersion(teacher.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(teacher)
 }
 
+// PatchTeacherHandler lida com a atualização parcial de um professor (JSON Merge Patch, RFC 7396).
+// PATCH /teachers/{id}
+func (h *TeacherHandler) PatchTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	var patch models.TeacherPatch
+	if !decodeMergePatch(w, r, &patch) {
+		return
+	}
+
+	teacher, err := h.service.PatchTeacher(r.Context(), id, version, &patch)
+	if err != nil {
+		if validationErr, ok := asValidationError(err); ok {
+			writeValidationError(w, validationErr)
+			return
+		}
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrTeacherNotFound) {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao atualizar parcialmente professor", "teacher_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao atualizar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etagForVersion(teacher.Version))
+	json.NewEncoder(w).Encode(teacher)
+}
+
 // DeleteTeacherHandler lida com a exclusão de um professor por ID.
 // DELETE /teachers/{id}
 func (h *TeacherHandler) DeleteTeacherHandler(w http.ResponseWriter, r *http.Request) {
@@ -137,12 +223,21 @@ func (h *TeacherHandler) DeleteTeacherHandler(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.service.DeleteTeacher(id); err != nil {
-		if err.Error() == "professor não encontrado para exclusão" {
+	version, ok := ifMatchVersion(w, r, h.currentVersion(r, id))
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTeacher(r.Context(), id, version); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			http.Error(w, `{"message": "Registro modificado por outra requisição. Recarregue e tente novamente."}`, http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, services.ErrTeacherNotFound) {
 			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("DeleteTeacherHandler: Erro ao deletar professor no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao excluir professor", "teacher_id", id, "error", err)
 		http.Error(w, `{"message": "Erro ao deletar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -150,7 +245,31 @@ func (h *TeacherHandler) DeleteTeacherHandler(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// AddSubjectToTeacherHandler lida com a adição de uma matéria a um professor.
+// RestoreTeacherHandler lida com a restauração de um professor excluído.
+// POST /teachers/{id}/restore
+func (h *TeacherHandler) RestoreTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.service.RestoreTeacher(r.Context(), id); err != nil {
+		if err.Error() == "professor excluído não encontrado para restauração" {
+			http.Error(w, `{"message": "`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "erro ao restaurar professor", "teacher_id", id, "error", err)
+		http.Error(w, `{"message": "Erro ao restaurar professor: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Professor restaurado com sucesso."})
+}
+
+// AddSubjectToTeacherHandler lida com a adição de uma matéria a um professor. A resposta traz a
+// carga horária resultante e, se ela passar do limite do regime do professor, um aviso em "warning"
+// (ou 422, se a política de carga horária for "reject").
 // POST /teachers/{teacherID}/subjects/{subjectID}
 func (h *TeacherHandler) AddSubjectToTeacherHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -159,9 +278,14 @@ func (h *TeacherHandler) AddSubjectToTeacherHandler(w http.ResponseWriter, r *ht
 	teacherID := vars["teacherID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.AddSubjectToTeacher(teacherID, subjectID); err != nil {
+	workload, err := h.service.AddSubjectToTeacher(r.Context(), teacherID, subjectID)
+	if err != nil {
 		errorMessage := err.Error()
-		if errorMessage == "professor não encontrado para associação" || errorMessage == "matéria não encontrada para associação" {
+		if errors.Is(err, services.ErrWorkloadExceeded) {
+			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		if errors.Is(err, services.ErrTeacherNotFound) || errorMessage == "matéria não encontrada para associação" {
 			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusNotFound)
 			return
 		}
@@ -169,13 +293,18 @@ func (h *TeacherHandler) AddSubjectToTeacherHandler(w http.ResponseWriter, r *ht
 			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusConflict)
 			return
 		}
-		log.Printf("AddSubjectToTeacherHandler: Erro ao adicionar matéria ao professor no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao associar matéria ao professor", "teacher_id", teacherID, "subject_id", subjectID, "error", err)
 		http.Error(w, `{"message": "Erro ao adicionar matéria ao professor: `+errorMessage+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	response := map[string]interface{}{"message": "Matéria adicionada ao professor com sucesso.", "workload": workload}
+	if workload.Overloaded {
+		response["warning"] = "Carga horária de " + strconv.Itoa(workload.WeeklyHours) + "h semanais acima do limite de " +
+			strconv.Itoa(workload.MaxWeeklyHours) + "h do regime " + workload.ContractType + "."
+	}
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Matéria adicionada ao professor com sucesso."})
+	json.NewEncoder(w).Encode(response)
 }
 
 // RemoveSubjectFromTeacherHandler lida com a remoção de uma matéria de um professor.
@@ -187,16 +316,31 @@ func (h *TeacherHandler) RemoveSubjectFromTeacherHandler(w http.ResponseWriter,
 	teacherID := vars["teacherID"]
 	subjectID := vars["subjectID"]
 
-	if err := h.service.RemoveSubjectFromTeacher(teacherID, subjectID); err != nil {
+	if err := h.service.RemoveSubjectFromTeacher(r.Context(), teacherID, subjectID); err != nil {
 		errorMessage := err.Error()
 		if errorMessage == "professor não encontrado para desassociação" || errorMessage == "associação entre professor "+teacherID+" e matéria "+subjectID+" não encontrada para desassociação" {
 			http.Error(w, `{"message": "`+errorMessage+`"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("RemoveSubjectFromTeacherHandler: Erro ao remover matéria do professor no serviço: %v", err)
+		h.logger.ErrorContext(r.Context(), "erro ao desassociar matéria do professor", "teacher_id", teacherID, "subject_id", subjectID, "error", err)
 		http.Error(w, `{"message": "Erro ao remover matéria do professor: `+errorMessage+`"}`, http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// currentVersion retorna a versão atual do professor id para If-Match: * (0 se não existir).
+func (h *TeacherHandler) currentVersion(r *http.Request, id string) func() (int, error) {
+	return func() (int, error) {
+		teacher, err := h.service.GetTeacherByID(r.Context(), id, false)
+		if err != nil {
+			if errors.Is(err, services.ErrTeacherNotFound) {
+				return 0, nil
+			}
+			h.logger.ErrorContext(r.Context(), "erro ao buscar versão atual do professor", "teacher_id", id, "error", err)
+			return 0, err
+		}
+		return teacher.Version, nil
+	}
+}
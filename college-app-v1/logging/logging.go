@@ -0,0 +1,107 @@
+// logging/logging.go
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+
+	"college-app-v1/reqctx"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pacote com o logger estruturado (log/slog) usado por repositórios, serviços e handlers.
+// Toda linha registrada com um contexto de requisição recebe request_id e actor (e trace_id,
+// quando há um span ativo), e os dados
+// pessoais (nomes e emails) são mascarados antes de saírem do processo.
+
+// Redacted substitui o valor dos atributos com dados pessoais.
+const Redacted = "[REDACTED]"
+
+// redactedKeys são os atributos cujo valor nunca é registrado (nomes e emails de pessoas e os
+// filtros de busca por eles).
+var redactedKeys = map[string]bool{
+	"name":              true,
+	"email":             true,
+	"student_name":      true,
+	"teacher_name":      true,
+	"head_teacher_name": true,
+	"name_filter":       true,
+	"email_filter":      true,
+}
+
+// emailPattern encontra emails no texto de mensagens e erros (ex: violações de UNIQUE).
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// Options define o formato e o nível do logger.
+type Options struct {
+	Level  slog.Level // Nível mínimo registrado
+	JSON   bool       // Uma linha JSON por registro (produção); senão, texto chave=valor
+	Redact bool       // Mascara nomes e emails
+}
+
+// New cria o logger da aplicação, escrevendo em w.
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	if opts.Redact {
+		handlerOpts.ReplaceAttr = redact
+	}
+	var handler slog.Handler
+	if opts.JSON {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	}
+	return slog.New(&contextHandler{Handler: handler, redact: opts.Redact})
+}
+
+// redact mascara os atributos de dados pessoais e os emails contidos em textos e erros.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if redactedKeys[attr.Key] {
+		return slog.String(attr.Key, Redacted)
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if value := attr.Value.String(); emailPattern.MatchString(value) {
+			return slog.String(attr.Key, emailPattern.ReplaceAllString(value, Redacted))
+		}
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, emailPattern.ReplaceAllString(err.Error(), Redacted))
+		}
+	}
+	return attr
+}
+
+// contextHandler acrescenta a cada registro o ID da requisição e o autor guardados no contexto
+// (reqctx) e mascara emails na mensagem, que não passa por ReplaceAttr.
+type contextHandler struct {
+	slog.Handler
+	redact bool
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.redact && emailPattern.MatchString(record.Message) {
+		record.Message = emailPattern.ReplaceAllString(record.Message, Redacted)
+	}
+	if ctx != nil {
+		if requestID := reqctx.RequestID(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID), slog.String("actor", reqctx.Actor(ctx)))
+		}
+		// trace_id/span_id ligam a linha de log ao trace da requisição no backend de tracing.
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), redact: h.redact}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), redact: h.redact}
+}
@@ -0,0 +1,88 @@
+// logging/logging_test.go
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"college-app-v1/reqctx"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want slog.Value
+	}{
+		{name: "nome", attr: slog.String("name", "Ana Souza"), want: slog.StringValue(Redacted)},
+		{name: "email", attr: slog.String("email", "ana@exemplo.com"), want: slog.StringValue(Redacted)},
+		{name: "filtro de busca", attr: slog.String("name_filter", "ana"), want: slog.StringValue(Redacted)},
+		{name: "chave sensível com outro tipo", attr: slog.Int("teacher_name", 1), want: slog.StringValue(Redacted)},
+		{name: "email dentro de texto", attr: slog.String("detail", "conflito com ana.souza+x@exemplo.com.br no cadastro"),
+			want: slog.StringValue("conflito com " + Redacted + " no cadastro")},
+		{name: "email dentro de erro", attr: slog.Any("error", fmt.Errorf("duplicate key: (email)=(bia@exemplo.com): %w", errors.New("unique"))),
+			want: slog.StringValue("duplicate key: (email)=(" + Redacted + "): unique")},
+		{name: "texto sem dados pessoais", attr: slog.String("enrollment", "2025M0001"), want: slog.StringValue("2025M0001")},
+		{name: "erro sem email", attr: slog.Any("error", errors.New("conexão recusada")), want: slog.StringValue("conexão recusada")},
+		{name: "outros tipos", attr: slog.Int("count", 3), want: slog.IntValue(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redact(nil, tt.attr)
+			if got.Key != tt.attr.Key {
+				t.Errorf("chave = %q, esperava %q", got.Key, tt.attr.Key)
+			}
+			if !got.Value.Equal(tt.want) {
+				t.Errorf("valor = %v, esperava %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	requestCtx := reqctx.WithActor(reqctx.WithRequestID(context.Background(), "req-1"), "admin")
+	tests := []struct {
+		name   string
+		redact bool
+		ctx    context.Context
+		msg    string
+		args   []any
+		want   map[string]string // Atributos esperados na linha (além de time e level)
+	}{
+		{name: "mascarado", redact: true, ctx: context.Background(), msg: "aluno criado", args: []any{"name", "Ana", "id", "a1"},
+			want: map[string]string{"msg": "aluno criado", "name": Redacted, "id": "a1"}},
+		{name: "email na mensagem", redact: true, ctx: context.Background(), msg: "falha ao notificar ana@exemplo.com",
+			want: map[string]string{"msg": "falha ao notificar " + Redacted}},
+		{name: "sem máscara", redact: false, ctx: context.Background(), msg: "aluno criado", args: []any{"email", "ana@exemplo.com"},
+			want: map[string]string{"msg": "aluno criado", "email": "ana@exemplo.com"}},
+		{name: "contexto da requisição", redact: true, ctx: requestCtx, msg: "professor criado", args: []any{"id", "p1"},
+			want: map[string]string{"msg": "professor criado", "id": "p1", "request_id": "req-1", "actor": "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, Options{Level: slog.LevelInfo, JSON: true, Redact: tt.redact})
+			logger.InfoContext(tt.ctx, tt.msg, tt.args...)
+
+			var line map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("linha inválida %q: %v", buf.String(), err)
+			}
+			got := map[string]string{}
+			for key, value := range line {
+				if key != "time" && key != "level" {
+					got[key] = fmt.Sprint(value)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("atributos = %v, esperava %v", got, tt.want)
+			}
+		})
+	}
+}
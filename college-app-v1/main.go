@@ -1,15 +1,29 @@
 package main // Mudar para 'main' para ser um executável
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os" // Adicionar para obter a porta do ambiente
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	// Corrigir os caminhos dos imports para o nome exato do seu módulo
 	"college-app-v1/config"
 	"college-app-v1/handlers"
+	"college-app-v1/logging"
+	"college-app-v1/metrics"
+	"college-app-v1/middleware"
 	"college-app-v1/repositories"
+	"college-app-v1/reqctx"
 	"college-app-v1/services"
+	"college-app-v1/tracing"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -18,44 +32,144 @@ import (
 // O roteador Mux precisa ser uma variável global ou ser inicializado uma vez
 // para que não seja re-inicializado em cada invocação da função serverless.
 var router *mux.Router
-var initOnce bool = false // Flag para garantir que a inicialização ocorra apenas uma vez
+var apiHandler http.Handler             // Roteador envolvido pelos middlewares globais (rate limiting, etc.)
+var purgeService *services.PurgeService // Usado também pela limpeza periódica no servidor local
+var purgeInterval time.Duration
+var healthService *services.HealthService       // Marcado como "em desligamento" pelo servidor local
+var shutdownTracing func(context.Context) error // Envia os spans pendentes no desligamento
+var logger *slog.Logger                         // Logger estruturado compartilhado por repositórios, serviços e handlers
+
+// initMu serializa a inicialização: com várias requisições simultâneas no cold start, as demais
+// aguardam a primeira terminar. initDone só é marcado após uma inicialização bem-sucedida; após
+// uma falha (ex: banco indisponível), a próxima requisição tenta de novo.
+var initMu sync.Mutex
+var initDone atomic.Bool
 
 // Handler é a função de entrada para a Vercel Function.
 func Handler(w http.ResponseWriter, r *http.Request) {
-	// Inicializa o roteador e as dependências APENAS UMA VEZ
-	if !initOnce {
-		initAPI()
-		initOnce = true
+	// Inicializa o roteador e as dependências até conseguir uma vez
+	if err := ensureInit(); err != nil {
+		http.Error(w, `{"message": "Serviço indisponível: falha na inicialização da API."}`, http.StatusServiceUnavailable)
+		return
 	}
-	// Servir a requisição usando o roteador inicializado
-	router.ServeHTTP(w, r)
+	// Servir a requisição usando o roteador inicializado (com middlewares)
+	apiHandler.ServeHTTP(w, r)
 }
 
-// initAPI inicializa todas as dependências da aplicação
-func initAPI() {
+// ensureInit inicializa a API se ainda não foi inicializada com sucesso.
+func ensureInit() error {
+	if initDone.Load() {
+		return nil
+	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initDone.Load() { // Inicializada por outra requisição enquanto esta aguardava
+		return nil
+	}
+
+	cfg, err := config.Load("") // Na Vercel, o arquivo opcional vem de CONFIG_FILE
+	if err == nil {
+		err = initAPI(cfg)
+	}
+	if err != nil {
+		slog.Error("falha ao inicializar a API", "error", err)
+		return err
+	}
+	initDone.Store(true)
+	return nil
+}
+
+// loadConfig carrega e valida a configuração (ambiente e arquivo opcional em path ou CONFIG_FILE),
+// encerrando o processo com a lista de problemas se ela for inválida.
+func loadConfig(path string) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return cfg
+}
+
+// initAPI inicializa todas as dependências da aplicação. Após um sucesso não deve ser chamada de
+// novo; após uma falha, libera o tracing e o pool do banco já configurados, para que uma nova
+// tentativa comece do zero.
+func initAPI(cfg *config.Config) (err error) {
+	loggingCfg := cfg.Logging
+	logger = logging.New(os.Stdout, logging.Options{Level: loggingCfg.Level, JSON: loggingCfg.Format == "json", Redact: loggingCfg.Redact})
+	slog.SetDefault(logger)
+
+	tracingCfg := cfg.Tracing
+	// Os spans são enviados em lote em segundo plano; shutdownTracing envia os pendentes ao encerrar.
+	shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{Exporter: tracingCfg.Exporter, File: tracingCfg.File, ServiceName: tracingCfg.ServiceName, SampleRatio: tracingCfg.SampleRatio})
+	if err != nil {
+		return fmt.Errorf("erro ao configurar tracing: %w", err)
+	}
+	logger.Info("tracing configurado", "exporter", tracingCfg.Exporter, "sample_ratio", tracingCfg.SampleRatio)
+	defer func() {
+		if err != nil {
+			shutdownTracing(context.Background())
+		}
+	}()
+
 	// A DATABASE_URL será definida via variável de ambiente da Vercel.
 	// NOTA: Certifique-se de que config.InitDB() lida com a conexão ao banco de dados.
-	config.InitDB()
+	// Com DB_STARTUP_MODE=background, a conexão e a criação das tabelas continuam em segundo
+	// plano e /readyz responde 503 até o banco estar pronto.
+	dbCfg := cfg.Database
+	if err := config.InitDB(dbCfg); err != nil {
+		config.CloseDB() // No modo wait, o pool foi aberto mas o banco não respondeu
+		return err
+	}
 	// NOTE: defer config.CloseDB() não é usado em Serverless Functions
 	// A conexão é mantida viva pela plataforma entre invocações.
 
-	log.Println("Backend da universidade inicializando para Vercel Function...")
+	logger.Info("backend da universidade inicializando")
+
+	metrics.RegisterDBStats(config.DB)
 
 	// --- Inicializando Repositórios e Serviços ---
 	// Certifique-se de que estas funções existem nos seus respectivos pacotes
 	// e que elas aceitam as dependências corretas (ex: conexão DB)
-	subjectRepo := repositories.NewSubjectRepository(config.DB) // Exemplo: passando a conexão do DB
-	studentRepo := repositories.NewStudentRepository(config.DB)
-	teacherRepo := repositories.NewTeacherRepository(config.DB)
+	subjectRepo := repositories.NewSubjectRepository(config.DB, logger) // Exemplo: passando a conexão do DB
+	studentRepo := repositories.NewStudentRepository(config.DB, logger)
+	teacherRepo := repositories.NewTeacherRepository(config.DB, logger)
+	programRepo := repositories.NewProgramRepository(config.DB, logger)
+	departmentRepo := repositories.NewDepartmentRepository(config.DB, logger)
+	auditRepo := repositories.NewAuditRepository(config.DB, logger)
+	healthRepo := repositories.NewHealthRepository(config.DB)
+	transactor := repositories.NewTransactor(config.DB)
+
+	auditService := services.NewAuditService(auditRepo, logger)
+	healthService = services.NewHealthService(healthRepo, config.SchemaVersion, dbCfg.PingTimeout)
+	subjectService := services.NewSubjectService(transactor, subjectRepo, auditService)
+	enrollmentPolicy := services.EnrollmentPolicy{SequenceDigits: cfg.Enrollment.SequenceDigits, Shifts: cfg.Enrollment.Shifts}
+	studentService := services.NewStudentService(transactor, studentRepo, subjectRepo, programRepo, enrollmentPolicy, auditService, logger)
+	workloadCfg := cfg.Workload
+	workloadPolicy := services.WorkloadPolicy{HoursPerCredit: workloadCfg.HoursPerCredit, MaxWeeklyHours: workloadCfg.MaxWeeklyHours, Reject: workloadCfg.Policy == "reject"}
+	teacherService := services.NewTeacherService(transactor, teacherRepo, subjectRepo, departmentRepo, workloadPolicy, auditService, logger)
+	importService := services.NewImportService(transactor, studentRepo, teacherRepo, subjectRepo, departmentRepo, enrollmentPolicy, auditService, logger)
+	curriculumService := services.NewCurriculumService(transactor, studentRepo, subjectRepo, programRepo, enrollmentPolicy, auditService, logger)
+	programService := services.NewProgramService(transactor, programRepo, subjectRepo, auditService)
+	departmentService := services.NewDepartmentService(transactor, departmentRepo, teacherRepo, subjectRepo, auditService)
+	associationService := services.NewAssociationService(transactor, studentRepo, teacherRepo, subjectRepo, workloadPolicy, auditService)
+	degreeAuditService := services.NewDegreeAuditService(studentRepo, subjectRepo, programRepo, cfg.Degree.RequiredCredits)
 
-	subjectService := services.NewSubjectService(subjectRepo)
-	studentService := services.NewStudentService(studentRepo, subjectRepo)
-	teacherService := services.NewTeacherService(teacherRepo, subjectRepo)
+	purgeService = services.NewPurgeService(transactor, studentRepo, teacherRepo, subjectRepo, auditService, cfg.Retention.SoftDeleteRetention(), logger)
+	purgeInterval = cfg.Retention.PurgeInterval
 
 	// --- Inicializando Handlers ---
-	subjectHandler := handlers.NewSubjectHandler(subjectService)
-	studentHandler := handlers.NewStudentHandler(studentService)
-	teacherHandler := handlers.NewTeacherHandler(teacherService)
+	subjectHandler := handlers.NewSubjectHandler(subjectService, logger)
+	studentHandler := handlers.NewStudentHandler(studentService, curriculumService, logger)
+	teacherHandler := handlers.NewTeacherHandler(teacherService, logger)
+	importHandler := handlers.NewImportHandler(importService, logger)
+	associationHandler := handlers.NewAssociationHandler(associationService, logger)
+	curriculumHandler := handlers.NewCurriculumHandler(curriculumService, logger)
+	programHandler := handlers.NewProgramHandler(programService, logger)
+	departmentHandler := handlers.NewDepartmentHandler(departmentService, logger)
+	reportHandler := handlers.NewReportHandler(teacherService, logger)
+	degreeAuditHandler := handlers.NewDegreeAuditHandler(degreeAuditService, logger)
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	adminHandler := handlers.NewAdminHandler(purgeService, logger)
+	healthHandler := handlers.NewHealthHandler(healthService, logger)
 
 	// --- Configurando o Roteador Mux ---
 	router = mux.NewRouter()
@@ -65,56 +179,283 @@ func initAPI() {
 	router.HandleFunc("/subjects", subjectHandler.GetAllSubjectsHandler).Methods("GET")
 	router.HandleFunc("/subjects/{id}", subjectHandler.GetSubjectByIDHandler).Methods("GET")
 	router.HandleFunc("/subjects/{id}", subjectHandler.UpdateSubjectHandler).Methods("PUT")
+	router.HandleFunc("/subjects/{id}", subjectHandler.PatchSubjectHandler).Methods("PATCH")
 	router.HandleFunc("/subjects/{id}", subjectHandler.DeleteSubjectHandler).Methods("DELETE")
+	router.HandleFunc("/subjects/{id}/restore", middleware.RequireAdmin(subjectHandler.RestoreSubjectHandler)).Methods("POST")
 
 	// Rotas para Alunos
 	router.HandleFunc("/students", studentHandler.CreateStudentHandler).Methods("POST")
 	router.HandleFunc("/students", studentHandler.GetAllStudentsHandler).Methods("GET")
+	router.HandleFunc("/students/enroll-curriculum", middleware.RequireAdmin(curriculumHandler.EnrollBatchHandler)).Methods("POST")
 	router.HandleFunc("/students/{id}", studentHandler.GetStudentByIDHandler).Methods("GET")
 	router.HandleFunc("/students/{id}", studentHandler.UpdateStudentHandler).Methods("PUT")
+	router.HandleFunc("/students/{id}", studentHandler.PatchStudentHandler).Methods("PATCH")
 	router.HandleFunc("/students/{id}", studentHandler.DeleteStudentHandler).Methods("DELETE")
+	router.HandleFunc("/students/{id}/restore", middleware.RequireAdmin(studentHandler.RestoreStudentHandler)).Methods("POST")
+	router.HandleFunc("/students/{id}/enroll-curriculum", middleware.RequireAdmin(curriculumHandler.EnrollStudentHandler)).Methods("POST")
+	router.HandleFunc("/students/{id}/degree-audit", degreeAuditHandler.StudentDegreeAuditHandler).Methods("GET")
 
 	// Rotas para associação Aluno-Matéria
 	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", studentHandler.AddSubjectToStudentHandler).Methods("POST")
 	router.HandleFunc("/students/{studentID}/subjects/{subjectID}", studentHandler.RemoveSubjectFromStudentHandler).Methods("DELETE")
+	router.HandleFunc("/students/{studentID}/subjects/{subjectID}/status", studentHandler.SetSubjectStatusHandler).Methods("PUT")
+	router.HandleFunc("/students/{id}/subjects", associationHandler.ReplaceStudentSubjectsHandler).Methods("PUT")
+	router.HandleFunc("/subjects/{id}/students", associationHandler.EnrollStudentsHandler).Methods("POST")
 
 	// --- ROTAS PARA PROFESSORES ---
 	router.HandleFunc("/teachers", teacherHandler.CreateTeacherHandler).Methods("POST")
 	router.HandleFunc("/teachers", teacherHandler.GetAllTeachersHandler).Methods("GET")
 	router.HandleFunc("/teachers/{id}", teacherHandler.GetTeacherByIDHandler).Methods("GET")
 	router.HandleFunc("/teachers/{id}", teacherHandler.UpdateTeacherHandler).Methods("PUT")
+	router.HandleFunc("/teachers/{id}", teacherHandler.PatchTeacherHandler).Methods("PATCH")
 	router.HandleFunc("/teachers/{id}", teacherHandler.DeleteTeacherHandler).Methods("DELETE")
+	router.HandleFunc("/teachers/{id}/restore", middleware.RequireAdmin(teacherHandler.RestoreTeacherHandler)).Methods("POST")
 
 	// Rotas para associação Professor-Matéria
 	router.HandleFunc("/teachers/{teacherID}/subjects/{subjectID}", teacherHandler.AddSubjectToTeacherHandler).Methods("POST")
 	router.HandleFunc("/teachers/{teacherID}/subjects/{subjectID}", teacherHandler.RemoveSubjectFromTeacherHandler).Methods("DELETE")
+	router.HandleFunc("/teachers/{id}/subjects", associationHandler.ReplaceTeacherSubjectsHandler).Methods("PUT")
+	router.HandleFunc("/subjects/{id}/teachers", associationHandler.AssignTeachersHandler).Methods("POST")
 
-	// --- Configuração do CORS ---
-	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Em produção, ajuste para domínios específicos
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"}, // Adicione outros headers se necessário
-		AllowCredentials: true,
-		Debug:            false, // Defina como false em produção
-	})
+	// --- ROTAS DE CURSOS E GRADES CURRICULARES ---
+	router.HandleFunc("/programs", middleware.RequireAdmin(programHandler.CreateProgramHandler)).Methods("POST")
+	router.HandleFunc("/programs", programHandler.GetAllProgramsHandler).Methods("GET")
+	router.HandleFunc("/programs/{id}", programHandler.GetProgramByIDHandler).Methods("GET")
+	router.HandleFunc("/programs/{id}", middleware.RequireAdmin(programHandler.UpdateProgramHandler)).Methods("PUT")
+	router.HandleFunc("/programs/{id}/curricula", middleware.RequireAdmin(programHandler.CreateCurriculumHandler)).Methods("POST")
+	router.HandleFunc("/programs/{id}/curricula", programHandler.GetCurriculaHandler).Methods("GET")
+	router.HandleFunc("/curricula/{id}", programHandler.GetCurriculumByIDHandler).Methods("GET")
 
-	// Aplica o middleware CORS ao seu roteador
-	router.Use(corsHandler.Handler) // Use diretamente corsHandler.Handler
+	// --- ROTAS DE DEPARTAMENTOS ---
+	router.HandleFunc("/departments", middleware.RequireAdmin(departmentHandler.CreateDepartmentHandler)).Methods("POST")
+	router.HandleFunc("/departments", departmentHandler.GetAllDepartmentsHandler).Methods("GET")
+	router.HandleFunc("/departments/{id}", departmentHandler.GetDepartmentByIDHandler).Methods("GET")
+	router.HandleFunc("/departments/{id}", middleware.RequireAdmin(departmentHandler.UpdateDepartmentHandler)).Methods("PUT")
+	router.HandleFunc("/departments/{id}/teachers", departmentHandler.GetDepartmentTeachersHandler).Methods("GET")
+	router.HandleFunc("/departments/{id}/subjects", departmentHandler.GetDepartmentSubjectsHandler).Methods("GET")
 
-	log.Println("Backend da universidade inicializado com sucesso para Vercel Function!")
+	// --- ROTAS DE RELATÓRIOS ---
+	router.HandleFunc("/reports/teacher-workload", reportHandler.TeacherWorkloadReportHandler).Methods("GET")
+	router.HandleFunc("/reports/graduation-candidates", degreeAuditHandler.GraduationCandidatesHandler).Methods("GET")
+
+	// --- ROTAS DE IMPORTAÇÃO EM LOTE (CSV) ---
+	if cfg.Features.Imports {
+		router.HandleFunc("/imports/students", importHandler.ImportStudentsHandler).Methods("POST")
+		router.HandleFunc("/imports/teachers", importHandler.ImportTeachersHandler).Methods("POST")
+		router.HandleFunc("/imports/subjects", importHandler.ImportSubjectsHandler).Methods("POST")
+	}
+
+	// --- ROTAS ADMINISTRATIVAS ---
+	router.HandleFunc("/audit", middleware.RequireAdmin(auditHandler.GetAuditEventsHandler)).Methods("GET")
+	router.HandleFunc("/admin/purge-deleted", middleware.RequireAdmin(adminHandler.PurgeDeletedHandler)).Methods("POST")
+
+	// --- SONDAS DE VIDA E PRONTIDÃO ---
+	router.HandleFunc("/healthz", healthHandler.LivenessHandler).Methods("GET")
+	router.HandleFunc("/readyz", healthHandler.ReadinessHandler).Methods("GET")
+
+	// --- MÉTRICAS (Prometheus) ---
+	// Restritas a administradores: o scraper envia "Authorization: Bearer <ADMIN_API_TOKEN>"
+	if cfg.Features.Metrics {
+		router.HandleFunc("/metrics", middleware.RequireAdmin(metrics.Handler().ServeHTTP)).Methods("GET")
+	}
+
+	// --- Middlewares globais ---
+	// Envolvem o roteador inteiro (e não via router.Use), para que também rotas inexistentes
+	// e requisições de preflight sem rota correspondente passem por eles.
+	// Ordem (de fora para dentro): CORS -> ID da requisição -> tracing -> identidade -> log de acesso
+	// -> métricas -> headers de segurança -> rate limiting -> idempotência -> roteador.
+	apiHandler = router
+	if idempotency := newIdempotency(cfg.Idempotency); idempotency != nil {
+		apiHandler = idempotency.Handler(apiHandler)
+	}
+	limiter, err := newRateLimiter(cfg.RateLimit)
+	if err != nil {
+		return err
+	}
+	if limiter != nil {
+		apiHandler = limiter.Handler(apiHandler)
+	}
+
+	securityCfg := cfg.Security
+	apiHandler = middleware.SecurityHeaders(securityCfg.HSTSMaxAge, securityCfg.ContentSecurityPolicy)(apiHandler)
+
+	// As métricas usam o modelo de rota do roteador (ex: /students/{id}) e contam também
+	// as respostas 429 do rate limiting.
+	apiHandler = middleware.Metrics(router)(apiHandler)
+
+	// O log de acesso fica dentro de RequestID/Identity para incluir o ID da requisição e o autor.
+	apiHandler = middleware.AccessLog(logger)(apiHandler)
+
+	// Autor e permissão de administrador vão para o contexto, usados pela auditoria e pelos logs.
+	apiHandler = middleware.Identity(cfg.AdminAPIToken)(apiHandler)
+	// O span da requisição envolve todo o processamento e continua o trace do header traceparent.
+	apiHandler = middleware.Tracing(router)(apiHandler)
+	apiHandler = middleware.RequestID(apiHandler)
+
+	// CORS fica por fora para que até respostas 429 tragam os headers de CORS
+	// e possam ser lidas pelo frontend.
+	corsCfg := cfg.CORS
+	corsOptions := cors.Options{
+		AllowedOrigins:   corsCfg.AllowedOrigins,
+		AllowedMethods:   corsCfg.AllowedMethods,
+		AllowedHeaders:   corsCfg.AllowedHeaders,
+		ExposedHeaders:   corsCfg.ExposedHeaders,
+		AllowCredentials: corsCfg.AllowCredentials,
+		MaxAge:           corsCfg.MaxAge,
+	}
+	if len(corsCfg.AllowedOrigins) == 0 {
+		// Sem origens configuradas o rs/cors liberaria todas; aqui significa "nenhuma origem externa".
+		corsOptions.AllowOriginFunc = func(string) bool { return false }
+	}
+	apiHandler = cors.New(corsOptions).Handler(apiHandler)
+	logger.Info("CORS configurado", "env", cfg.Env, "origins", corsCfg.AllowedOrigins)
+
+	logger.Info("backend da universidade inicializado")
+	return nil
 }
 
 // Adicionando uma função main() para testar localmente (opcional)
 // Esta função NÃO será executada pela Vercel. A Vercel executará 'Handler'.
 func main() {
-	initAPI() // Inicializa a API
+	configPath := flag.String("config", "", "arquivo de configuração YAML (padrão: CONFIG_FILE)")
+	printConfig := flag.Bool("print-config", false, "exibe a configuração efetiva (segredos mascarados) e encerra")
+	flag.Parse()
+
+	cfg := loadConfig(*configPath)
+	if *printConfig {
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("Erro ao exibir a configuração: %v", err)
+		}
+		return
+	}
+
+	if err := initAPI(cfg); err != nil { // Inicializa a API
+		log.Fatalf("Erro ao inicializar a API: %v", err)
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // Porta padrão se não estiver definida
+	serverCfg := cfg.Server
+	server := &http.Server{
+		Addr:              ":" + serverCfg.Port,
+		Handler:           apiHandler,
+		ReadHeaderTimeout: serverCfg.ReadHeaderTimeout,
+		ReadTimeout:       serverCfg.ReadTimeout,
+		WriteTimeout:      serverCfg.WriteTimeout,
+		IdleTimeout:       serverCfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
 	}
-	addr := ":" + port
 
-	log.Printf("Servidor da College App iniciado localmente na porta %s", addr)
-	log.Fatal(http.ListenAndServe(addr, router))
+	// ctx é cancelado no primeiro SIGINT/SIGTERM; um segundo sinal encerra o processo imediatamente.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Na Vercel a limpeza é feita via cron chamando POST /admin/purge-deleted;
+	// localmente, um processo de longa duração pode executá-la periodicamente.
+	if purgeInterval > 0 {
+		go runPurgeLoop(ctx, purgeInterval)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("servidor da College App iniciado localmente", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Fatalf("Erro no servidor HTTP: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+	shutdown(server, serverCfg)
+}
+
+// shutdown encerra o servidor sem interromper requisições em andamento: tira a instância do
+// balanceamento (/readyz em 503), aguarda ShutdownDelay, para de aceitar conexões e espera as
+// requisições terminarem por até ShutdownTimeout; por fim envia os spans pendentes e fecha o pool do banco.
+func shutdown(server *http.Server, cfg config.ServerConfig) {
+	logger.Info("sinal de desligamento recebido", "delay", cfg.ShutdownDelay.String(), "timeout", cfg.ShutdownTimeout.String())
+	healthService.MarkShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("requisições em andamento não terminaram a tempo", "error", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("erro ao enviar spans pendentes", "error", err)
+	}
+	config.CloseDB()
+	logger.Info("servidor da College App encerrado")
+}
+
+// newRateLimiter monta o limitador de requisições a partir da configuração de rate limiting.
+// Retorna nil se RATE_LIMIT_ENABLED=false.
+func newRateLimiter(cfg config.RateLimitConfig) (*middleware.RateLimiter, error) {
+	if !cfg.Enabled {
+		logger.Info("rate limiting desabilitado (RATE_LIMIT_ENABLED=false)")
+		return nil, nil
+	}
+
+	defaultLimit, err := middleware.ParseLimit(cfg.Default)
+	if err != nil {
+		return nil, fmt.Errorf("configuração RATE_LIMIT_DEFAULT inválida: %w", err)
+	}
+	groups := make(map[string]middleware.Limit, len(cfg.Groups))
+	for group, raw := range cfg.Groups {
+		limit, err := middleware.ParseLimit(raw)
+		if err != nil {
+			return nil, fmt.Errorf("configuração RATE_LIMIT_GROUPS inválida para o grupo %s: %w", group, err)
+		}
+		groups[group] = limit
+	}
+
+	var store middleware.RateLimitStore
+	switch cfg.Store {
+	case "memory":
+		store = middleware.NewMemoryRateLimitStore()
+	case "postgres":
+		store = middleware.NewPostgresRateLimitStore(config.DB, logger)
+	}
+
+	logger.Info("rate limiting habilitado", "store", cfg.Store, "default", cfg.Default, "groups", cfg.Groups)
+	return middleware.NewRateLimiter(store, defaultLimit, groups, cfg.TrustProxy, logger), nil
+}
+
+// newIdempotency monta o middleware de Idempotency-Key a partir da configuração de idempotência.
+// Retorna nil se IDEMPOTENCY_ENABLED=false.
+func newIdempotency(cfg config.IdempotencyConfig) *middleware.Idempotency {
+	if !cfg.Enabled {
+		logger.Info("Idempotency-Key desabilitado (IDEMPOTENCY_ENABLED=false)")
+		return nil
+	}
+
+	var store middleware.IdempotencyStore
+	switch cfg.Store {
+	case "memory":
+		store = middleware.NewMemoryIdempotencyStore()
+	case "postgres":
+		store = middleware.NewPostgresIdempotencyStore(config.DB, logger)
+	}
+
+	logger.Info("Idempotency-Key habilitado", "store", cfg.Store, "ttl", cfg.TTL)
+	return middleware.NewIdempotency(store, cfg.TTL, logger)
+}
+
+// runPurgeLoop executa a limpeza de registros excluídos a cada interval.
+// O loop termina quando ctx é cancelado (desligamento do servidor).
+func runPurgeLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		purgeCtx := reqctx.WithActor(context.WithoutCancel(ctx), "system:purge")
+		if _, err := purgeService.PurgeDeleted(purgeCtx); err != nil {
+			logger.ErrorContext(purgeCtx, "erro na limpeza periódica de registros excluídos", "error", err)
+		}
+	}
 }
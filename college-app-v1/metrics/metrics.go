@@ -0,0 +1,94 @@
+// metrics/metrics.go
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "college"
+
+// Registry reúne as métricas da aplicação expostas em /metrics. Um registro próprio (em vez do
+// global do client) evita expor métricas de bibliotecas que não foram escolhidas aqui.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests conta as requisições por método, modelo de rota do mux (ex: /students/{id}) e status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total de requisições HTTP por método, rota e status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration mede a latência das requisições por método e modelo de rota.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por método e rota.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration mede a duração das consultas por repositório e comando SQL (select, insert...).
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duração das consultas ao banco por repositório e comando SQL.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "operation"})
+
+	// StudentsCreated conta os alunos cadastrados, por origem (api, curriculum ou import).
+	StudentsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "students_created_total",
+		Help:      "Total de alunos cadastrados por origem.",
+	}, []string{"source"})
+
+	// EnrollmentAllocationRetries conta as novas tentativas de gerar matrícula após conflito
+	// com um cadastro concorrente do mesmo ano e turno.
+	EnrollmentAllocationRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrollment_allocation_retries_total",
+		Help:      "Total de novas tentativas de geração de matrícula após conflito.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		StudentsCreated,
+		EnrollmentAllocationRetries,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// dbStats é o coletor registrado por RegisterDBStats.
+var dbStats prometheus.Collector
+
+// RegisterDBStats expõe as estatísticas do pool de conexões (config.DB.Stats()) como
+// go_sql_* com db_name="college" (conexões abertas, em uso, ociosas, esperas etc.). Chamada de
+// novo (nova tentativa de inicialização), substitui o coletor do pool anterior.
+func RegisterDBStats(db *sql.DB) {
+	if dbStats != nil {
+		Registry.Unregister(dbStats)
+	}
+	dbStats = collectors.NewDBStatsCollector(db, namespace)
+	Registry.MustRegister(dbStats)
+}
+
+// ObserveQuery registra a duração de uma consulta iniciada em start.
+func ObserveQuery(repository, operation string, start time.Time) {
+	DBQueryDuration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+}
+
+// Handler serve as métricas no formato de exposição do Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,319 @@
+// middleware/idempotency.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"college-app-v1/reqctx"
+)
+
+// IdempotencyLockTimeout é por quanto tempo uma chave fica reservada sem notícias da requisição
+// original. Enquanto ela está em andamento a reserva é prorrogada a cada idempotencyHeartbeat;
+// passado esse prazo sem prorrogação (ex: a instância caiu no meio da requisição), uma nova
+// tentativa com a mesma chave volta a ser executada.
+const IdempotencyLockTimeout = time.Minute
+
+// idempotencyHeartbeat é o intervalo entre as prorrogações da reserva de uma requisição em andamento.
+var idempotencyHeartbeat = IdempotencyLockTimeout / 3
+
+// maxIdempotentBodyBytes limita o corpo lido (e mantido em memória) para calcular a impressão
+// digital da requisição. Os corpos JSON da API são pequenos.
+const maxIdempotentBodyBytes = 1 << 20
+
+// maxIdempotentUploadBytes é o limite do corpo nas rotas de idempotencyUploadPrefixes. Acima de
+// maxIdempotentBodyBytes, o corpo é copiado para um arquivo temporário enquanto a impressão
+// digital é calculada, em vez de ficar em memória. Apenas a resposta vai para o store.
+const maxIdempotentUploadBytes = 10 << 20
+
+// idempotencyUploadPrefixes são as rotas POST que recebem arquivos (as importações, de até 10 MB).
+var idempotencyUploadPrefixes = []string{"/imports/"}
+
+// validIdempotencyKey aceita chaves como UUIDs ou outros identificadores gerados pelo cliente.
+var validIdempotencyKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// idempotencyReplayHeaders são os headers da resposta original guardados e repetidos no replay.
+// Os demais (rate limit, ID da requisição, CORS) pertencem à nova requisição e são
+// definidos pelos outros middlewares.
+var idempotencyReplayHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyRecord é o estado guardado para uma chave de idempotência.
+// Enquanto a requisição original não termina, Completed é false.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore guarda as chaves de idempotência. Implementações devem ser seguras para uso concorrente.
+type IdempotencyStore interface {
+	// Begin reserva key para uma nova execução até lockedUntil. Se a chave já existe e não expirou,
+	// nada é reservado e o registro existente é retornado; nil significa que a reserva foi feita.
+	Begin(ctx context.Context, key, fingerprint string, now, lockedUntil time.Time) (*IdempotencyRecord, error)
+	// Complete guarda a resposta da execução reservada, válida até expiresAt.
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, expiresAt time.Time) error
+	// Release desfaz a reserva ainda não concluída feita com fingerprint, permitindo que o cliente
+	// tente novamente. A reserva de outra requisição (após a expiração desta) é mantida.
+	Release(ctx context.Context, key, fingerprint string) error
+	// Extend prorroga até lockedUntil a reserva ainda não concluída de key feita com fingerprint.
+	Extend(ctx context.Context, key, fingerprint string, lockedUntil time.Time) error
+}
+
+// Idempotency é o middleware que honra o header Idempotency-Key em requisições POST:
+// a primeira execução tem a resposta guardada por ttl, e novas tentativas com a mesma chave
+// e o mesmo corpo recebem a resposta original sem executar o handler de novo.
+type Idempotency struct {
+	store  IdempotencyStore
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// NewIdempotency cria o middleware de idempotência.
+func NewIdempotency(store IdempotencyStore, ttl time.Duration, logger *slog.Logger) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, logger: logger}
+}
+
+// Handler envolve next aplicando a idempotência. Requisições sem o header seguem normalmente.
+func (m *Idempotency) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get("Idempotency-Key")
+		if r.Method != http.MethodPost || idempotencyKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey.MatchString(idempotencyKey) {
+			w.Header().Set("Content-Type", "application/json")
+			http.Error(w, `{"message": "Idempotency-Key inválida: use até 255 caracteres entre letras, números, '.', '_', ':' e '-'."}`, http.StatusBadRequest)
+			return
+		}
+
+		limit := int64(maxIdempotentBodyBytes)
+		if idempotencyUpload(r.URL.Path) {
+			limit = maxIdempotentUploadBytes
+		}
+		body, fingerprint, err := bufferRequestBody(r, http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, fmt.Sprintf(`{"message": "Corpo grande demais para uma requisição com Idempotency-Key (máximo de %d MB)."}`, limit>>20), http.StatusRequestEntityTooLarge)
+				return
+			}
+			m.logger.ErrorContext(r.Context(), "erro ao ler corpo da requisição com Idempotency-Key", "error", err)
+			http.Error(w, `{"message": "Requisição inválida: não foi possível ler o corpo."}`, http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+		r.Body = body
+
+		ctx := r.Context()
+		key := idempotencyStoreKey(reqctx.Actor(ctx), idempotencyKey)
+		now := time.Now()
+
+		existing, err := m.store.Begin(ctx, key, fingerprint, now, now.Add(IdempotencyLockTimeout))
+		if err != nil {
+			// Falha no store não deve derrubar a API: registra e executa sem garantia de idempotência.
+			m.logger.ErrorContext(ctx, "erro ao reservar chave de idempotência", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if existing != nil {
+			m.replay(w, existing, fingerprint)
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// O contexto da requisição pode já ter sido cancelado (cliente desconectou),
+			// mas a reserva precisa ser liberada mesmo assim.
+			if err := m.store.Release(context.WithoutCancel(ctx), key, fingerprint); err != nil {
+				m.logger.ErrorContext(ctx, "erro ao liberar chave de idempotência", "error", err)
+			}
+		}()
+
+		stopHeartbeat := m.keepLocked(ctx, key, fingerprint)
+		next.ServeHTTP(recorder, r)
+		stopHeartbeat()
+
+		// Erros do servidor não são guardados: a nova tentativa deve executar de novo.
+		if recorder.status >= http.StatusInternalServerError {
+			return
+		}
+		record := &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  recorder.status,
+			Header:      http.Header{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range idempotencyReplayHeaders {
+			if value := w.Header().Get(name); value != "" {
+				record.Header.Set(name, value)
+			}
+		}
+		if err := m.store.Complete(context.WithoutCancel(ctx), key, record, time.Now().Add(m.ttl)); err != nil {
+			m.logger.ErrorContext(ctx, "erro ao guardar resposta da chave de idempotência", "error", err)
+			return
+		}
+		completed = true
+	})
+}
+
+// keepLocked prorroga a reserva de key a cada idempotencyHeartbeat até a função retornada ser
+// chamada, para que uma requisição demorada não perca a reserva no meio da execução.
+func (m *Idempotency) keepLocked(ctx context.Context, key, fingerprint string) (stop func()) {
+	ctx = context.WithoutCancel(ctx)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(idempotencyHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if err := m.store.Extend(ctx, key, fingerprint, now.Add(IdempotencyLockTimeout)); err != nil {
+					m.logger.ErrorContext(ctx, "erro ao prorrogar chave de idempotência", "error", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished // Nenhuma prorrogação depois de Complete/Release
+	}
+}
+
+// idempotencyUpload informa se path pertence a uma rota de upload (idempotencyUploadPrefixes).
+func idempotencyUpload(path string) bool {
+	for _, prefix := range idempotencyUploadPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// replay responde uma nova tentativa a partir do registro existente.
+func (m *Idempotency) replay(w http.ResponseWriter, existing *IdempotencyRecord, fingerprint string) {
+	if existing.Fingerprint != fingerprint {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, `{"message": "Idempotency-Key já utilizada com uma requisição diferente."}`, http.StatusUnprocessableEntity)
+		return
+	}
+	if !existing.Completed {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, `{"message": "Uma requisição com esta Idempotency-Key ainda está em andamento."}`, http.StatusConflict)
+		return
+	}
+
+	for name, values := range existing.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(existing.StatusCode)
+	w.Write(existing.Body)
+}
+
+// idempotencyStoreKey separa as chaves por autor, para que clientes diferentes
+// não colidam nem leiam as respostas uns dos outros.
+func idempotencyStoreKey(actor, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(actor + "\x00" + idempotencyKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// bufferRequestBody lê body inteiro, calculando a impressão digital da requisição (método,
+// rota, query e corpo), e retorna uma cópia do corpo para o handler. Até maxIdempotentBodyBytes
+// a cópia fica em memória; o restante é copiado para um arquivo temporário, removido ao fechar.
+func bufferRequestBody(r *http.Request, body io.Reader) (io.ReadCloser, string, error) {
+	h := sha256.New()
+	io.WriteString(h, r.Method+"\n"+r.URL.Path+"\n"+r.URL.RawQuery+"\n")
+	tee := io.TeeReader(body, h)
+
+	var head bytes.Buffer
+	n, err := io.CopyN(&head, tee, maxIdempotentBodyBytes+1)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+	if n <= maxIdempotentBodyBytes {
+		return io.NopCloser(&head), hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	spool, err := os.CreateTemp("", "idempotency-body-*")
+	if err != nil {
+		return nil, "", err
+	}
+	file := &tempFileBody{File: spool}
+	if _, err := head.WriteTo(spool); err != nil {
+		file.Close()
+		return nil, "", err
+	}
+	if _, err := io.Copy(spool, tee); err != nil {
+		file.Close()
+		return nil, "", err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, "", err
+	}
+	return file, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// tempFileBody é o corpo copiado para um arquivo temporário; Close remove o arquivo.
+type tempFileBody struct {
+	*os.File
+}
+
+func (f *tempFileBody) Close() error {
+	f.File.Close()
+	return os.Remove(f.Name())
+}
+
+// idempotencyRecorder repassa a resposta ao cliente e guarda uma cópia para o store.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (w *idempotencyRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap permite que http.ResponseController acesse o ResponseWriter original.
+func (w *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,222 @@
+// middleware/idempotency_store.go
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MemoryIdempotencyStore mantém as chaves em memória. Adequado para uma única instância;
+// em implantações com várias instâncias uma nova tentativa pode chegar a outra instância.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore cria um store em memória vazio.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]*memoryIdempotencyEntry)}
+}
+
+// Begin reserva key, a menos que já exista um registro não expirado.
+func (s *MemoryIdempotencyStore) Begin(_ context.Context, key, fingerprint string, now, lockedUntil time.Time) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, nil
+	}
+	s.entries[key] = &memoryIdempotencyEntry{
+		record:    IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: lockedUntil,
+	}
+	return nil, nil
+}
+
+// Complete guarda a resposta da execução reservada.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryIdempotencyEntry{record: *record, expiresAt: expiresAt}
+	return nil
+}
+
+// Release remove a reserva se ela ainda não foi concluída e pertence à requisição (fingerprint).
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.record.Completed && entry.record.Fingerprint == fingerprint {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Extend prorroga a reserva se ela ainda não foi concluída.
+func (s *MemoryIdempotencyStore) Extend(_ context.Context, key, fingerprint string, lockedUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.record.Completed && entry.record.Fingerprint == fingerprint {
+		entry.expiresAt = lockedUntil
+	}
+	return nil
+}
+
+// sweep remove registros expirados, evitando crescimento ilimitado do mapa.
+// Executa no máximo uma vez por minuto.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// PostgresIdempotencyStore guarda as chaves na tabela idempotency_keys, permitindo
+// que uma nova tentativa seja reconhecida por qualquer instância (ex: Vercel Functions).
+type PostgresIdempotencyStore struct {
+	db     *sql.DB
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewPostgresIdempotencyStore cria um store baseado no PostgreSQL.
+func NewPostgresIdempotencyStore(db *sql.DB, logger *slog.Logger) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{db: db, logger: logger}
+}
+
+// Begin reserva key com um único INSERT ... ON CONFLICT: a linha só é (re)escrita se não
+// existir ou estiver expirada, de modo que apenas uma instância concorrente consegue a reserva.
+func (s *PostgresIdempotencyStore) Begin(ctx context.Context, key, fingerprint string, now, lockedUntil time.Time) (*IdempotencyRecord, error) {
+	s.cleanup(ctx, now)
+
+	var reserved string
+	err := s.db.QueryRowContext(ctx, `
+	INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (key) DO UPDATE SET
+		fingerprint = EXCLUDED.fingerprint,
+		status_code = NULL,
+		response_headers = NULL,
+		response_body = NULL,
+		created_at = EXCLUDED.created_at,
+		expires_at = EXCLUDED.expires_at
+	WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	RETURNING key`, key, fingerprint, now, lockedUntil).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("falha ao reservar chave de idempotência: %w", err)
+	}
+
+	// A chave já existe e ainda é válida: devolve o registro atual.
+	record := &IdempotencyRecord{}
+	var statusCode sql.NullInt64
+	var headers, body []byte
+	err = s.db.QueryRowContext(ctx,
+		`SELECT fingerprint, status_code, response_headers, response_body FROM idempotency_keys WHERE key = $1`,
+		key).Scan(&record.Fingerprint, &statusCode, &headers, &body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler chave de idempotência: %w", err)
+	}
+	if statusCode.Valid {
+		record.Completed = true
+		record.StatusCode = int(statusCode.Int64)
+		record.Body = body
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &record.Header); err != nil {
+				return nil, fmt.Errorf("falha ao decodificar headers guardados: %w", err)
+			}
+		}
+	}
+	return record, nil
+}
+
+// Complete guarda a resposta da execução reservada.
+func (s *PostgresIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, expiresAt time.Time) error {
+	headers, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar headers da resposta: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+	UPDATE idempotency_keys
+	SET status_code = $1, response_headers = $2, response_body = $3, expires_at = $4
+	WHERE key = $5 AND fingerprint = $6`,
+		record.StatusCode, string(headers), record.Body, expiresAt, key, record.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("falha ao guardar resposta da chave de idempotência: %w", err)
+	}
+	return nil
+}
+
+// Release remove a reserva se ela ainda não foi concluída e pertence à requisição (fingerprint).
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, key, fingerprint string) error {
+	_, err := s.db.ExecContext(ctx, `
+	DELETE FROM idempotency_keys
+	WHERE key = $1 AND fingerprint = $2 AND status_code IS NULL`, key, fingerprint)
+	if err != nil {
+		return fmt.Errorf("falha ao liberar chave de idempotência: %w", err)
+	}
+	return nil
+}
+
+// Extend prorroga a reserva se ela ainda não foi concluída.
+func (s *PostgresIdempotencyStore) Extend(ctx context.Context, key, fingerprint string, lockedUntil time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+	UPDATE idempotency_keys SET expires_at = $1
+	WHERE key = $2 AND fingerprint = $3 AND status_code IS NULL`, lockedUntil, key, fingerprint)
+	if err != nil {
+		return fmt.Errorf("falha ao prorrogar chave de idempotência: %w", err)
+	}
+	return nil
+}
+
+// cleanup remove chaves expiradas no máximo a cada 10 minutos por instância.
+func (s *PostgresIdempotencyStore) cleanup(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < 10*time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	if removed, err := s.DeleteExpired(ctx, now); err != nil {
+		s.logger.ErrorContext(ctx, "erro ao remover chaves expiradas", "error", err)
+	} else if removed > 0 {
+		s.logger.InfoContext(ctx, "chaves expiradas removidas", "count", removed)
+	}
+}
+
+// DeleteExpired remove chaves de idempotência expiradas.
+func (s *PostgresIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao remover chaves de idempotência expiradas: %w", err)
+	}
+	return res.RowsAffected()
+}
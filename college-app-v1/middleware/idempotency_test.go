@@ -0,0 +1,362 @@
+// middleware/idempotency_test.go
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// idempotencyStep é uma requisição enviada ao middleware e a resposta esperada.
+type idempotencyStep struct {
+	path         string
+	key          string
+	body         string
+	wantStatus   int
+	wantBody     string
+	wantReplayed bool
+}
+
+func TestIdempotencyHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int // Status respondido pelo handler
+		steps     []idempotencyStep
+		wantCalls int32
+	}{
+		{
+			name:   "nova tentativa recebe a resposta original",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{"name":"Ana"}`, wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/students", key: "k1", body: `{"name":"Ana"}`, wantStatus: http.StatusCreated, wantBody: "resposta 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "mesma chave com outro corpo",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{"name":"Ana"}`, wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/students", key: "k1", body: `{"name":"Bia"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "mesma chave em outra rota",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/teachers", key: "k1", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "chaves diferentes executam de novo",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/students", key: "k2", body: `{}`, wantStatus: http.StatusCreated, wantBody: "resposta 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "erro do servidor não é guardado",
+			status: http.StatusInternalServerError,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusInternalServerError, wantBody: "resposta 1"},
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusInternalServerError, wantBody: "resposta 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "erro do cliente é guardado",
+			status: http.StatusUnprocessableEntity,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusUnprocessableEntity, wantBody: "resposta 1"},
+				{path: "/students", key: "k1", body: `{}`, wantStatus: http.StatusUnprocessableEntity, wantBody: "resposta 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "sem chave não há idempotência",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", body: `{}`, wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/students", body: `{}`, wantStatus: http.StatusCreated, wantBody: "resposta 2"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "importação repetida",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/imports/students", key: "k1", body: "name\nAna\n", wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/imports/students", key: "k1", body: "name\nAna\n", wantStatus: http.StatusCreated, wantBody: "resposta 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "importação acima do limite em memória",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/imports/students", key: "k1", body: strings.Repeat("a", maxIdempotentBodyBytes+1), wantStatus: http.StatusCreated, wantBody: "resposta 1"},
+				{path: "/imports/students", key: "k1", body: strings.Repeat("a", maxIdempotentBodyBytes+1), wantStatus: http.StatusCreated, wantBody: "resposta 1", wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "importação grande demais",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/imports/students", key: "k1", body: strings.Repeat("a", maxIdempotentUploadBytes+1), wantStatus: http.StatusRequestEntityTooLarge},
+			},
+		},
+		{
+			name:   "chave inválida",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "chave com espaço", body: `{}`, wantStatus: http.StatusBadRequest},
+			},
+		},
+		{
+			name:   "corpo grande demais",
+			status: http.StatusCreated,
+			steps: []idempotencyStep{
+				{path: "/students", key: "k1", body: strings.Repeat("a", maxIdempotentBodyBytes+1), wantStatus: http.StatusRequestEntityTooLarge},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				io.ReadAll(r.Body) // O handler ainda lê o corpo original
+				w.WriteHeader(tt.status)
+				io.WriteString(w, "resposta "+strconv.Itoa(int(n)))
+			})
+			handler := NewIdempotency(NewMemoryIdempotencyStore(), time.Hour, slog.New(slog.DiscardHandler)).Handler(next)
+
+			for i, step := range tt.steps {
+				r := httptest.NewRequest(http.MethodPost, step.path, strings.NewReader(step.body))
+				if step.key != "" {
+					r.Header.Set("Idempotency-Key", step.key)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != step.wantStatus {
+					t.Fatalf("passo %d: status = %d, esperava %d (corpo: %s)", i, w.Code, step.wantStatus, w.Body.String())
+				}
+				if step.wantBody != "" && w.Body.String() != step.wantBody {
+					t.Errorf("passo %d: corpo = %q, esperava %q", i, w.Body.String(), step.wantBody)
+				}
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != step.wantReplayed {
+					t.Errorf("passo %d: Idempotent-Replayed = %v, esperava %v", i, replayed, step.wantReplayed)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler executado %d vezes, esperava %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlightLock(t *testing.T) {
+	originalHeartbeat := idempotencyHeartbeat
+	idempotencyHeartbeat = 5 * time.Millisecond
+	defer func() { idempotencyHeartbeat = originalHeartbeat }()
+
+	store := &countingIdempotencyStore{MemoryIdempotencyStore: NewMemoryIdempotencyStore()}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "criado")
+	})
+	handler := NewIdempotency(store, time.Hour, slog.New(slog.DiscardHandler)).Handler(next)
+	send := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/students", strings.NewReader(body))
+		r.Header.Set("Idempotency-Key", "k1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	original := make(chan *httptest.ResponseRecorder)
+	go func() { original <- send(`{}`) }()
+	<-started
+
+	// Enquanto a original está em andamento, a reserva é prorrogada e as novas tentativas esperam
+	deadline := time.Now().Add(time.Second)
+	for store.extends.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if store.extends.Load() < 2 {
+		t.Fatalf("reserva prorrogada %d vezes, esperava ao menos 2", store.extends.Load())
+	}
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "mesmo corpo aguarda a original", body: `{}`, wantStatus: http.StatusConflict},
+		{name: "outro corpo é recusado", body: `{"x":1}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := send(tt.body); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, esperava %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	close(release)
+	if w := <-original; w.Code != http.StatusCreated {
+		t.Fatalf("original: status = %d, esperava %d", w.Code, http.StatusCreated)
+	}
+	extends := store.extends.Load()
+	time.Sleep(20 * time.Millisecond)
+	if store.extends.Load() != extends {
+		t.Error("reserva prorrogada depois do fim da requisição original")
+	}
+	if w := send(`{}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("depois da original: status = %d, replay = %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestMemoryIdempotencyStoreLock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		extendBy     time.Duration // Prorrogação antes da nova tentativa (0: nenhuma)
+		retryAfter   time.Duration
+		wantReserved bool
+	}{
+		{name: "reserva válida", retryAfter: 30 * time.Second, wantReserved: false},
+		{name: "reserva expirada", retryAfter: IdempotencyLockTimeout, wantReserved: true},
+		{name: "reserva prorrogada", extendBy: 2 * IdempotencyLockTimeout, retryAfter: IdempotencyLockTimeout + time.Second, wantReserved: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryIdempotencyStore()
+			if existing, err := store.Begin(ctx, "k", "fp", start, start.Add(IdempotencyLockTimeout)); err != nil || existing != nil {
+				t.Fatalf("primeira reserva: %+v, %v", existing, err)
+			}
+			if tt.extendBy > 0 {
+				store.Extend(ctx, "k", "fp", start.Add(tt.extendBy))
+			}
+			existing, err := store.Begin(ctx, "k", "fp", start.Add(tt.retryAfter), start.Add(tt.retryAfter+IdempotencyLockTimeout))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if reserved := existing == nil; reserved != tt.wantReserved {
+				t.Errorf("nova reserva = %v, esperava %v", reserved, tt.wantReserved)
+			}
+			if existing != nil && existing.Completed {
+				t.Error("reserva em andamento retornada como concluída")
+			}
+		})
+	}
+}
+
+func TestMemoryIdempotencyStoreRelease(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		fingerprint  string // Impressão digital informada ao Release
+		completed    bool
+		wantReleased bool
+	}{
+		{name: "reserva da própria requisição", fingerprint: "fp", wantReleased: true},
+		{name: "reserva de outra requisição", fingerprint: "outra", wantReleased: false},
+		{name: "requisição concluída", fingerprint: "fp", completed: true, wantReleased: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryIdempotencyStore()
+			store.Begin(ctx, "k", "fp", start, start.Add(IdempotencyLockTimeout))
+			if tt.completed {
+				store.Complete(ctx, "k", &IdempotencyRecord{Fingerprint: "fp", Completed: true, StatusCode: http.StatusCreated}, start.Add(time.Hour))
+			}
+			if err := store.Release(ctx, "k", tt.fingerprint); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			existing, err := store.Begin(ctx, "k", "fp", start.Add(time.Second), start.Add(IdempotencyLockTimeout))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if released := existing == nil; released != tt.wantReleased {
+				t.Errorf("reserva liberada = %v, esperava %v", released, tt.wantReleased)
+			}
+		})
+	}
+}
+
+// countingIdempotencyStore conta as prorrogações de reserva.
+type countingIdempotencyStore struct {
+	*MemoryIdempotencyStore
+	extends atomic.Int32
+}
+
+func (s *countingIdempotencyStore) Extend(ctx context.Context, key, fingerprint string, lockedUntil time.Time) error {
+	s.extends.Add(1)
+	return s.MemoryIdempotencyStore.Extend(ctx, key, fingerprint, lockedUntil)
+}
+
+func TestBufferRequestBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantSpool bool
+	}{
+		{name: "corpo vazio", body: ""},
+		{name: "corpo em memória", body: strings.Repeat("a", maxIdempotentBodyBytes)},
+		{name: "corpo em arquivo temporário", body: strings.Repeat("a", maxIdempotentBodyBytes) + "fim", wantSpool: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/imports/students?dry_run=true", nil)
+			body, fingerprint, err := bufferRequestBody(r, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			spool, spooled := body.(*tempFileBody)
+			if spooled != tt.wantSpool {
+				t.Errorf("arquivo temporário = %v, esperava %v", spooled, tt.wantSpool)
+			}
+			got, _ := io.ReadAll(body)
+			if string(got) != tt.body {
+				t.Errorf("corpo repassado com %d bytes, esperava %d", len(got), len(tt.body))
+			}
+			if err := body.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+			if spooled {
+				if _, err := os.Stat(spool.Name()); !os.IsNotExist(err) {
+					t.Errorf("arquivo temporário não removido: %v", err)
+				}
+			}
+
+			// A impressão digital depende do conteúdo, não de onde o corpo foi guardado
+			_, same, _ := bufferRequestBody(r, strings.NewReader(tt.body))
+			_, other, _ := bufferRequestBody(r, strings.NewReader(tt.body+"x"))
+			if fingerprint != same || fingerprint == other {
+				t.Errorf("impressões digitais: %s, %s (mesmo corpo), %s (outro corpo)", fingerprint, same, other)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// middleware/identity.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"college-app-v1/reqctx"
+
+	"github.com/google/uuid"
+)
+
+// validRequestID limita IDs recebidos do cliente a um formato seguro para logs e para o banco.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// validUserID limita o X-User-ID a um formato seguro para logs e para a coluna actor da auditoria.
+var validUserID = regexp.MustCompile(`^[A-Za-z0-9._@+-]{1,128}$`)
+
+// RequestID garante que toda requisição tenha um ID de correlação: reaproveita o header
+// X-Request-ID enviado pelo cliente (se válido) ou gera um novo, devolvendo-o na resposta.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		next.ServeHTTP(w, r.WithContext(reqctx.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// Identity registra no contexto quem está fazendo a requisição. O acesso de administrador exige
+// "Authorization: Bearer <adminToken>"; com adminToken vazio, nenhuma requisição é tratada como
+// administrador. O header X-User-ID não é autenticado, então o autor é gravado como identidade
+// declarada: "claimed:<id>" sem o token e "admin:<id>" com ele ("admin" sem o header).
+func Identity(adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			admin := false
+			if adminToken != "" {
+				token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				admin = found && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+			}
+			if actor := identityActor(strings.TrimSpace(r.Header.Get("X-User-ID")), admin); actor != "" {
+				ctx = reqctx.WithActor(ctx, actor)
+			}
+			ctx = reqctx.WithAdmin(ctx, admin)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// identityActor monta o autor registrado na auditoria a partir do X-User-ID declarado e da
+// autenticação de administrador. Um X-User-ID em formato inválido é ignorado.
+func identityActor(userID string, admin bool) string {
+	if !validUserID.MatchString(userID) {
+		userID = ""
+	}
+	switch {
+	case admin && userID != "":
+		return reqctx.AdminActor + ":" + userID
+	case admin:
+		return reqctx.AdminActor
+	case userID != "":
+		return reqctx.ClaimedActorPrefix + userID
+	}
+	return ""
+}
+
+// RequireAdmin restringe um handler a administradores autenticados por Identity.
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !reqctx.IsAdmin(r.Context()) {
+			w.Header().Set("Content-Type", "application/json")
+			http.Error(w, `{"message": "Acesso restrito a administradores."}`, http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,45 @@
+// middleware/metrics.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"college-app-v1/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute agrupa requisições sem rota correspondente, para que caminhos arbitrários
+// não criem novas séries de métricas.
+const unmatchedRoute = "unmatched"
+
+// Metrics registra contagem e latência das requisições por modelo de rota do router
+// (ex: /students/{id}), em vez do caminho concreto, mantendo a cardinalidade baixa.
+func Metrics(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			route := routeTemplate(router, r)
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+			metrics.HTTPDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// routeTemplate retorna o modelo da rota do router que atende r, ou unmatchedRoute.
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return unmatchedRoute
+	}
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
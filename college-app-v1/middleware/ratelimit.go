@@ -0,0 +1,185 @@
+// middleware/ratelimit.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"college-app-v1/reqctx"
+)
+
+// Limit descreve um token bucket: Requests fichas reabastecidas a cada Period,
+// com capacidade máxima Burst (por padrão igual a Requests).
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// ParseLimit interpreta limites no formato "100/1m" ou "100/1m/20" (com burst explícito).
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Limit{}, fmt.Errorf("limite inválido %q: use o formato <requisições>/<período>[/<burst>]", s)
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("limite inválido %q: número de requisições deve ser inteiro positivo", s)
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("limite inválido %q: período deve ser uma duração positiva (ex: 1m, 30s)", s)
+	}
+	limit := Limit{Requests: requests, Period: period, Burst: requests}
+	if len(parts) == 3 {
+		burst, err := strconv.Atoi(parts[2])
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("limite inválido %q: burst deve ser inteiro positivo", s)
+		}
+		limit.Burst = burst
+	}
+	return limit, nil
+}
+
+// ratePerSecond retorna a taxa de reabastecimento do bucket em fichas por segundo.
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result é a decisão do store para uma requisição.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // tempo até a próxima ficha disponível (apenas quando !Allowed)
+	Reset      time.Duration // tempo até o bucket estar cheio novamente
+}
+
+// RateLimitStore guarda o estado dos buckets. Implementações devem ser seguras para uso concorrente.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// takeFromBucket aplica o algoritmo de token bucket sobre o estado atual de um bucket
+// e devolve o novo número de fichas junto com a decisão. Compartilhado pelos stores.
+func takeFromBucket(tokens float64, updatedAt time.Time, limit Limit, now time.Time) (float64, Result) {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	rate := limit.ratePerSecond()
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed*rate)
+
+	result := Result{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = time.Duration((float64(limit.Burst) - tokens) / rate * float64(time.Second))
+	return tokens, result
+}
+
+// RateLimiter é o middleware HTTP que aplica os limites por cliente e por grupo de rotas.
+type RateLimiter struct {
+	store        RateLimitStore
+	defaultLimit Limit
+	groups       map[string]Limit
+	trustProxy   bool
+	logger       *slog.Logger
+}
+
+// NewRateLimiter cria um RateLimiter. groups mapeia o primeiro segmento da rota
+// (ex: "students" para /students/{id}) para um limite específico.
+func NewRateLimiter(store RateLimitStore, defaultLimit Limit, groups map[string]Limit, trustProxy bool, logger *slog.Logger) *RateLimiter {
+	if groups == nil {
+		groups = map[string]Limit{}
+	}
+	return &RateLimiter{store: store, defaultLimit: defaultLimit, groups: groups, trustProxy: trustProxy, logger: logger}
+}
+
+// Handler envolve next aplicando o rate limiting. Requisições OPTIONS (preflight CORS) não são contadas.
+func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		group := routeGroup(r.URL.Path)
+		limit, ok := rl.groups[group]
+		if !ok {
+			limit = rl.defaultLimit
+		}
+		key := group + ":" + rl.clientKey(r)
+
+		result, err := rl.store.Take(r.Context(), key, limit, time.Now())
+		if err != nil {
+			// Falha no store não deve derrubar a API: registra e deixa a requisição passar.
+			rl.logger.ErrorContext(r.Context(), "erro ao consultar store de rate limiting", "group", group, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, ceilSeconds(limit.Period), limit.Burst))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			w.Header().Set("Content-Type", "application/json")
+			http.Error(w, `{"message": "Limite de requisições excedido. Tente novamente mais tarde."}`, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey identifica o cliente: administradores autenticados (Authorization: Bearer, validado
+// por Identity) compartilham a chave "admin"; os demais são identificados pelo endereço IP.
+// Headers não autenticados (ex: X-User-ID) não são usados, pois bastaria variá-los a cada
+// requisição para escapar do limite.
+func (rl *RateLimiter) clientKey(r *http.Request) string {
+	if reqctx.IsAdmin(r.Context()) {
+		return "admin"
+	}
+	return "ip:" + clientIP(r, rl.trustProxy)
+}
+
+// clientIP extrai o IP do cliente. Com trustProxy, usa o primeiro endereço de X-Forwarded-For,
+// necessário quando a API roda atrás de um proxy (ex: Vercel).
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return realIP
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// routeGroup retorna o primeiro segmento do caminho (ex: "/students/123" -> "students").
+func routeGroup(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return strings.ToLower(segment)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
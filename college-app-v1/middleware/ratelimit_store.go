@@ -0,0 +1,144 @@
+// middleware/ratelimit_store.go
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MemoryRateLimitStore mantém os buckets em memória. Adequado para uma única instância;
+// em implantações com várias instâncias cada uma terá seus próprios contadores.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	idleAfter time.Duration // tempo após o qual o bucket estaria cheio e pode ser descartado
+}
+
+// NewMemoryRateLimitStore cria um store em memória vazio.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+}
+
+// Take consome uma ficha do bucket identificado por key.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	tokens, result := takeFromBucket(bucket.tokens, bucket.updatedAt, limit, now)
+	bucket.tokens = tokens
+	bucket.updatedAt = now
+	bucket.idleAfter = result.Reset
+	return result, nil
+}
+
+// sweep remove buckets que já estariam cheios, evitando crescimento ilimitado do mapa.
+// Executa no máximo uma vez por minuto.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > bucket.idleAfter {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// PostgresRateLimitStore guarda os buckets na tabela rate_limit_buckets, permitindo
+// que várias instâncias (ex: Vercel Functions) compartilhem os mesmos limites.
+type PostgresRateLimitStore struct {
+	db     *sql.DB
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewPostgresRateLimitStore cria um store baseado no PostgreSQL.
+func NewPostgresRateLimitStore(db *sql.DB, logger *slog.Logger) *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{db: db, logger: logger}
+}
+
+// Take consome uma ficha do bucket dentro de uma transação com bloqueio de linha,
+// garantindo consistência entre instâncias concorrentes.
+func (s *PostgresRateLimitStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("falha ao iniciar transação de rate limit: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING`,
+		key, float64(limit.Burst), now)
+	if err != nil {
+		return Result{}, fmt.Errorf("falha ao criar bucket de rate limit: %w", err)
+	}
+
+	var tokens float64
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx,
+		`SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key).Scan(&tokens, &updatedAt)
+	if err != nil {
+		return Result{}, fmt.Errorf("falha ao ler bucket de rate limit: %w", err)
+	}
+
+	tokens, result := takeFromBucket(tokens, updatedAt, limit, now)
+	_, err = tx.ExecContext(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2, expires_at = $3 WHERE key = $4`,
+		tokens, now, now.Add(result.Reset), key)
+	if err != nil {
+		return Result{}, fmt.Errorf("falha ao atualizar bucket de rate limit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("falha ao confirmar transação de rate limit: %w", err)
+	}
+
+	s.cleanup(ctx, now)
+	return result, nil
+}
+
+// cleanup remove buckets expirados no máximo a cada 10 minutos por instância.
+func (s *PostgresRateLimitStore) cleanup(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < 10*time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	if removed, err := s.DeleteExpired(ctx, now); err != nil {
+		s.logger.ErrorContext(ctx, "erro ao remover buckets expirados", "error", err)
+	} else if removed > 0 {
+		s.logger.InfoContext(ctx, "buckets expirados removidos", "count", removed)
+	}
+}
+
+// DeleteExpired remove buckets que já estariam cheios.
+func (s *PostgresRateLimitStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao remover buckets expirados: %w", err)
+	}
+	return res.RowsAffected()
+}
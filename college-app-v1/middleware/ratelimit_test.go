@@ -0,0 +1,151 @@
+// middleware/ratelimit_test.go
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"college-app-v1/reqctx"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Limit
+		wantErr bool
+	}{
+		{name: "burst padrão igual às requisições", input: "100/1m", want: Limit{Requests: 100, Period: time.Minute, Burst: 100}},
+		{name: "burst explícito", input: "100/1m/20", want: Limit{Requests: 100, Period: time.Minute, Burst: 20}},
+		{name: "espaços nas pontas", input: "  5/30s ", want: Limit{Requests: 5, Period: 30 * time.Second, Burst: 5}},
+		{name: "sem período", input: "100", wantErr: true},
+		{name: "partes demais", input: "100/1m/20/5", wantErr: true},
+		{name: "requisições não numéricas", input: "muitas/1m", wantErr: true},
+		{name: "requisições zero", input: "0/1m", wantErr: true},
+		{name: "período inválido", input: "100/minuto", wantErr: true},
+		{name: "período negativo", input: "100/-1m", wantErr: true},
+		{name: "burst zero", input: "100/1m/0", wantErr: true},
+		{name: "burst não numérico", input: "100/1m/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimit(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLimit(%q) = %+v, esperava erro", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLimit(%q): erro inesperado: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %+v, esperava %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakeFromBucket(t *testing.T) {
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 10} // 1 ficha por segundo
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		tokens        float64
+		elapsed       time.Duration
+		wantTokens    float64
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+		wantReset     time.Duration
+	}{
+		{name: "bucket cheio", tokens: 10, wantTokens: 9, wantAllowed: true, wantRemaining: 9, wantReset: time.Second},
+		{name: "última ficha", tokens: 1, wantTokens: 0, wantAllowed: true, wantRemaining: 0, wantReset: 10 * time.Second},
+		{name: "vazio", tokens: 0, wantTokens: 0, wantRetry: time.Second, wantReset: 10 * time.Second},
+		{name: "meia ficha", tokens: 0.5, wantTokens: 0.5, wantRetry: 500 * time.Millisecond, wantReset: 9500 * time.Millisecond},
+		{name: "reabastece com o tempo", tokens: 0, elapsed: 3 * time.Second, wantTokens: 2, wantAllowed: true, wantRemaining: 2, wantReset: 8 * time.Second},
+		{name: "reabastecimento limitado ao burst", tokens: 2, elapsed: time.Hour, wantTokens: 9, wantAllowed: true, wantRemaining: 9, wantReset: time.Second},
+		{name: "relógio para trás não reabastece", tokens: 0, elapsed: -5 * time.Second, wantTokens: 0, wantRetry: time.Second, wantReset: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, result := takeFromBucket(tt.tokens, start, limit, start.Add(tt.elapsed))
+			if tokens != tt.wantTokens {
+				t.Errorf("fichas = %v, esperava %v", tokens, tt.wantTokens)
+			}
+			if result.Allowed != tt.wantAllowed || result.Remaining != tt.wantRemaining {
+				t.Errorf("Allowed/Remaining = %v/%d, esperava %v/%d", result.Allowed, result.Remaining, tt.wantAllowed, tt.wantRemaining)
+			}
+			if result.RetryAfter != tt.wantRetry {
+				t.Errorf("RetryAfter = %v, esperava %v", result.RetryAfter, tt.wantRetry)
+			}
+			if result.Reset != tt.wantReset {
+				t.Errorf("Reset = %v, esperava %v", result.Reset, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 2} // 1 ficha a cada 500ms
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		at          time.Duration
+		key         string
+		wantAllowed bool
+	}{
+		{at: 0, key: "ip:a", wantAllowed: true},
+		{at: 0, key: "ip:a", wantAllowed: true},
+		{at: 0, key: "ip:a", wantAllowed: false},
+		{at: 0, key: "ip:b", wantAllowed: true}, // Buckets independentes por cliente
+		{at: 250 * time.Millisecond, key: "ip:a", wantAllowed: false},
+		{at: 500 * time.Millisecond, key: "ip:a", wantAllowed: true},
+		{at: 500 * time.Millisecond, key: "ip:a", wantAllowed: false},
+		{at: 2 * time.Second, key: "ip:a", wantAllowed: true},
+		{at: 2 * time.Second, key: "ip:a", wantAllowed: true},
+		{at: 2 * time.Second, key: "ip:a", wantAllowed: false},
+	}
+	store := NewMemoryRateLimitStore()
+	for i, step := range steps {
+		result, err := store.Take(context.Background(), step.key, limit, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("passo %d: erro inesperado: %v", i, err)
+		}
+		if result.Allowed != step.wantAllowed {
+			t.Errorf("passo %d (%s em %v): Allowed = %v, esperava %v", i, step.key, step.at, result.Allowed, step.wantAllowed)
+		}
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		admin      bool
+		headers    map[string]string
+		want       string
+	}{
+		{name: "endereço remoto", want: "ip:192.0.2.1"},
+		{name: "headers de identidade ignorados", headers: map[string]string{"X-User-ID": "alice", "X-API-Key": "segredo"}, want: "ip:192.0.2.1"},
+		{name: "X-Forwarded-For sem proxy confiável", headers: map[string]string{"X-Forwarded-For": "203.0.113.9"}, want: "ip:192.0.2.1"},
+		{name: "X-Forwarded-For com proxy confiável", trustProxy: true, headers: map[string]string{"X-Forwarded-For": "203.0.113.9, 10.0.0.1"}, want: "ip:203.0.113.9"},
+		{name: "X-Real-IP com proxy confiável", trustProxy: true, headers: map[string]string{"X-Real-IP": "203.0.113.7"}, want: "ip:203.0.113.7"},
+		{name: "administrador autenticado", admin: true, headers: map[string]string{"X-Forwarded-For": "203.0.113.9"}, want: "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := &RateLimiter{trustProxy: tt.trustProxy}
+			r := httptest.NewRequest("GET", "/students", nil)
+			r.RemoteAddr = "192.0.2.1:54321"
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			r = r.WithContext(reqctx.WithAdmin(r.Context(), tt.admin))
+			if got := rl.clientKey(r); got != tt.want {
+				t.Errorf("clientKey = %q, esperava %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// middleware/security.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SecurityHeaders adiciona headers de segurança padrão a todas as respostas.
+// HSTS só é enviado se hstsMaxAge > 0; a CSP só é aplicada a respostas HTML,
+// já que a API responde JSON e a política não tem efeito nesses casos.
+func SecurityHeaders(hstsMaxAge int, contentSecurityPolicy string) func(http.Handler) http.Handler {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(hstsMaxAge) + "; includeSubDomains"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			if hsts != "" {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(&cspResponseWriter{ResponseWriter: w, policy: contentSecurityPolicy}, r)
+		})
+	}
+}
+
+// cspResponseWriter aplica a Content-Security-Policy no momento em que o status é escrito,
+// quando o Content-Type final da resposta já é conhecido.
+type cspResponseWriter struct {
+	http.ResponseWriter
+	policy      string
+	wroteHeader bool
+}
+
+func (w *cspResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.policy != "" && strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			w.Header().Set("Content-Security-Policy", w.policy)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cspResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		// Mesmo comportamento do net/http: detecta o Content-Type se o handler não definiu.
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap permite que http.ResponseController acesse o ResponseWriter original.
+func (w *cspResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,47 @@
+// middleware/tracing.go
+package middleware
+
+import (
+	"net/http"
+
+	"college-app-v1/reqctx"
+	"college-app-v1/tracing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing cria um span de servidor por requisição, continuando o trace recebido no header
+// traceparent (W3C Trace Context) quando o cliente o envia. O nome do span usa o modelo de
+// rota do router (ex: "GET /students/{id}"). Deve ficar dentro de RequestID.
+func Tracing(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			route := routeTemplate(router, r)
+			ctx, span := tracing.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodOriginal(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.HTTPRoute(route),
+					attribute.String("request_id", reqctx.RequestID(r.Context())),
+				),
+			)
+			defer span.End()
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+			if recorder.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(recorder.status))
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// models/audit_event.go
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent representa uma mutação registrada no log de auditoria (tabela append-only audit_events).
+type AuditEvent struct {
+	ID         int64           `json:"id"`                   // Sequencial gerado pelo banco
+	OccurredAt time.Time       `json:"occurred_at"`          // Momento da mutação
+	Actor      string          `json:"actor"`                // Quem fez a alteração ("admin", "admin:<id>", "claimed:<id>" ou "anonymous")
+	RequestID  string          `json:"request_id,omitempty"` // ID de correlação da requisição HTTP
+	EntityType string          `json:"entity_type"`          // "student", "teacher", "subject", "program" ou "department"
+	EntityID   string          `json:"entity_id"`            // ID da entidade alterada
+	Action     string          `json:"action"`               // Ex: "create", "update", "delete", "add_subject"
+	Before     json.RawMessage `json:"before,omitempty"`     // Estado antes da mutação (JSON)
+	After      json.RawMessage `json:"after,omitempty"`      // Estado depois da mutação (JSON)
+	Changes    json.RawMessage `json:"changes,omitempty"`    // Diff campo a campo: {"campo": {"from": ..., "to": ...}}
+}
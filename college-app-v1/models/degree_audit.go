@@ -0,0 +1,56 @@
+// models/degree_audit.go
+package models
+
+// Tipos de requisito de formatura avaliados na auditoria.
+const (
+	DegreeRequirementTotalCredits = "total_credits"     // Total de créditos aprovados
+	DegreeRequirementMandatory    = "mandatory_subject" // Matéria obrigatória de um ano
+	DegreeRequirementElective     = "elective_bucket"   // Créditos mínimos em um grupo de eletivas
+)
+
+// Situação de um requisito de formatura.
+const (
+	DegreeRequirementSatisfied  = "satisfied"   // Cumprido com matérias aprovadas
+	DegreeRequirementInProgress = "in_progress" // Será cumprido se o aluno for aprovado nas matérias em curso
+	DegreeRequirementMissing    = "missing"     // Não cumprido nem em curso
+)
+
+// DegreeRequirement é um requisito de formatura e a situação do aluno nele.
+type DegreeRequirement struct {
+	Type              string `json:"type"`                          // total_credits, mandatory_subject ou elective_bucket
+	Description       string `json:"description"`                   // Nome da matéria, do grupo de eletivas ou "Total de créditos"
+	SubjectID         string `json:"subject_id,omitempty"`          // Apenas mandatory_subject
+	Year              int    `json:"year,omitempty"`                // Ano da matéria obrigatória
+	Bucket            string `json:"bucket,omitempty"`              // Apenas elective_bucket
+	RequiredCredits   int    `json:"required_credits"`              // Créditos exigidos (da matéria, no caso de obrigatórias)
+	EarnedCredits     int    `json:"earned_credits"`                // Créditos aprovados que contam para o requisito
+	InProgressCredits int    `json:"in_progress_credits,omitempty"` // Créditos em curso que contam para o requisito
+	Status            string `json:"status"`                        // satisfied, in_progress ou missing
+}
+
+// DegreeAudit compara as matérias do aluno (student_subjects) com os requisitos de formatura da
+// sua grade curricular ou, para alunos sem grade, com os requisitos gerais do catálogo.
+type DegreeAudit struct {
+	StudentID         string              `json:"student_id"`
+	Enrollment        string              `json:"enrollment"`
+	Name              string              `json:"name"`
+	CurrentYear       int                 `json:"current_year"`
+	ProgramID         string              `json:"program_id,omitempty"`
+	CurriculumID      string              `json:"curriculum_id,omitempty"`
+	RequirementsFrom  string              `json:"requirements_from"` // "curriculum" ou "catalog"
+	RequiredCredits   int                 `json:"required_credits"`
+	EarnedCredits     int                 `json:"earned_credits"`      // Créditos de todas as matérias aprovadas
+	InProgressCredits int                 `json:"in_progress_credits"` // Créditos das matérias em curso
+	Eligible          bool                `json:"eligible"`            // Todos os requisitos cumpridos
+	Satisfied         []DegreeRequirement `json:"satisfied"`
+	Missing           []DegreeRequirement `json:"missing"` // Inclui os requisitos em curso
+}
+
+// StudentSubjectRecord é uma matéria associada ao aluno, com seus créditos e a situação do aluno
+// nela (inclui matérias excluídas depois da associação, que continuam no histórico).
+type StudentSubjectRecord struct {
+	SubjectID string
+	Name      string
+	Credits   int
+	Status    string // enrolled, passed ou failed
+}
@@ -0,0 +1,13 @@
+// models/department.go
+package models
+
+// Department representa um departamento acadêmico (ex: Ciência da Computação), ao qual
+// pertencem os professores.
+type Department struct {
+	ID              string `json:"id"`                          // ID único do departamento (gerado, ex: UUID)
+	Code            string `json:"code"`                        // Código curto e único (ex: "DCC")
+	Name            string `json:"name"`                        // Nome único do departamento
+	HeadTeacherID   string `json:"head_teacher_id,omitempty"`   // Chefe do departamento (professor do próprio departamento)
+	HeadTeacherName string `json:"head_teacher_name,omitempty"` // Preenchido nas consultas
+	Version         int    `json:"version"`                     // Versão do registro para controle de concorrência otimista (ETag)
+}
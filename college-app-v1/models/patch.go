@@ -0,0 +1,31 @@
+// models/patch.go
+package models
+
+// Patches parciais (PATCH com JSON Merge Patch, RFC 7396). Um campo nil não foi enviado
+// e permanece inalterado; os campos destas entidades são obrigatórios e não podem ser removidos.
+
+// StudentPatch contém os campos de um aluno alteráveis via PATCH.
+type StudentPatch struct {
+	Name         *string `json:"name"`
+	CurrentYear  *int    `json:"current_year"`
+	Shift        *string `json:"shift"`
+	ProgramID    *string `json:"program_id"`
+	CurriculumID *string `json:"curriculum_id"`
+}
+
+// TeacherPatch contém os campos de um professor alteráveis via PATCH.
+type TeacherPatch struct {
+	Name         *string `json:"name"`
+	Email        *string `json:"email"`
+	DepartmentID *string `json:"department_id"`
+	Department   *string `json:"department"` // Código ou nome do departamento, alternativa a department_id
+	ContractType *string `json:"contract_type"`
+}
+
+// SubjectPatch contém os campos de uma matéria alteráveis via PATCH.
+type SubjectPatch struct {
+	Name      *string `json:"name"`
+	Year      *int    `json:"year"`
+	Credits   *int    `json:"credits"`
+	Mandatory *bool   `json:"mandatory"`
+}
@@ -0,0 +1,45 @@
+// models/program.go
+package models
+
+import "time"
+
+// Program representa um curso de graduação (ex: Sistemas de Informação, Engenharia).
+type Program struct {
+	ID                  string `json:"id"`                     // ID único do curso (gerado, ex: UUID)
+	Code                string `json:"code"`                   // Código curto e único (ex: "SI"), usado na matrícula quando habilitado
+	Name                string `json:"name"`                   // Nome do curso
+	UseCodeInEnrollment bool   `json:"use_code_in_enrollment"` // Prefixa as matrículas com o código (ex: SI2025M0001)
+	Version             int    `json:"version"`                // Versão do registro para controle de concorrência otimista (ETag)
+}
+
+// Curriculum é uma versão da grade curricular de um curso. Grades não são alteradas depois de
+// criadas: mudanças geram uma nova versão, e os alunos continuam na versão em que ingressaram.
+// Os requisitos de formatura da grade são as matérias obrigatórias, o total de créditos e os
+// créditos mínimos de cada grupo de eletivas.
+type Curriculum struct {
+	ID              string              `json:"id"`                    // ID único da grade (gerado, ex: UUID)
+	ProgramID       string              `json:"program_id"`            // Curso ao qual a grade pertence
+	Version         int                 `json:"version"`               // Número da versão da grade no curso (1, 2, ...)
+	Description     string              `json:"description,omitempty"` // Descrição opcional (ex: "Grade 2025")
+	RequiredCredits int                 `json:"required_credits"`      // Créditos aprovados exigidos para a formatura (0 = sem mínimo)
+	CreatedAt       time.Time           `json:"created_at"`
+	ElectiveBuckets []ElectiveBucket    `json:"elective_buckets"`
+	Subjects        []CurriculumSubject `json:"subjects"`
+}
+
+// ElectiveBucket é um grupo de matérias eletivas da grade, do qual o aluno deve ser aprovado em
+// um mínimo de créditos (ex: "Optativas de Computação", 8 créditos).
+type ElectiveBucket struct {
+	Name       string `json:"name"`
+	MinCredits int    `json:"min_credits"`
+}
+
+// CurriculumSubject é uma matéria da grade, com o ano em que é oferecida e se é obrigatória ou eletiva.
+type CurriculumSubject struct {
+	SubjectID string `json:"subject_id"`
+	Name      string `json:"name,omitempty"`    // Preenchido nas consultas
+	Credits   int    `json:"credits,omitempty"` // Preenchido nas consultas
+	Year      int    `json:"year"`
+	Mandatory bool   `json:"mandatory"`
+	Bucket    string `json:"bucket,omitempty"` // Grupo de eletivas da matéria (apenas eletivas)
+}
@@ -1,12 +1,34 @@
 // models/student.go
 package models
 
+import "time"
+
 // Student representa um aluno na universidade.
 type Student struct {
-	ID          string    `json:"id"`           // ID único do aluno (gerado, ex: UUID)
-	Enrollment  string    `json:"enrollment"`   // Matrícula do aluno (gerado automaticamente por lógica de negócio)
-	Name        string    `json:"name"`         // Nome completo do aluno
-	CurrentYear int       `json:"current_year"` // Ano atual do aluno na universidade (ex: 1, 2, 3, 4)
-	Shift       string    `json:"shift"`        // Turno do aluno (ex: "M" - Manhã, "T" - Tarde, "N" - Noite)
-	Subjects    []Subject `json:"subjects"`     // Matérias que o aluno está cursando/cursou
+	ID           string     `json:"id"`                      // ID único do aluno (gerado, ex: UUID)
+	Enrollment   string     `json:"enrollment"`              // Matrícula do aluno (gerado automaticamente por lógica de negócio)
+	Name         string     `json:"name"`                    // Nome completo do aluno
+	CurrentYear  int        `json:"current_year"`            // Ano atual do aluno na universidade (ex: 1, 2, 3, 4)
+	Shift        string     `json:"shift"`                   // Turno do aluno (ex: "M" - Manhã, "T" - Tarde, "N" - Noite)
+	ProgramID    string     `json:"program_id,omitempty"`    // Curso do aluno; vazio para alunos sem curso definido
+	CurriculumID string     `json:"curriculum_id,omitempty"` // Versão da grade do curso seguida pelo aluno (padrão: a mais recente no ingresso)
+	Subjects     []Subject  `json:"subjects"`                // Matérias que o aluno está cursando/cursou
+	Version      int        `json:"version"`                 // Versão do registro para controle de concorrência otimista (ETag)
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`    // Momento da exclusão (soft delete); nil se ativo
+}
+
+// StudentEnrollmentRecord reúne os dados usados para gerar novamente a matrícula de um aluno.
+type StudentEnrollmentRecord struct {
+	ID          string
+	Name        string
+	Enrollment  string
+	Shift       string
+	ProgramCode string // Código do curso, se o curso usa o código na matrícula; senão vazio
 }
+
+// Situação de um aluno em uma matéria associada (coluna student_subjects.status).
+const (
+	SubjectStatusEnrolled = "enrolled" // Cursando
+	SubjectStatusPassed   = "passed"   // Aprovado; não é matriculado novamente pelo currículo
+	SubjectStatusFailed   = "failed"   // Reprovado; volta a ser matriculado pelo currículo
+)
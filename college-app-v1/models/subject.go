@@ -1,10 +1,15 @@
 // models/subject.go
 package models
 
+import "time"
+
 // Subject representa uma matéria na universidade.
 type Subject struct {
-	ID      string `json:"id"`      // ID único da matéria (ex: "BSI101")
-	Name    string `json:"name"`    // Nome da matéria (ex: "Programação Orientada a Objetos")
-	Year    int    `json:"year"`    // Ano em que a matéria é oferecida (ex: 1, 2, 3, 4)
-	Credits int    `json:"credits"` // Créditos da matéria (ex: 4)
+	ID        string     `json:"id"`                   // ID único da matéria (ex: "BSI101")
+	Name      string     `json:"name"`                 // Nome da matéria (ex: "Programação Orientada a Objetos")
+	Year      int        `json:"year"`                 // Ano em que a matéria é oferecida (ex: 1, 2, 3, 4)
+	Credits   int        `json:"credits"`              // Créditos da matéria (ex: 4)
+	Mandatory bool       `json:"mandatory"`            // Obrigatória no ano (entra na matrícula automática do currículo)
+	Version   int        `json:"version"`              // Versão do registro para controle de concorrência otimista (ETag)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Momento da exclusão (soft delete); nil se ativo
 }
@@ -1,11 +1,39 @@
 package models
 
+import "time"
+
 // Teacher representa um professor na universidade.
 type Teacher struct {
-	ID         string    `json:"id"`                 // ID único do professor (gerado, ex: UUID)
-	Registry   string    `json:"registry"`           // Registro único do professor (ex: "PROF001")
-	Name       string    `json:"name"`               // Nome completo do professor
-	Email      string    `json:"email"`              // <-- Adicionado: Email do professor (deve ser único no DB)
-	Department string    `json:"department"`         // Departamento do professor (ex: "Ciência da Computação")
-	Subjects   []Subject `json:"subjects,omitempty"` // <-- Adicionado: Matérias associadas ao professor
+	ID           string     `json:"id"`                   // ID único do professor (gerado, ex: UUID)
+	Registry     string     `json:"registry"`             // Registro único do professor (ex: "PROF001")
+	Name         string     `json:"name"`                 // Nome completo do professor
+	Email        string     `json:"email"`                // <-- Adicionado: Email do professor (deve ser único no DB)
+	DepartmentID string     `json:"department_id"`        // Departamento do professor (tabela departments)
+	Department   string     `json:"department"`           // Nome do departamento; na entrada também aceita o código ou o nome em vez do ID
+	ContractType string     `json:"contract_type"`        // Regime de contratação (ContractType*), que define a carga horária máxima
+	Subjects     []Subject  `json:"subjects,omitempty"`   // <-- Adicionado: Matérias associadas ao professor
+	Version      int        `json:"version"`              // Versão do registro para controle de concorrência otimista (ETag)
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"` // Momento da exclusão (soft delete); nil se ativo
+}
+
+// Regimes de contratação de professores (coluna teachers.contract_type).
+const (
+	ContractTypeIntegral = "integral" // Tempo integral (padrão)
+	ContractTypeParcial  = "parcial"  // Tempo parcial
+	ContractTypeHorista  = "horista"  // Pago por hora-aula
+)
+
+// TeacherWorkload é a carga horária semanal de um professor, calculada pelos créditos das
+// matérias (não excluídas) que leciona.
+type TeacherWorkload struct {
+	TeacherID      string `json:"teacher_id"`
+	TeacherName    string `json:"teacher_name"`
+	DepartmentID   string `json:"department_id,omitempty"`
+	Department     string `json:"department,omitempty"`
+	ContractType   string `json:"contract_type"`
+	Subjects       int    `json:"subjects"`         // Quantidade de matérias
+	Credits        int    `json:"credits"`          // Soma dos créditos das matérias
+	WeeklyHours    int    `json:"weekly_hours"`     // Créditos convertidos em horas semanais
+	MaxWeeklyHours int    `json:"max_weekly_hours"` // Limite do regime de contratação; 0 = sem limite
+	Overloaded     bool   `json:"overloaded"`       // WeeklyHours acima de MaxWeeklyHours
 }
@@ -0,0 +1,81 @@
+// repositories/association.go
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+// queryIDs executa uma consulta que retorna uma única coluna de IDs. op identifica a operação nos logs.
+func queryIDs(ctx context.Context, db DBTX, logger *slog.Logger, op, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "erro ao buscar IDs", "op", op, "error", err)
+		return nil, fmt.Errorf("falha ao buscar IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("falha ao escanear ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// activeIDs retorna, dentre ids, os que existem em table e não foram excluídos (soft delete).
+// table vem sempre de constantes internas, nunca de entrada do usuário.
+func activeIDs(ctx context.Context, db DBTX, logger *slog.Logger, table string, ids []string) (map[string]bool, error) {
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id = ANY($1) AND deleted_at IS NULL`, table)
+	found, err := queryIDs(ctx, db, logger, "activeIDs", query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao verificar registros de %s: %w", table, err)
+	}
+	active := make(map[string]bool, len(found))
+	for _, id := range found {
+		active[id] = true
+	}
+	return active, nil
+}
+
+// ActiveStudentIDs retorna, dentre ids, os alunos existentes e não excluídos.
+func (r *StudentRepository) ActiveStudentIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	return activeIDs(ctx, r.db, r.logger, "students", ids)
+}
+
+// ActiveTeacherIDs retorna, dentre ids, os professores existentes e não excluídos.
+func (r *TeacherRepository) ActiveTeacherIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	return activeIDs(ctx, r.db, r.logger, "teachers", ids)
+}
+
+// ActiveSubjectIDs retorna, dentre ids, as matérias existentes e não excluídas.
+func (r *SubjectRepository) ActiveSubjectIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	return activeIDs(ctx, r.db, r.logger, "subjects", ids)
+}
+
+// GetStudentIDsBySubjectID retorna os IDs dos alunos (não excluídos) associados a uma matéria.
+func (r *StudentRepository) GetStudentIDsBySubjectID(ctx context.Context, subjectID string) ([]string, error) {
+	query := `
+	SELECT s.id FROM students s
+	JOIN student_subjects ss ON ss.student_id = s.id
+	WHERE ss.subject_id = $1 AND s.deleted_at IS NULL`
+	return queryIDs(ctx, r.db, r.logger, "GetStudentIDsBySubjectID", query, subjectID)
+}
+
+// GetTeacherIDsBySubjectID retorna os IDs dos professores (não excluídos) associados a uma matéria.
+func (r *TeacherRepository) GetTeacherIDsBySubjectID(ctx context.Context, subjectID string) ([]string, error) {
+	query := `
+	SELECT t.id FROM teachers t
+	JOIN teacher_subjects ts ON ts.teacher_id = t.id
+	WHERE ts.subject_id = $1 AND t.deleted_at IS NULL`
+	return queryIDs(ctx, r.db, r.logger, "GetTeacherIDsBySubjectID", query, subjectID)
+}
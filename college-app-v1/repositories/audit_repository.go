@@ -0,0 +1,100 @@
+// repositories/audit_repository.go
+package repositories
+
+import (
+	"college-app-v1/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// AuditRepository persiste eventos de auditoria. A tabela audit_events é append-only:
+// um trigger no banco rejeita UPDATE e DELETE.
+type AuditRepository struct {
+	db     DBTX
+	logger *slog.Logger
+}
+
+// NewAuditRepository cria uma nova instância de AuditRepository.
+func NewAuditRepository(db *sql.DB, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{db: instrument("audit_events", db), logger: logger}
+}
+
+// WithTx retorna um AuditRepository que executa as operações na transação tx.
+func (r *AuditRepository) WithTx(tx *sql.Tx) *AuditRepository {
+	return &AuditRepository{db: instrument("audit_events", tx), logger: r.logger}
+}
+
+// AppendEvent insere um evento de auditoria, preenchendo ID e OccurredAt.
+func (r *AuditRepository) AppendEvent(ctx context.Context, event *models.AuditEvent) error {
+	query := `
+	INSERT INTO audit_events (actor, request_id, entity_type, entity_id, action, before, after, changes)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, occurred_at`
+	err := r.db.QueryRowContext(ctx, query,
+		event.Actor, nullString(event.RequestID), event.EntityType, event.EntityID, event.Action,
+		nullJSON(event.Before), nullJSON(event.After), nullJSON(event.Changes),
+	).Scan(&event.ID, &event.OccurredAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao inserir evento de auditoria", "entity_type", event.EntityType, "entity_id", event.EntityID, "action", event.Action, "error", err)
+		return fmt.Errorf("falha ao registrar evento de auditoria: %w", err)
+	}
+	return nil
+}
+
+// ListEvents busca eventos de auditoria, do mais recente para o mais antigo.
+// entityType e entityID vazios significam sem filtro; limit limita a quantidade retornada.
+func (r *AuditRepository) ListEvents(ctx context.Context, entityType, entityID string, limit int) ([]models.AuditEvent, error) {
+	baseQuery := `SELECT id, occurred_at, actor, COALESCE(request_id, ''), entity_type, entity_id, action, before, after, changes FROM audit_events WHERE 1=1`
+	args := []interface{}{}
+	argCounter := 1
+
+	if entityType != "" {
+		baseQuery += fmt.Sprintf(" AND entity_type = $%d", argCounter)
+		args = append(args, entityType)
+		argCounter++
+	}
+	if entityID != "" {
+		baseQuery += fmt.Sprintf(" AND entity_id = $%d", argCounter)
+		args = append(args, entityID)
+		argCounter++
+	}
+	baseQuery += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", argCounter)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar eventos de auditoria", "entity_type", entityType, "entity_id", entityID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar eventos de auditoria: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		var before, after, changes []byte
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.RequestID, &e.EntityType, &e.EntityID, &e.Action, &before, &after, &changes); err != nil {
+			return nil, fmt.Errorf("falha ao escanear evento de auditoria: %w", err)
+		}
+		e.Before, e.After, e.Changes = before, after, changes
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de eventos de auditoria: %w", err)
+	}
+	return events, nil
+}
+
+// nullString converte "" em NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullJSON converte JSON vazio em NULL para colunas JSONB.
+func nullJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,137 @@
+// repositories/backup_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// BackupRepository lê e grava linhas de tabelas inteiras como objetos JSON (coluna → valor),
+// para a cópia de segurança lógica. Os nomes de tabelas e colunas vêm da definição fixa do
+// arquivo (services), nunca da entrada do usuário, e por isso são interpolados nas consultas.
+type BackupRepository struct {
+	db     DBTX
+	logger *slog.Logger
+}
+
+// NewBackupRepository cria uma nova instância de BackupRepository.
+func NewBackupRepository(db *sql.DB, logger *slog.Logger) *BackupRepository {
+	return &BackupRepository{db: instrument("backup", db), logger: logger}
+}
+
+// WithTx retorna um BackupRepository que executa as operações na transação tx.
+func (r *BackupRepository) WithTx(tx *sql.Tx) *BackupRepository {
+	return &BackupRepository{db: instrument("backup", tx), logger: r.logger}
+}
+
+// CountRows conta as linhas de table.
+func (r *BackupRepository) CountRows(ctx context.Context, table string) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&count); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao contar linhas", "table", table, "error", err)
+		return 0, fmt.Errorf("falha ao contar linhas de %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// FirstNonEmpty retorna a primeira das tabelas que tem alguma linha, ou "" se todas estão vazias.
+func (r *BackupRepository) FirstNonEmpty(ctx context.Context, tables []string) (string, error) {
+	for _, table := range tables {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+`)`).Scan(&exists); err != nil {
+			return "", fmt.Errorf("falha ao verificar linhas de %s: %w", table, err)
+		}
+		if exists {
+			return table, nil
+		}
+	}
+	return "", nil
+}
+
+// StreamRows percorre as linhas de table na ordem de orderBy, chamando fn com cada uma como
+// objeto JSON com as colunas informadas. Se fn retornar erro, a iteração é interrompida.
+func (r *BackupRepository) StreamRows(ctx context.Context, table string, columns, orderBy []string, fn func(row json.RawMessage) error) error {
+	query := fmt.Sprintf(`SELECT row_to_json(r) FROM (SELECT %s FROM %s) r ORDER BY %s`,
+		strings.Join(columns, ", "), table, strings.Join(orderBy, ", "))
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao ler tabela", "table", table, "error", err)
+		return fmt.Errorf("falha ao ler %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return fmt.Errorf("falha ao escanear linha de %s: %w", table, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro durante iteração de %s: %w", table, err)
+	}
+	return nil
+}
+
+// InsertRows grava rows (objetos JSON coluna → valor) em table, apenas nas colunas informadas;
+// as demais recebem o valor padrão.
+func (r *BackupRepository) InsertRows(ctx context.Context, table string, columns []string, rows []json.RawMessage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	list := strings.Join(columns, ", ")
+	query := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM json_populate_recordset(NULL::%s, $1)`, table, list, list, table)
+	if _, err := r.db.ExecContext(ctx, query, jsonArray(rows)); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao gravar linhas", "table", table, "rows", len(rows), "error", err)
+		return fmt.Errorf("falha ao gravar linhas em %s: %w", table, err)
+	}
+	return nil
+}
+
+// UpdateColumn grava a coluna column das linhas de table identificadas por key, com os valores
+// de rows (usado nas referências circulares, gravadas depois das tabelas referenciadas).
+func (r *BackupRepository) UpdateColumn(ctx context.Context, table string, key []string, column string, rows []json.RawMessage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	conditions := make([]string, len(key))
+	for i, k := range key {
+		conditions[i] = fmt.Sprintf("t.%s = x.%s", k, k)
+	}
+	query := fmt.Sprintf(`UPDATE %s t SET %s = x.%s FROM json_populate_recordset(NULL::%s, $1) x WHERE %s`,
+		table, column, column, table, strings.Join(conditions, " AND "))
+	if _, err := r.db.ExecContext(ctx, query, jsonArray(rows)); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar coluna", "table", table, "column", column, "error", err)
+		return fmt.Errorf("falha ao atualizar %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// ResetSequence ajusta a sequência da coluna serial column de table para continuar após o maior valor gravado.
+func (r *BackupRepository) ResetSequence(ctx context.Context, table, column string) error {
+	query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s`, table, column, column, table)
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("falha ao ajustar a sequência de %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// jsonArray junta objetos JSON em um array, passado como texto (compatível com o modo PgBouncer).
+func jsonArray(rows []json.RawMessage) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(row)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
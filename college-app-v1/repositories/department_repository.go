@@ -0,0 +1,149 @@
+// repositories/department_repository.go
+package repositories
+
+import (
+	"college-app-v1/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// DepartmentRepository gerencia a persistência de departamentos.
+type DepartmentRepository struct {
+	db     DBTX
+	logger *slog.Logger
+}
+
+// NewDepartmentRepository cria uma nova instância de DepartmentRepository.
+func NewDepartmentRepository(db *sql.DB, logger *slog.Logger) *DepartmentRepository {
+	return &DepartmentRepository{db: instrument("departments", db), logger: logger}
+}
+
+// WithTx retorna uma cópia do repositório que executa as operações na transação tx.
+func (r *DepartmentRepository) WithTx(tx *sql.Tx) *DepartmentRepository {
+	return &DepartmentRepository{db: instrument("departments", tx), logger: r.logger}
+}
+
+// departmentColumns são as colunas lidas por scanDepartment (d = departments, h = chefe).
+const departmentColumns = `d.id, d.code, d.name, COALESCE(d.head_teacher_id, ''), COALESCE(h.name, ''), d.version
+	FROM departments d LEFT JOIN teachers h ON h.id = d.head_teacher_id`
+
+func scanDepartment(row interface{ Scan(...interface{}) error }, department *models.Department) error {
+	return row.Scan(&department.ID, &department.Code, &department.Name, &department.HeadTeacherID, &department.HeadTeacherName, &department.Version)
+}
+
+// CreateDepartment insere um novo departamento.
+func (r *DepartmentRepository) CreateDepartment(ctx context.Context, department *models.Department) error {
+	department.ID = uuid.New().String()
+	query := `INSERT INTO departments (id, code, name, head_teacher_id) VALUES ($1, $2, $3, NULLIF($4, '')) RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, department.ID, department.Code, department.Name, department.HeadTeacherID).Scan(&department.Version)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao inserir departamento", "department_code", department.Code, "error", err)
+		return fmt.Errorf("falha ao criar departamento: %w", err)
+	}
+	r.logger.DebugContext(ctx, "departamento criado", "department_id", department.ID, "department_code", department.Code)
+	return nil
+}
+
+// CodeOrNameTaken informa se outro departamento (diferente de exceptID) já usa o código ou o nome.
+func (r *DepartmentRepository) CodeOrNameTaken(ctx context.Context, code, name, exceptID string) (codeTaken, nameTaken bool, err error) {
+	query := `SELECT
+		EXISTS (SELECT 1 FROM departments WHERE code = $1 AND id <> $3),
+		EXISTS (SELECT 1 FROM departments WHERE LOWER(name) = LOWER($2) AND id <> $3)`
+	if err := r.db.QueryRowContext(ctx, query, code, name, exceptID).Scan(&codeTaken, &nameTaken); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao verificar código e nome de departamento", "department_code", code, "error", err)
+		return false, false, fmt.Errorf("falha ao verificar departamento: %w", err)
+	}
+	return codeTaken, nameTaken, nil
+}
+
+// GetDepartmentByID busca um departamento pelo ID.
+func (r *DepartmentRepository) GetDepartmentByID(ctx context.Context, id string) (*models.Department, error) {
+	department := &models.Department{}
+	err := scanDepartment(r.db.QueryRowContext(ctx, `SELECT `+departmentColumns+` WHERE d.id = $1`, id), department)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.DebugContext(ctx, "departamento não encontrado", "department_id", id)
+			return nil, fmt.Errorf("departamento não encontrado")
+		}
+		r.logger.ErrorContext(ctx, "erro ao buscar departamento", "department_id", id, "error", err)
+		return nil, fmt.Errorf("falha ao buscar departamento por ID: %w", err)
+	}
+	return department, nil
+}
+
+// FindDepartment busca um departamento pelo ID, pelo código ou pelo nome (sem diferenciar
+// maiúsculas), na ordem de preferência.
+func (r *DepartmentRepository) FindDepartment(ctx context.Context, value string) (*models.Department, error) {
+	department := &models.Department{}
+	query := `SELECT ` + departmentColumns + `
+	WHERE d.id = $1 OR LOWER(d.code) = LOWER($1) OR LOWER(d.name) = LOWER($1)
+	ORDER BY d.id = $1 DESC, LOWER(d.code) = LOWER($1) DESC LIMIT 1`
+	err := scanDepartment(r.db.QueryRowContext(ctx, query, value), department)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("departamento não encontrado")
+		}
+		r.logger.ErrorContext(ctx, "erro ao buscar departamento", "department", value, "error", err)
+		return nil, fmt.Errorf("falha ao buscar departamento: %w", err)
+	}
+	return department, nil
+}
+
+// GetAllDepartments busca todos os departamentos, ordenados pelo nome.
+func (r *DepartmentRepository) GetAllDepartments(ctx context.Context) ([]models.Department, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+departmentColumns+` ORDER BY d.name`)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao listar departamentos", "error", err)
+		return nil, fmt.Errorf("falha ao buscar departamentos: %w", err)
+	}
+	defer rows.Close()
+
+	departments := []models.Department{}
+	for rows.Next() {
+		var department models.Department
+		if err := scanDepartment(rows, &department); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados do departamento: %w", err)
+		}
+		departments = append(departments, department)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de departamentos: %w", err)
+	}
+	return departments, nil
+}
+
+// UpdateDepartment atualiza código, nome e chefe, desde que department.Version seja a versão
+// atual. Em caso de sucesso, department.Version recebe a nova versão.
+func (r *DepartmentRepository) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	query := `UPDATE departments SET code = $1, name = $2, head_teacher_id = NULLIF($3, ''), version = version + 1
+	WHERE id = $4 AND version = $5 RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, department.Code, department.Name, department.HeadTeacherID, department.ID, department.Version).Scan(&department.Version)
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM departments WHERE id = $1)`, department.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("falha ao verificar versão do departamento: %w", err)
+		}
+		if exists {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("departamento não encontrado para atualização")
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar departamento", "department_id", department.ID, "error", err)
+		return fmt.Errorf("falha ao atualizar departamento: %w", err)
+	}
+	r.logger.DebugContext(ctx, "departamento atualizado", "department_id", department.ID, "version", department.Version)
+	return nil
+}
+
+// ClearHeadTeacher remove o professor da chefia de departamentos diferentes de departmentID
+// (usado quando o professor muda de departamento). Retorna os IDs dos departamentos alterados.
+func (r *DepartmentRepository) ClearHeadTeacher(ctx context.Context, teacherID, departmentID string) ([]string, error) {
+	query := `UPDATE departments SET head_teacher_id = NULL, version = version + 1
+	WHERE head_teacher_id = $1 AND id <> $2 RETURNING id`
+	return queryIDs(ctx, r.db, r.logger, "ClearHeadTeacher", query, teacherID, departmentID)
+}
@@ -0,0 +1,38 @@
+// repositories/health_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// HealthRepository consulta o estado do banco para as verificações de prontidão.
+type HealthRepository struct {
+	db *sql.DB
+}
+
+// NewHealthRepository cria uma nova instância de HealthRepository.
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// Ping verifica se o banco responde.
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// SchemaVersion retorna a versão do esquema gravada em schema_version, ou 0 se o esquema
+// ainda não foi criado.
+func (r *HealthRepository) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version)
+	if err == sql.ErrNoRows || (err != nil && strings.Contains(err.Error(), "does not exist")) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("falha ao consultar versão do esquema: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,85 @@
+// repositories/instrumented.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"college-app-v1/metrics"
+	"college-app-v1/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedDB envolve um DBTX medindo a duração de cada consulta em
+// college_db_query_duration_seconds, por repositório e comando SQL, e criando um span
+// com o texto da consulta (sem os argumentos). Em QueryContext, a medição cobre a execução
+// da consulta, não a leitura das linhas.
+type instrumentedDB struct {
+	db         DBTX
+	repository string
+}
+
+// instrument retorna db instrumentado com o nome do repositório (ex: "students").
+func instrument(repository string, db DBTX) DBTX {
+	return &instrumentedDB{db: db, repository: repository}
+}
+
+func (i *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := i.start(ctx, query)
+	result, err := i.db.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (i *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := i.start(ctx, query)
+	rows, err := i.db.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (i *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := i.start(ctx, query)
+	row := i.db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+// start abre o span da consulta; done registra a duração e encerra o span com o erro, se houver.
+// sql.ErrNoRows não é tratado como erro: é o resultado esperado de buscas sem registro.
+func (i *instrumentedDB) start(ctx context.Context, query string) (context.Context, func(err error)) {
+	operation := queryOperation(query)
+	begin := time.Now()
+	ctx, span := tracing.Start(ctx, operation+" "+i.repository,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(i.repository),
+			semconv.DBQueryText(query),
+		),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveQuery(i.repository, operation, begin)
+		if err != nil && err != sql.ErrNoRows {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// queryOperation retorna o comando SQL da consulta em minúsculas (select, insert, update,
+// delete, with...), mantendo a cardinalidade do rótulo baixa.
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,53 @@
+// repositories/patch.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// updateColumns atualiza apenas as colunas presentes em changes (coluna -> novo valor) de um
+// registro ativo, desde que version seja a versão atual, e retorna a nova versão.
+// Colunas fora de allowed são recusadas; table e allowed vêm sempre de constantes internas.
+func updateColumns(ctx context.Context, db DBTX, table string, allowed map[string]bool, id string, version int, changes map[string]interface{}, notFound error) (int, error) {
+	columns := changedColumns(changes) // Ordem determinística, útil para logs e cache de planos
+	for _, column := range columns {
+		if !allowed[column] {
+			return 0, fmt.Errorf("coluna %q não pode ser alterada em %s", column, table)
+		}
+	}
+
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+2)
+	for i, column := range columns {
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, i+1))
+		args = append(args, changes[column])
+	}
+	assignments = append(assignments, "version = version + 1")
+	args = append(args, id, version)
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d AND version = $%d AND deleted_at IS NULL RETURNING version`,
+		table, strings.Join(assignments, ", "), len(columns)+1, len(columns)+2)
+	var newVersion int
+	err := db.QueryRowContext(ctx, query, args...).Scan(&newVersion)
+	if err == sql.ErrNoRows {
+		return 0, checkVersionConflict(ctx, db, table, id, notFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("falha ao atualizar colunas de %s: %w", table, err)
+	}
+	return newVersion, nil
+}
+
+// changedColumns retorna as colunas de changes em ordem alfabética.
+func changedColumns(changes map[string]interface{}) []string {
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,299 @@
+// repositories/program_repository.go
+package repositories
+
+import (
+	"college-app-v1/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// ProgramRepository gerencia a persistência de cursos e de suas grades curriculares.
+type ProgramRepository struct {
+	db     DBTX
+	logger *slog.Logger
+}
+
+// NewProgramRepository cria uma nova instância de ProgramRepository.
+func NewProgramRepository(db *sql.DB, logger *slog.Logger) *ProgramRepository {
+	return &ProgramRepository{db: instrument("programs", db), logger: logger}
+}
+
+// WithTx retorna uma cópia do repositório que executa as operações na transação tx.
+func (r *ProgramRepository) WithTx(tx *sql.Tx) *ProgramRepository {
+	return &ProgramRepository{db: instrument("programs", tx), logger: r.logger}
+}
+
+// CreateProgram insere um novo curso.
+func (r *ProgramRepository) CreateProgram(ctx context.Context, program *models.Program) error {
+	program.ID = uuid.New().String()
+	query := `INSERT INTO programs (id, code, name, use_code_in_enrollment) VALUES ($1, $2, $3, $4) RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, program.ID, program.Code, program.Name, program.UseCodeInEnrollment).Scan(&program.Version)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao inserir curso", "program_code", program.Code, "error", err)
+		return fmt.Errorf("falha ao criar curso: %w", err)
+	}
+	r.logger.DebugContext(ctx, "curso criado", "program_id", program.ID, "program_code", program.Code)
+	return nil
+}
+
+// CodeExists informa se algum curso já usa o código (a coluna é única).
+func (r *ProgramRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM programs WHERE code = $1)`, code).Scan(&exists)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao verificar código de curso", "program_code", code, "error", err)
+		return false, fmt.Errorf("falha ao verificar código de curso: %w", err)
+	}
+	return exists, nil
+}
+
+// GetProgramByID busca um curso pelo ID.
+func (r *ProgramRepository) GetProgramByID(ctx context.Context, id string) (*models.Program, error) {
+	program := &models.Program{}
+	query := `SELECT id, code, name, use_code_in_enrollment, version FROM programs WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&program.ID, &program.Code, &program.Name, &program.UseCodeInEnrollment, &program.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.DebugContext(ctx, "curso não encontrado", "program_id", id)
+			return nil, fmt.Errorf("curso não encontrado")
+		}
+		r.logger.ErrorContext(ctx, "erro ao buscar curso", "program_id", id, "error", err)
+		return nil, fmt.Errorf("falha ao buscar curso por ID: %w", err)
+	}
+	return program, nil
+}
+
+// GetAllPrograms busca todos os cursos, ordenados pelo nome.
+func (r *ProgramRepository) GetAllPrograms(ctx context.Context) ([]models.Program, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, code, name, use_code_in_enrollment, version FROM programs ORDER BY name`)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao listar cursos", "error", err)
+		return nil, fmt.Errorf("falha ao buscar cursos: %w", err)
+	}
+	defer rows.Close()
+
+	programs := []models.Program{}
+	for rows.Next() {
+		var program models.Program
+		if err := rows.Scan(&program.ID, &program.Code, &program.Name, &program.UseCodeInEnrollment, &program.Version); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados do curso: %w", err)
+		}
+		programs = append(programs, program)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de cursos: %w", err)
+	}
+	return programs, nil
+}
+
+// UpdateProgram atualiza nome, código e uso do código na matrícula, desde que program.Version
+// seja a versão atual. Em caso de sucesso, program.Version recebe a nova versão.
+func (r *ProgramRepository) UpdateProgram(ctx context.Context, program *models.Program) error {
+	query := `UPDATE programs SET code = $1, name = $2, use_code_in_enrollment = $3, version = version + 1
+	WHERE id = $4 AND version = $5 RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, program.Code, program.Name, program.UseCodeInEnrollment, program.ID, program.Version).Scan(&program.Version)
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM programs WHERE id = $1)`, program.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("falha ao verificar versão do curso: %w", err)
+		}
+		if exists {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("curso não encontrado para atualização")
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar curso", "program_id", program.ID, "error", err)
+		return fmt.Errorf("falha ao atualizar curso: %w", err)
+	}
+	r.logger.DebugContext(ctx, "curso atualizado", "program_id", program.ID, "version", program.Version)
+	return nil
+}
+
+// CreateCurriculum grava uma nova versão da grade do curso (número seguinte à maior existente)
+// com suas matérias. Deve ser chamado em uma transação (WithTx) para gravar a grade inteira ou nada;
+// a restrição UNIQUE (program_id, version) rejeita versões criadas em paralelo.
+func (r *ProgramRepository) CreateCurriculum(ctx context.Context, curriculum *models.Curriculum) error {
+	curriculum.ID = uuid.New().String()
+	query := `
+	INSERT INTO curricula (id, program_id, version, description, required_credits)
+	SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4 FROM curricula WHERE program_id = $2
+	RETURNING version, created_at`
+	err := r.db.QueryRowContext(ctx, query, curriculum.ID, curriculum.ProgramID, curriculum.Description, curriculum.RequiredCredits).Scan(&curriculum.Version, &curriculum.CreatedAt)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao criar grade curricular", "program_id", curriculum.ProgramID, "error", err)
+		return fmt.Errorf("falha ao criar grade curricular: %w", err)
+	}
+
+	for _, bucket := range curriculum.ElectiveBuckets {
+		_, err := r.db.ExecContext(ctx, `INSERT INTO curriculum_elective_buckets (curriculum_id, name, min_credits) VALUES ($1, $2, $3)`,
+			curriculum.ID, bucket.Name, bucket.MinCredits)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "erro ao adicionar grupo de eletivas à grade", "curriculum_id", curriculum.ID, "bucket", bucket.Name, "error", err)
+			return fmt.Errorf("falha ao adicionar grupo de eletivas à grade curricular: %w", err)
+		}
+	}
+	for _, subject := range curriculum.Subjects {
+		_, err := r.db.ExecContext(ctx, `INSERT INTO curriculum_subjects (curriculum_id, subject_id, year, mandatory, bucket) VALUES ($1, $2, $3, $4, NULLIF($5, ''))`,
+			curriculum.ID, subject.SubjectID, subject.Year, subject.Mandatory, subject.Bucket)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "erro ao adicionar matéria à grade", "curriculum_id", curriculum.ID, "subject_id", subject.SubjectID, "error", err)
+			return fmt.Errorf("falha ao adicionar matéria à grade curricular: %w", err)
+		}
+	}
+	r.logger.DebugContext(ctx, "grade curricular criada", "program_id", curriculum.ProgramID, "curriculum_id", curriculum.ID, "version", curriculum.Version, "subjects", len(curriculum.Subjects))
+	return nil
+}
+
+// GetCurriculumByID busca uma versão da grade com suas matérias (incluindo as excluídas
+// depois da criação da grade, que continuam fazendo parte do histórico).
+func (r *ProgramRepository) GetCurriculumByID(ctx context.Context, id string) (*models.Curriculum, error) {
+	curriculum := &models.Curriculum{}
+	query := `SELECT id, program_id, version, description, required_credits, created_at FROM curricula WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&curriculum.ID, &curriculum.ProgramID, &curriculum.Version, &curriculum.Description, &curriculum.RequiredCredits, &curriculum.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.logger.DebugContext(ctx, "grade curricular não encontrada", "curriculum_id", id)
+			return nil, fmt.Errorf("grade curricular não encontrada")
+		}
+		r.logger.ErrorContext(ctx, "erro ao buscar grade curricular", "curriculum_id", id, "error", err)
+		return nil, fmt.Errorf("falha ao buscar grade curricular por ID: %w", err)
+	}
+
+	buckets, err := r.getElectiveBuckets(ctx, curriculum.ID)
+	if err != nil {
+		return nil, err
+	}
+	curriculum.ElectiveBuckets = buckets
+	subjects, err := r.getCurriculumSubjects(ctx, curriculum.ID)
+	if err != nil {
+		return nil, err
+	}
+	curriculum.Subjects = subjects
+	return curriculum, nil
+}
+
+// GetCurriculaByProgramID busca as versões da grade de um curso (mais recente primeiro), sem as matérias.
+func (r *ProgramRepository) GetCurriculaByProgramID(ctx context.Context, programID string) ([]models.Curriculum, error) {
+	query := `SELECT id, program_id, version, description, required_credits, created_at FROM curricula WHERE program_id = $1 ORDER BY version DESC`
+	rows, err := r.db.QueryContext(ctx, query, programID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar grades do curso", "program_id", programID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar grades curriculares: %w", err)
+	}
+	defer rows.Close()
+
+	curricula := []models.Curriculum{}
+	for rows.Next() {
+		var curriculum models.Curriculum
+		if err := rows.Scan(&curriculum.ID, &curriculum.ProgramID, &curriculum.Version, &curriculum.Description, &curriculum.RequiredCredits, &curriculum.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados da grade curricular: %w", err)
+		}
+		curricula = append(curricula, curriculum)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de grades curriculares: %w", err)
+	}
+	return curricula, nil
+}
+
+// GetLatestCurriculumID retorna o ID da versão mais recente da grade do curso, ou "" se não houver.
+func (r *ProgramRepository) GetLatestCurriculumID(ctx context.Context, programID string) (string, error) {
+	var id string
+	query := `SELECT id FROM curricula WHERE program_id = $1 ORDER BY version DESC LIMIT 1`
+	err := r.db.QueryRowContext(ctx, query, programID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar grade mais recente do curso", "program_id", programID, "error", err)
+		return "", fmt.Errorf("falha ao buscar grade curricular mais recente: %w", err)
+	}
+	return id, nil
+}
+
+// GetMandatorySubjectsForYear busca as matérias obrigatórias (não excluídas) de um ano da grade.
+func (r *ProgramRepository) GetMandatorySubjectsForYear(ctx context.Context, curriculumID string, year int) ([]models.Subject, error) {
+	query := `
+	SELECT s.id, s.name, s.year, s.credits, cs.mandatory, s.version, s.deleted_at
+	FROM curriculum_subjects cs
+	JOIN subjects s ON s.id = cs.subject_id AND s.deleted_at IS NULL
+	WHERE cs.curriculum_id = $1 AND cs.year = $2 AND cs.mandatory
+	ORDER BY s.name`
+	rows, err := r.db.QueryContext(ctx, query, curriculumID, year)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias obrigatórias da grade", "curriculum_id", curriculumID, "year", year, "error", err)
+		return nil, fmt.Errorf("falha ao buscar matérias obrigatórias da grade: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.Subject{}
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados da matéria: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matérias: %w", err)
+	}
+	return subjects, nil
+}
+
+// getCurriculumSubjects busca as matérias de uma versão da grade, por ano e nome.
+func (r *ProgramRepository) getCurriculumSubjects(ctx context.Context, curriculumID string) ([]models.CurriculumSubject, error) {
+	query := `
+	SELECT cs.subject_id, s.name, s.credits, cs.year, cs.mandatory, COALESCE(cs.bucket, '')
+	FROM curriculum_subjects cs
+	JOIN subjects s ON s.id = cs.subject_id
+	WHERE cs.curriculum_id = $1
+	ORDER BY cs.year, s.name`
+	rows, err := r.db.QueryContext(ctx, query, curriculumID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias da grade", "curriculum_id", curriculumID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar matérias da grade curricular: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.CurriculumSubject{}
+	for rows.Next() {
+		var subject models.CurriculumSubject
+		if err := rows.Scan(&subject.SubjectID, &subject.Name, &subject.Credits, &subject.Year, &subject.Mandatory, &subject.Bucket); err != nil {
+			return nil, fmt.Errorf("falha ao escanear matéria da grade curricular: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matérias da grade curricular: %w", err)
+	}
+	return subjects, nil
+}
+
+// getElectiveBuckets busca os grupos de eletivas de uma versão da grade, por nome.
+func (r *ProgramRepository) getElectiveBuckets(ctx context.Context, curriculumID string) ([]models.ElectiveBucket, error) {
+	query := `SELECT name, min_credits FROM curriculum_elective_buckets WHERE curriculum_id = $1 ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query, curriculumID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar grupos de eletivas da grade", "curriculum_id", curriculumID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar grupos de eletivas da grade curricular: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []models.ElectiveBucket{}
+	for rows.Next() {
+		var bucket models.ElectiveBucket
+		if err := rows.Scan(&bucket.Name, &bucket.MinCredits); err != nil {
+			return nil, fmt.Errorf("falha ao escanear grupo de eletivas: %w", err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de grupos de eletivas: %w", err)
+	}
+	return buckets, nil
+}
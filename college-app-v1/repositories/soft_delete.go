@@ -0,0 +1,35 @@
+// repositories/soft_delete.go
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// purgeDeleted remove definitivamente as linhas de table excluídas (soft delete) antes de olderThan
+// e retorna os IDs removidos. table vem sempre de constantes internas, nunca de entrada do usuário.
+func purgeDeleted(ctx context.Context, db DBTX, logger *slog.Logger, table string, olderThan time.Time) ([]string, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id`, table)
+	rows, err := db.QueryContext(ctx, query, olderThan)
+	if err != nil {
+		logger.ErrorContext(ctx, "erro ao remover registros excluídos", "table", table, "error", err)
+		return nil, fmt.Errorf("falha ao remover registros excluídos de %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("falha ao escanear ID removido de %s: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de registros removidos de %s: %w", table, err)
+	}
+	logger.InfoContext(ctx, "registros excluídos removidos definitivamente", "table", table, "count", len(ids), "deleted_before", olderThan.Format(time.RFC3339))
+	return ids, nil
+}
@@ -0,0 +1,14 @@
+// repositories/stream.go
+package repositories
+
+import "college-app-v1/models"
+
+// subjectsFromArrays monta as matérias associadas a partir dos arrays agregados (array_agg)
+// de IDs e nomes usados nas consultas de exportação.
+func subjectsFromArrays(ids, names []string) []models.Subject {
+	subjects := make([]models.Subject, len(ids))
+	for i := range ids {
+		subjects[i] = models.Subject{ID: ids[i], Name: names[i]}
+	}
+	return subjects
+}
@@ -3,64 +3,82 @@ package repositories
 
 import (
 	"college-app-v1/models" // Certifique-se de que este caminho está correto
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrStudentNotFound indica que o aluno não existe (ou está excluído, quando a operação só
+// considera alunos ativos). Os erros de atualização e exclusão o envolvem, com o sufixo da operação.
+var ErrStudentNotFound = errors.New("aluno não encontrado")
+
 // StudentRepository define as operações de CRUD para alunos.
 // A interface será ajustada no service para ser mais clara.
 type StudentRepository struct {
-	db *sql.DB
+	db     DBTX
+	logger *slog.Logger
 }
 
 // NewStudentRepository cria uma nova instância de StudentRepository.
-func NewStudentRepository(db *sql.DB) *StudentRepository {
-	return &StudentRepository{db: db}
+func NewStudentRepository(db *sql.DB, logger *slog.Logger) *StudentRepository {
+	return &StudentRepository{db: instrument("students", db), logger: logger}
+}
+
+// WithTx retorna um StudentRepository que executa as operações na transação tx.
+func (r *StudentRepository) WithTx(tx *sql.Tx) *StudentRepository {
+	return &StudentRepository{db: instrument("students", tx), logger: r.logger}
 }
 
 // CreateStudent insere um novo aluno no banco de dados.
-func (r *StudentRepository) CreateStudent(student *models.Student) error {
+func (r *StudentRepository) CreateStudent(ctx context.Context, student *models.Student) error {
 	student.ID = uuid.New().String() // Gera um ID único para o aluno
-	query := `INSERT INTO students (id, enrollment, name, current_year, shift) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, student.ID, student.Enrollment, student.Name, student.CurrentYear, student.Shift)
+	query := `INSERT INTO students (id, enrollment, name, current_year, shift, program_id, curriculum_id)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')) RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, student.ID, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.ProgramID, student.CurriculumID).Scan(&student.Version)
 	if err != nil {
-		log.Printf("CreateStudent: Erro ao executar INSERT para aluno %s: %v", student.Name, err)
+		r.logger.ErrorContext(ctx, "erro ao inserir aluno", "enrollment", student.Enrollment, "error", err)
 		return fmt.Errorf("falha ao criar aluno: %w", err) // Retorna erro encapsulado
 	}
 
 	// Insere as matérias do aluno na tabela de relacionamento
 	if student.Subjects != nil {
 		for _, subject := range student.Subjects {
-			err := r.AddSubjectToStudent(student.ID, subject.ID) // student.ID é string, subject.ID é string
+			err := r.AddSubjectToStudent(ctx, student.ID, subject.ID) // student.ID é string, subject.ID é string
 			if err != nil {
-				log.Printf("CreateStudent: Aviso - Erro ao adicionar matéria %s ao aluno %s: %v", subject.ID, student.ID, err)
+				r.logger.WarnContext(ctx, "erro ao associar matéria ao aluno criado", "student_id", student.ID, "subject_id", subject.ID, "error", err)
 			}
 		}
 	}
-	log.Printf("CreateStudent: Aluno %s (%s) criado com sucesso. Matrícula: %s", student.Name, student.ID, student.Enrollment)
+	r.logger.DebugContext(ctx, "aluno criado", "student_id", student.ID, "enrollment", student.Enrollment)
 	return nil
 }
 
-// GetStudentByID busca um aluno pelo ID.
-func (r *StudentRepository) GetStudentByID(id string) (*models.Student, error) {
+// GetStudentByID busca um aluno pelo ID. Alunos excluídos (soft delete) só são retornados com includeDeleted.
+func (r *StudentRepository) GetStudentByID(ctx context.Context, id string, includeDeleted bool) (*models.Student, error) {
 	student := &models.Student{}
-	query := `SELECT id, enrollment, name, current_year, shift FROM students WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift)
+	query := `SELECT id, enrollment, name, current_year, shift, COALESCE(program_id, ''), COALESCE(curriculum_id, ''), version, deleted_at FROM students WHERE id = $1`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.ProgramID, &student.CurriculumID, &student.Version, &student.DeletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetStudentByID: Aluno com ID %s não encontrado no DB.", id)
-			return nil, fmt.Errorf("aluno não encontrado") // Retorna erro específico
+			r.logger.DebugContext(ctx, "aluno não encontrado", "student_id", id)
+			return nil, ErrStudentNotFound
 		}
-		log.Printf("GetStudentByID: Erro ao escanear dados do aluno com ID %s: %v", id, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar aluno", "student_id", id, "error", err)
 		return nil, fmt.Errorf("falha ao buscar aluno por ID: %w", err) // Retorna erro encapsulado
 	}
 
-	subjects, err := r.GetSubjectsByStudentID(student.ID)
+	subjects, err := r.GetSubjectsByStudentID(ctx, student.ID)
 	if err != nil {
-		log.Printf("GetStudentByID: Erro ao buscar matérias para o aluno %s (ID: %s): %v", student.Name, student.ID, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias do aluno", "student_id", student.ID, "error", err)
 		return nil, fmt.Errorf("falha ao buscar matérias associadas: %w", err)
 	}
 	if subjects == nil {
@@ -68,15 +86,19 @@ func (r *StudentRepository) GetStudentByID(id string) (*models.Student, error) {
 	} else {
 		student.Subjects = subjects
 	}
-	log.Printf("GetStudentByID: Aluno '%s' (ID: %s) encontrado com sucesso, %d matérias.", student.Name, student.ID, len(student.Subjects))
+	r.logger.DebugContext(ctx, "aluno encontrado", "student_id", student.ID, "subjects", len(student.Subjects))
 	return student, nil
 }
 
 // GetAllStudents busca todos os alunos, com opções de filtro.
 // year: ponteiro para int para permitir nil (sem filtro de ano)
 // shift: string para o turno (vazio significa sem filtro de turno)
-func (r *StudentRepository) GetAllStudents(year *int, shift string) ([]models.Student, error) {
-	baseQuery := `SELECT id, enrollment, name, current_year, shift FROM students WHERE 1=1`
+// includeDeleted: incluir alunos excluídos (soft delete)
+func (r *StudentRepository) GetAllStudents(ctx context.Context, year *int, shift string, includeDeleted bool) ([]models.Student, error) {
+	baseQuery := `SELECT id, enrollment, name, current_year, shift, COALESCE(program_id, ''), COALESCE(curriculum_id, ''), version, deleted_at FROM students WHERE 1=1`
+	if !includeDeleted {
+		baseQuery += ` AND deleted_at IS NULL`
+	}
 	args := []interface{}{}
 	argCounter := 1
 
@@ -94,9 +116,9 @@ func (r *StudentRepository) GetAllStudents(year *int, shift string) ([]models.St
 		argCounter++
 	}
 
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
 	if err != nil {
-		log.Printf("GetAllStudents: Erro ao executar query com filtros '%s' %v: %v", baseQuery, args, err)
+		r.logger.ErrorContext(ctx, "erro ao listar alunos", "year", year, "shift", shift, "error", err)
 		return nil, fmt.Errorf("falha ao buscar alunos com filtros: %w", err)
 	}
 	defer rows.Close()
@@ -104,14 +126,14 @@ func (r *StudentRepository) GetAllStudents(year *int, shift string) ([]models.St
 	var students []models.Student
 	for rows.Next() {
 		student := models.Student{}
-		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift); err != nil {
-			log.Printf("GetAllStudents: Erro ao escanear linha de aluno do DB: %v", err)
+		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.ProgramID, &student.CurriculumID, &student.Version, &student.DeletedAt); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao escanear aluno", "error", err)
 			return nil, fmt.Errorf("falha ao escanear dados do aluno: %w", err)
 		}
 		// Buscar matérias para cada aluno (mantendo o N+1 por enquanto)
-		subjects, err := r.GetSubjectsByStudentID(student.ID)
+		subjects, err := r.GetSubjectsByStudentID(ctx, student.ID)
 		if err != nil {
-			log.Printf("GetAllStudents: Erro ao buscar matérias para aluno %s (ID: %s) durante iteração: %v", student.Name, student.ID, err)
+			r.logger.ErrorContext(ctx, "erro ao buscar matérias do aluno", "student_id", student.ID, "error", err)
 			// Decide como lidar com este erro. Pode ser fatal ou apenas logar e continuar.
 			// Por enquanto, vamos retornar o erro, mas em um cenário real você poderia logar
 			// e talvez retornar um erro específico para o cliente se matérias forem cruciais.
@@ -129,68 +151,167 @@ func (r *StudentRepository) GetAllStudents(year *int, shift string) ([]models.St
 		return nil, fmt.Errorf("erro durante iteração de alunos: %w", err)
 	}
 
-	log.Printf("GetAllStudents: %d alunos encontrados e processados com filtros (Ano: %v, Turno: %s).", len(students), year, shift)
+	r.logger.DebugContext(ctx, "alunos listados", "count", len(students), "year", year, "shift", shift)
 	return students, nil
 }
 
-// UpdateStudent atualiza um aluno existente.
-func (r *StudentRepository) UpdateStudent(student *models.Student) error {
-	query := `UPDATE students SET enrollment = $1, name = $2, current_year = $3, shift = $4 WHERE id = $5`
-	result, err := r.db.Exec(query, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.ID)
+// StreamStudents percorre os alunos com os mesmos filtros de GetAllStudents, chamando fn para
+// cada um sem carregar a lista inteira em memória (usado na exportação). As matérias associadas
+// vêm na mesma consulta, apenas com ID e nome. Se fn retornar erro, a iteração é interrompida.
+func (r *StudentRepository) StreamStudents(ctx context.Context, year *int, shift string, includeDeleted bool, fn func(*models.Student) error) error {
+	baseQuery := `
+	SELECT s.id, s.enrollment, s.name, s.current_year, s.shift, COALESCE(s.program_id, ''), COALESCE(s.curriculum_id, ''), s.version, s.deleted_at,
+		COALESCE(array_agg(sub.id ORDER BY sub.name) FILTER (WHERE sub.id IS NOT NULL), '{}'),
+		COALESCE(array_agg(sub.name ORDER BY sub.name) FILTER (WHERE sub.id IS NOT NULL), '{}')
+	FROM students s
+	LEFT JOIN student_subjects ss ON ss.student_id = s.id
+	LEFT JOIN subjects sub ON sub.id = ss.subject_id AND sub.deleted_at IS NULL
+	WHERE 1=1`
+	if !includeDeleted {
+		baseQuery += ` AND s.deleted_at IS NULL`
+	}
+	args := []interface{}{}
+	argCounter := 1
+	if year != nil {
+		baseQuery += fmt.Sprintf(" AND s.current_year = $%d", argCounter)
+		args = append(args, *year)
+		argCounter++
+	}
+	if shift != "" {
+		baseQuery += fmt.Sprintf(" AND LOWER(s.shift) = LOWER($%d)", argCounter)
+		args = append(args, shift)
+		argCounter++
+	}
+	baseQuery += ` GROUP BY s.id ORDER BY s.name, s.id`
+
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar alunos para exportação", "year", year, "shift", shift, "error", err)
+		return fmt.Errorf("falha ao buscar alunos para exportação: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var student models.Student
+		var subjectIDs, subjectNames []string
+		if err := rows.Scan(&student.ID, &student.Enrollment, &student.Name, &student.CurrentYear, &student.Shift, &student.ProgramID, &student.CurriculumID, &student.Version, &student.DeletedAt,
+			pq.Array(&subjectIDs), pq.Array(&subjectNames)); err != nil {
+			return fmt.Errorf("falha ao escanear dados do aluno: %w", err)
+		}
+		student.Subjects = subjectsFromArrays(subjectIDs, subjectNames)
+		if err := fn(&student); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro durante iteração de alunos: %w", err)
+	}
+	r.logger.DebugContext(ctx, "alunos exportados", "count", count, "year", year, "shift", shift)
+	return nil
+}
+
+// UpdateStudent atualiza um aluno existente, desde que student.Version seja a versão atual no banco.
+// Em caso de sucesso, student.Version recebe a nova versão; se outra requisição alterou o aluno
+// antes, retorna ErrVersionConflict.
+func (r *StudentRepository) UpdateStudent(ctx context.Context, student *models.Student) error {
+	query := `UPDATE students SET enrollment = $1, name = $2, current_year = $3, shift = $4, version = version + 1
+	WHERE id = $5 AND version = $6 AND deleted_at IS NULL RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, student.Enrollment, student.Name, student.CurrentYear, student.Shift, student.ID, student.Version).Scan(&student.Version)
+	if err == sql.ErrNoRows {
+		r.logger.DebugContext(ctx, "aluno não encontrado na versão para atualização", "student_id", student.ID, "version", student.Version)
+		return checkVersionConflict(ctx, r.db, "students", student.ID, fmt.Errorf("%w para atualização", ErrStudentNotFound))
+	}
 	if err != nil {
-		log.Printf("UpdateStudent: Erro ao executar UPDATE para aluno %s (ID: %s): %v", student.Name, student.ID, err)
+		r.logger.ErrorContext(ctx, "erro ao atualizar aluno", "student_id", student.ID, "error", err)
 		return fmt.Errorf("falha ao atualizar aluno: %w", err)
 	}
+	r.logger.DebugContext(ctx, "aluno atualizado", "student_id", student.ID, "version", student.Version)
+	return nil
+}
+
+// patchableStudentColumns são as colunas de students que PatchStudent pode alterar.
+var patchableStudentColumns = map[string]bool{"name": true, "current_year": true, "shift": true, "program_id": true, "curriculum_id": true}
+
+// PatchStudent atualiza apenas as colunas em changes (coluna -> novo valor) de um aluno ativo,
+// desde que version seja a versão atual, e retorna a nova versão. Se outra requisição alterou
+// o aluno antes, retorna ErrVersionConflict.
+func (r *StudentRepository) PatchStudent(ctx context.Context, id string, version int, changes map[string]interface{}) (int, error) {
+	newVersion, err := updateColumns(ctx, r.db, "students", patchableStudentColumns, id, version, changes, fmt.Errorf("%w para atualização", ErrStudentNotFound))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar colunas do aluno", "student_id", id, "columns", changedColumns(changes), "version", version, "error", err)
+		return 0, err
+	}
+	r.logger.DebugContext(ctx, "aluno atualizado", "student_id", id, "columns", changedColumns(changes), "version", newVersion)
+	return newVersion, nil
+}
+
+// DeleteStudent marca um aluno como excluído (soft delete), preservando suas associações.
+// version deve ser a versão atual do aluno; caso contrário retorna ErrVersionConflict.
+func (r *StudentRepository) DeleteStudent(ctx context.Context, id string, version int) error {
+	query := `UPDATE students SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao excluir aluno", "student_id", id, "error", err)
+		return fmt.Errorf("falha ao deletar aluno: %w", err)
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após atualização: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("UpdateStudent: Nenhum aluno encontrado para atualizar com ID %s.", student.ID)
-		return fmt.Errorf("aluno não encontrado para atualização") // Retorna erro mais descritivo
+		r.logger.DebugContext(ctx, "aluno não encontrado na versão para exclusão", "student_id", id, "version", version)
+		return checkVersionConflict(ctx, r.db, "students", id, fmt.Errorf("%w para exclusão", ErrStudentNotFound))
 	}
-	log.Printf("UpdateStudent: Aluno %s (ID: %s) atualizado com sucesso.", student.Name, student.ID)
+	r.logger.DebugContext(ctx, "aluno excluído", "student_id", id)
 	return nil
 }
 
-// DeleteStudent deleta um aluno pelo ID.
-func (r *StudentRepository) DeleteStudent(id string) error {
-	query := `DELETE FROM students WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+// RestoreStudent desfaz a exclusão (soft delete) de um aluno.
+func (r *StudentRepository) RestoreStudent(ctx context.Context, id string) error {
+	query := `UPDATE students SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		log.Printf("DeleteStudent: Erro ao executar DELETE para aluno ID %s: %v", id, err)
-		return fmt.Errorf("falha ao deletar aluno: %w", err)
+		r.logger.ErrorContext(ctx, "erro ao restaurar aluno", "student_id", id, "error", err)
+		return fmt.Errorf("falha ao restaurar aluno: %w", err)
 	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após restauração: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("DeleteStudent: Nenhum aluno encontrado para deletar com ID %s.", id)
-		return fmt.Errorf("aluno não encontrado para exclusão") // Retorna erro mais descritivo
+		r.logger.DebugContext(ctx, "aluno excluído não encontrado para restauração", "student_id", id)
+		return fmt.Errorf("aluno excluído não encontrado para restauração")
 	}
-	log.Printf("DeleteStudent: Aluno com ID %s deletado com sucesso.", id)
+	r.logger.DebugContext(ctx, "aluno restaurado", "student_id", id)
 	return nil
 }
 
+// PurgeDeletedStudents remove definitivamente alunos excluídos antes de olderThan,
+// junto com suas associações (ON DELETE CASCADE). Retorna os IDs removidos.
+func (r *StudentRepository) PurgeDeletedStudents(ctx context.Context, olderThan time.Time) ([]string, error) {
+	return purgeDeleted(ctx, r.db, r.logger, "students", olderThan)
+}
+
 // AddSubjectToStudent associa uma matéria a um aluno.
-func (r *StudentRepository) AddSubjectToStudent(studentID, subjectID string) error {
+func (r *StudentRepository) AddSubjectToStudent(ctx context.Context, studentID, subjectID string) error {
 	query := `INSERT INTO student_subjects (student_id, subject_id) VALUES ($1, $2) ON CONFLICT (student_id, subject_id) DO NOTHING`
-	_, err := r.db.Exec(query, studentID, subjectID)
+	_, err := r.db.ExecContext(ctx, query, studentID, subjectID)
 	if err != nil {
-		log.Printf("AddSubjectToStudent: Erro ao executar INSERT para associação aluno %s - matéria %s: %v", studentID, subjectID, err)
+		r.logger.ErrorContext(ctx, "erro ao associar matéria ao aluno", "student_id", studentID, "subject_id", subjectID, "error", err)
 		return fmt.Errorf("falha ao associar matéria ao aluno: %w", err)
 	}
-	log.Printf("AddSubjectToStudent: Associação aluno %s - matéria %s criada/existente.", studentID, subjectID)
+	r.logger.DebugContext(ctx, "matéria associada ao aluno", "student_id", studentID, "subject_id", subjectID)
 	return nil
 }
 
 // RemoveSubjectFromStudent desassocia uma matéria de um aluno.
-func (r *StudentRepository) RemoveSubjectFromStudent(studentID, subjectID string) error {
+func (r *StudentRepository) RemoveSubjectFromStudent(ctx context.Context, studentID, subjectID string) error {
 	query := `DELETE FROM student_subjects WHERE student_id = $1 AND subject_id = $2`
-	result, err := r.db.Exec(query, studentID, subjectID)
+	result, err := r.db.ExecContext(ctx, query, studentID, subjectID)
 	if err != nil {
-		log.Printf("RemoveSubjectFromStudent: Erro ao executar DELETE para associação aluno %s - matéria %s: %v", studentID, subjectID, err)
+		r.logger.ErrorContext(ctx, "erro ao desassociar matéria do aluno", "student_id", studentID, "subject_id", subjectID, "error", err)
 		return fmt.Errorf("falha ao desassociar matéria do aluno: %w", err)
 	}
 	rowsAffected, err := result.RowsAffected()
@@ -198,51 +319,191 @@ func (r *StudentRepository) RemoveSubjectFromStudent(studentID, subjectID string
 		return fmt.Errorf("falha ao verificar linhas afetadas após desassociação: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("RemoveSubjectFromStudent: Associação aluno %s - matéria %s não encontrada para deletar.", studentID, subjectID)
+		r.logger.DebugContext(ctx, "associação aluno-matéria não encontrada", "student_id", studentID, "subject_id", subjectID)
 		return fmt.Errorf("associação não encontrada para desassociação") // Retorna erro mais descritivo
 	}
-	log.Printf("RemoveSubjectFromStudent: Associação aluno %s - matéria %s deletada com sucesso.", studentID, subjectID)
+	r.logger.DebugContext(ctx, "matéria desassociada do aluno", "student_id", studentID, "subject_id", subjectID)
+	return nil
+}
+
+// GetSubjectStatuses retorna a situação do aluno em cada matéria associada (ID da matéria -> status).
+func (r *StudentRepository) GetSubjectStatuses(ctx context.Context, studentID string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT subject_id, status FROM student_subjects WHERE student_id = $1`, studentID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar situações do aluno nas matérias", "student_id", studentID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar situação do aluno nas matérias: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := map[string]string{}
+	for rows.Next() {
+		var subjectID, status string
+		if err := rows.Scan(&subjectID, &status); err != nil {
+			return nil, fmt.Errorf("falha ao escanear situação do aluno na matéria: %w", err)
+		}
+		statuses[subjectID] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de situações: %w", err)
+	}
+	return statuses, nil
+}
+
+// GetSubjectRecords busca as matérias associadas a cada aluno de studentIDs, com créditos e
+// situação (ID do aluno -> matérias), em uma única consulta. Matérias excluídas são incluídas:
+// aprovações anteriores à exclusão continuam valendo.
+func (r *StudentRepository) GetSubjectRecords(ctx context.Context, studentIDs []string) (map[string][]models.StudentSubjectRecord, error) {
+	records := map[string][]models.StudentSubjectRecord{}
+	if len(studentIDs) == 0 {
+		return records, nil
+	}
+	query := `
+	SELECT ss.student_id, s.id, s.name, s.credits, ss.status
+	FROM student_subjects ss
+	JOIN subjects s ON s.id = ss.subject_id
+	WHERE ss.student_id = ANY($1)
+	ORDER BY ss.student_id, s.name`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(studentIDs))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar histórico de matérias dos alunos", "students", len(studentIDs), "error", err)
+		return nil, fmt.Errorf("falha ao buscar histórico de matérias dos alunos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var studentID string
+		var record models.StudentSubjectRecord
+		if err := rows.Scan(&studentID, &record.SubjectID, &record.Name, &record.Credits, &record.Status); err != nil {
+			return nil, fmt.Errorf("falha ao escanear matéria do histórico do aluno: %w", err)
+		}
+		records[studentID] = append(records[studentID], record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração do histórico de matérias: %w", err)
+	}
+	return records, nil
+}
+
+// SetSubjectStatus altera a situação do aluno em uma matéria já associada.
+func (r *StudentRepository) SetSubjectStatus(ctx context.Context, studentID, subjectID, status string) error {
+	query := `UPDATE student_subjects SET status = $3 WHERE student_id = $1 AND subject_id = $2`
+	result, err := r.db.ExecContext(ctx, query, studentID, subjectID, status)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar situação do aluno na matéria", "student_id", studentID, "subject_id", subjectID, "error", err)
+		return fmt.Errorf("falha ao atualizar situação do aluno na matéria: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas após atualização de situação: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("associação não encontrada para atualização de situação")
+	}
+	r.logger.DebugContext(ctx, "situação do aluno na matéria alterada", "student_id", studentID, "subject_id", subjectID, "status", status)
 	return nil
 }
 
 // GetLastEnrollmentForYearAndShift busca a maior matrícula para o ano e turno especificados.
-func (r *StudentRepository) GetLastEnrollmentForYearAndShift(year int, studentShift string) (string, error) {
+// programCode é o prefixo de curso da matrícula (vazio para matrículas sem código de curso).
+func (r *StudentRepository) GetLastEnrollmentForYearAndShift(ctx context.Context, programCode string, year int, studentShift string) (string, error) {
 	var lastEnrollment sql.NullString // Usar sql.NullString para lidar com NULL do DB
 	query := `
 		SELECT enrollment FROM students
-		WHERE enrollment LIKE $1 || $2 || '%'
+		WHERE enrollment LIKE $1 || $2 || $3 || '%'
 		ORDER BY enrollment DESC
 		LIMIT 1
 	`
-	err := r.db.QueryRow(query, fmt.Sprintf("%d", year), studentShift).Scan(&lastEnrollment)
+	err := r.db.QueryRowContext(ctx, query, programCode, fmt.Sprintf("%d", year), studentShift).Scan(&lastEnrollment)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetLastEnrollmentForYearAndShift: Nenhuma matrícula encontrada para o ano %d e turno %s.", year, studentShift)
+			r.logger.DebugContext(ctx, "nenhuma matrícula encontrada para o ano e turno", "program_code", programCode, "year", year, "shift", studentShift)
 			return "", nil // Nenhuma matrícula encontrada para este ano e turno
 		}
-		log.Printf("GetLastEnrollmentForYearAndShift: Erro ao buscar última matrícula para o ano %d e turno %s: %v", year, studentShift, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar última matrícula", "program_code", programCode, "year", year, "shift", studentShift, "error", err)
 		return "", fmt.Errorf("falha ao buscar última matrícula: %w", err)
 	}
 
 	if lastEnrollment.Valid {
-		log.Printf("GetLastEnrollmentForYearAndShift: Última matrícula encontrada para %d%s: %s", year, studentShift, lastEnrollment.String)
+		r.logger.DebugContext(ctx, "última matrícula encontrada", "enrollment", lastEnrollment.String)
 		return lastEnrollment.String, nil
 	}
-	log.Printf("GetLastEnrollmentForYearAndShift: Matrícula nula/inválida para o ano %d e turno %s.", year, studentShift)
+	r.logger.WarnContext(ctx, "matrícula nula para o ano e turno", "program_code", programCode, "year", year, "shift", studentShift)
 	return "", nil // Caso a string seja nula (não deveria acontecer com LIMIT 1)
 }
 
+// LockEnrollments bloqueia a criação e a alteração de alunos por outras transações até o fim da
+// transação atual, para que as matrículas possam ser renumeradas sem concorrência. Exige WithTx.
+func (r *StudentRepository) LockEnrollments(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `LOCK TABLE students IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao bloquear tabela de alunos", "error", err)
+		return fmt.Errorf("falha ao bloquear alunos para renumeração: %w", err)
+	}
+	return nil
+}
+
+// GetEnrollmentRecords lista matrícula, turno e código de curso de todos os alunos, inclusive
+// os excluídos (que continuam ocupando a matrícula), em ordem de matrícula.
+func (r *StudentRepository) GetEnrollmentRecords(ctx context.Context) ([]models.StudentEnrollmentRecord, error) {
+	query := `
+	SELECT s.id, s.name, s.enrollment, s.shift, COALESCE(CASE WHEN p.use_code_in_enrollment THEN p.code END, '')
+	FROM students s
+	LEFT JOIN programs p ON p.id = s.program_id
+	ORDER BY s.enrollment`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matrículas", "error", err)
+		return nil, fmt.Errorf("falha ao buscar matrículas: %w", err)
+	}
+	defer rows.Close()
+
+	var records []models.StudentEnrollmentRecord
+	for rows.Next() {
+		var record models.StudentEnrollmentRecord
+		if err := rows.Scan(&record.ID, &record.Name, &record.Enrollment, &record.Shift, &record.ProgramCode); err != nil {
+			return nil, fmt.Errorf("falha ao escanear matrícula: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matrículas: %w", err)
+	}
+	return records, nil
+}
+
+// ReplaceEnrollments grava novas matrículas (ID do aluno -> matrícula) e incrementa a versão
+// dos alunos alterados. As matrículas antigas são liberadas antes, para que trocas entre alunos
+// não violem a restrição UNIQUE. Deve ser executada em uma transação (WithTx).
+func (r *StudentRepository) ReplaceEnrollments(ctx context.Context, enrollments map[string]string) error {
+	ids := make([]string, 0, len(enrollments))
+	for id := range enrollments {
+		ids = append(ids, id)
+	}
+	// Valor provisório único e fora do formato de matrícula.
+	if _, err := r.db.ExecContext(ctx, `UPDATE students SET enrollment = '~' || id WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao liberar matrículas", "count", len(ids), "error", err)
+		return fmt.Errorf("falha ao liberar matrículas: %w", err)
+	}
+	for id, enrollment := range enrollments {
+		if _, err := r.db.ExecContext(ctx, `UPDATE students SET enrollment = $1, version = version + 1 WHERE id = $2`, enrollment, id); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao gravar matrícula", "student_id", id, "enrollment", enrollment, "error", err)
+			return fmt.Errorf("falha ao gravar matrícula %s: %w", enrollment, err)
+		}
+	}
+	r.logger.DebugContext(ctx, "matrículas renumeradas", "count", len(enrollments))
+	return nil
+}
+
 // GetSubjectsByStudentID busca todas as matérias associadas a um aluno.
-func (r *StudentRepository) GetSubjectsByStudentID(studentID string) ([]models.Subject, error) {
+func (r *StudentRepository) GetSubjectsByStudentID(ctx context.Context, studentID string) ([]models.Subject, error) {
 	query := `
-	SELECT s.id, s.name, s.year, s.credits
+	SELECT s.id, s.name, s.year, s.credits, s.mandatory
 	FROM subjects s
 	JOIN student_subjects ss ON s.id = ss.subject_id
-	WHERE ss.student_id = $1`
-	rows, err := r.db.Query(query, studentID)
+	WHERE ss.student_id = $1 AND s.deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
-		log.Printf("GetSubjectsByStudentID: Erro ao executar query para aluno ID %s: %v", studentID, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias do aluno", "student_id", studentID, "error", err)
 		return nil, fmt.Errorf("falha ao buscar matérias por aluno: %w", err)
 	}
 	defer rows.Close()
@@ -250,8 +511,8 @@ func (r *StudentRepository) GetSubjectsByStudentID(studentID string) ([]models.S
 	var subjects []models.Subject
 	for rows.Next() {
 		subject := models.Subject{}
-		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits); err != nil {
-			log.Printf("GetSubjectsByStudentID: Erro ao escanear matéria do aluno ID %s: %v", studentID, err)
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao escanear matéria do aluno", "student_id", studentID, "error", err)
 			return nil, fmt.Errorf("falha ao escanear matéria: %w", err)
 		}
 		subjects = append(subjects, subject)
@@ -261,9 +522,9 @@ func (r *StudentRepository) GetSubjectsByStudentID(studentID string) ([]models.S
 	}
 
 	if subjects == nil {
-		log.Printf("GetSubjectsByStudentID: Nenhuma matéria encontrada para aluno ID %s (retornando slice vazio).", studentID)
+		r.logger.DebugContext(ctx, "nenhuma matéria associada ao aluno", "student_id", studentID)
 		return []models.Subject{}, nil
 	}
-	log.Printf("GetSubjectsByStudentID: %d matérias encontradas para aluno ID %s.", len(subjects), studentID)
+	r.logger.DebugContext(ctx, "matérias do aluno encontradas", "student_id", studentID, "count", len(subjects))
 	return subjects, nil
 }
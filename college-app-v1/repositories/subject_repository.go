@@ -3,58 +3,99 @@ package repositories
 
 import (
 	"college-app-v1/models"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt" // Importar fmt para usar fmt.Errorf
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid" // <-- Adicionar este import!
 )
 
+// ErrSubjectNotFound indica que a matéria não existe (ou está excluída, quando a operação só
+// considera matérias ativas). Os erros de atualização e exclusão o envolvem, com o sufixo da operação.
+var ErrSubjectNotFound = errors.New("matéria não encontrada")
+
 type SubjectRepository struct {
-	db *sql.DB
+	db     DBTX
+	logger *slog.Logger
+}
+
+func NewSubjectRepository(db *sql.DB, logger *slog.Logger) *SubjectRepository {
+	return &SubjectRepository{db: instrument("subjects", db), logger: logger}
 }
 
-func NewSubjectRepository(db *sql.DB) *SubjectRepository {
-	return &SubjectRepository{db: db}
+// WithTx retorna uma SubjectRepository que executa as operações na transação tx.
+func (r *SubjectRepository) WithTx(tx *sql.Tx) *SubjectRepository {
+	return &SubjectRepository{db: instrument("subjects", tx), logger: r.logger}
 }
 
 // CreateSubject insere uma nova matéria no banco de dados.
-func (r *SubjectRepository) CreateSubject(subject *models.Subject) error {
+func (r *SubjectRepository) CreateSubject(ctx context.Context, subject *models.Subject) error {
 	// --- MUDANÇA CRÍTICA AQUI: Gerar o UUID para o ID da matéria ---
 	subject.ID = uuid.New().String() // Gera um ID único para a matéria
 
-	query := `INSERT INTO subjects (id, name, year, credits) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, subject.ID, subject.Name, subject.Year, subject.Credits)
+	query := `INSERT INTO subjects (id, name, year, credits, mandatory) VALUES ($1, $2, $3, $4, $5) RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, subject.ID, subject.Name, subject.Year, subject.Credits, subject.Mandatory).Scan(&subject.Version)
 	if err != nil {
-		log.Printf("CreateSubject: Erro ao executar INSERT para matéria %s (Name: %s, Year: %d): %v", subject.ID, subject.Name, subject.Year, err)
+		r.logger.ErrorContext(ctx, "erro ao inserir matéria", "subject", subject.Name, "year", subject.Year, "error", err)
 		return fmt.Errorf("falha ao criar matéria no DB: %w", err) // Encapsular o erro
 	}
-	log.Printf("CreateSubject: Matéria '%s' (ID: %s, Ano: %d) criada com sucesso.", subject.Name, subject.ID, subject.Year)
+	r.logger.DebugContext(ctx, "matéria criada", "subject_id", subject.ID, "subject", subject.Name, "year", subject.Year)
+	return nil
+}
+
+// NameExists informa se alguma matéria, inclusive excluída, já usa o nome (a coluna é única).
+func (r *SubjectRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM subjects WHERE name = $1)`, name).Scan(&exists)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao verificar nome de matéria", "subject", name, "error", err)
+		return false, fmt.Errorf("falha ao verificar nome de matéria: %w", err)
+	}
+	return exists, nil
+}
+
+// LockSubjects bloqueia a criação e a alteração de matérias por outras transações até o fim da
+// transação atual, para que a unicidade dos nomes possa ser conferida antes de gravar. Exige WithTx.
+func (r *SubjectRepository) LockSubjects(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `LOCK TABLE subjects IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao bloquear tabela de matérias", "error", err)
+		return fmt.Errorf("falha ao bloquear matérias: %w", err)
+	}
 	return nil
 }
 
-// GetSubjectByID busca uma matéria pelo ID.
-func (r *SubjectRepository) GetSubjectByID(id string) (*models.Subject, error) {
+// GetSubjectByID busca uma matéria pelo ID. Matérias excluídas (soft delete) só são retornadas com includeDeleted.
+func (r *SubjectRepository) GetSubjectByID(ctx context.Context, id string, includeDeleted bool) (*models.Subject, error) {
 	subject := &models.Subject{}
-	query := `SELECT id, name, year, credits FROM subjects WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits)
+	query := `SELECT id, name, year, credits, mandatory, version, deleted_at FROM subjects WHERE id = $1`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetSubjectByID: Matéria com ID %s não encontrada no DB.", id)
-			return nil, fmt.Errorf("matéria não encontrada") // Retorna erro mais específico
+			r.logger.DebugContext(ctx, "matéria não encontrada", "subject_id", id)
+			return nil, ErrSubjectNotFound
 		}
-		log.Printf("GetSubjectByID: Erro ao buscar matéria por ID %s: %v", id, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matéria", "subject_id", id, "error", err)
 		return nil, fmt.Errorf("falha ao buscar matéria por ID: %w", err)
 	}
-	log.Printf("GetSubjectByID: Matéria '%s' (ID: %s) encontrada.", subject.Name, subject.ID)
+	r.logger.DebugContext(ctx, "matéria encontrada", "subject_id", subject.ID)
 	return subject, nil
 }
 
-// GetAllSubjects busca todas as matérias.
-func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
-	rows, err := r.db.Query(`SELECT id, name, year, credits FROM subjects`)
+// GetAllSubjects busca todas as matérias. includeDeleted inclui as excluídas (soft delete).
+func (r *SubjectRepository) GetAllSubjects(ctx context.Context, includeDeleted bool) ([]models.Subject, error) {
+	query := `SELECT id, name, year, credits, mandatory, version, deleted_at FROM subjects`
+	if !includeDeleted {
+		query += ` WHERE deleted_at IS NULL`
+	}
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		log.Printf("GetAllSubjects: Erro ao buscar todas as matérias: %v", err)
+		r.logger.ErrorContext(ctx, "erro ao listar matérias", "error", err)
 		return nil, fmt.Errorf("falha ao buscar todas as matérias: %w", err)
 	}
 	defer rows.Close()
@@ -62,8 +103,89 @@ func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
 	var subjects []models.Subject
 	for rows.Next() {
 		subject := models.Subject{}
-		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits); err != nil {
-			log.Printf("GetAllSubjects: Erro ao escanear matéria: %v", err)
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao escanear matéria", "error", err)
+			return nil, fmt.Errorf("falha ao escanear dados da matéria: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matérias: %w", err)
+	}
+	r.logger.DebugContext(ctx, "matérias listadas", "count", len(subjects))
+	return subjects, nil
+}
+
+// GetMandatorySubjectsByYear busca as matérias obrigatórias (não excluídas) de um ano do curso.
+func (r *SubjectRepository) GetMandatorySubjectsByYear(ctx context.Context, year int) ([]models.Subject, error) {
+	query := `SELECT id, name, year, credits, mandatory, version, deleted_at FROM subjects
+	WHERE year = $1 AND mandatory AND deleted_at IS NULL ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query, year)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias obrigatórias do ano", "year", year, "error", err)
+		return nil, fmt.Errorf("falha ao buscar matérias obrigatórias: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.Subject{}
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados da matéria: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matérias: %w", err)
+	}
+	return subjects, nil
+}
+
+// GetAllMandatorySubjects busca as matérias obrigatórias (não excluídas) de todos os anos, por ano e nome.
+func (r *SubjectRepository) GetAllMandatorySubjects(ctx context.Context) ([]models.Subject, error) {
+	query := `SELECT id, name, year, credits, mandatory, version, deleted_at FROM subjects
+	WHERE mandatory AND deleted_at IS NULL ORDER BY year, name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias obrigatórias", "error", err)
+		return nil, fmt.Errorf("falha ao buscar matérias obrigatórias: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.Subject{}
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados da matéria: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de matérias: %w", err)
+	}
+	return subjects, nil
+}
+
+// GetSubjectsByDepartmentID busca as matérias (não excluídas) lecionadas por professores ativos
+// do departamento.
+func (r *SubjectRepository) GetSubjectsByDepartmentID(ctx context.Context, departmentID string) ([]models.Subject, error) {
+	query := `SELECT DISTINCT s.id, s.name, s.year, s.credits, s.mandatory, s.version, s.deleted_at
+	FROM subjects s
+	JOIN teacher_subjects ts ON ts.subject_id = s.id
+	JOIN teachers t ON t.id = ts.teacher_id
+	WHERE t.department_id = $1 AND t.deleted_at IS NULL AND s.deleted_at IS NULL
+	ORDER BY s.name, s.id`
+	rows, err := r.db.QueryContext(ctx, query, departmentID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias do departamento", "department_id", departmentID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar matérias do departamento: %w", err)
+	}
+	defer rows.Close()
+
+	subjects := []models.Subject{}
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
 			return nil, fmt.Errorf("falha ao escanear dados da matéria: %w", err)
 		}
 		subjects = append(subjects, subject)
@@ -71,46 +193,120 @@ func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("erro durante iteração de matérias: %w", err)
 	}
-	log.Printf("GetAllSubjects: %d matérias encontradas.", len(subjects))
 	return subjects, nil
 }
 
-// UpdateSubject atualiza uma matéria existente.
-func (r *SubjectRepository) UpdateSubject(subject *models.Subject) error {
-	query := `UPDATE subjects SET name = $1, year = $2, credits = $3 WHERE id = $4`
-	result, err := r.db.Exec(query, subject.Name, subject.Year, subject.Credits, subject.ID)
+// StreamSubjects percorre as matérias chamando fn para cada uma, sem carregar a lista inteira
+// em memória (usado na exportação). Se fn retornar erro, a iteração é interrompida.
+func (r *SubjectRepository) StreamSubjects(ctx context.Context, includeDeleted bool, fn func(*models.Subject) error) error {
+	query := `SELECT id, name, year, credits, mandatory, version, deleted_at FROM subjects`
+	if !includeDeleted {
+		query += ` WHERE deleted_at IS NULL`
+	}
+	query += ` ORDER BY year, name`
+
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		log.Printf("UpdateSubject: Erro ao atualizar matéria %s (ID: %s): %v", subject.Name, subject.ID, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias para exportação", "error", err)
+		return fmt.Errorf("falha ao buscar matérias para exportação: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var subject models.Subject
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory, &subject.Version, &subject.DeletedAt); err != nil {
+			return fmt.Errorf("falha ao escanear dados da matéria: %w", err)
+		}
+		if err := fn(&subject); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro durante iteração de matérias: %w", err)
+	}
+	r.logger.DebugContext(ctx, "matérias exportadas", "count", count)
+	return nil
+}
+
+// UpdateSubject atualiza uma matéria existente, desde que subject.Version seja a versão atual.
+// Em caso de sucesso, subject.Version recebe a nova versão; caso contrário retorna ErrVersionConflict.
+func (r *SubjectRepository) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	query := `UPDATE subjects SET name = $1, year = $2, credits = $3, mandatory = $4, version = version + 1
+	WHERE id = $5 AND version = $6 AND deleted_at IS NULL RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, subject.Name, subject.Year, subject.Credits, subject.Mandatory, subject.ID, subject.Version).Scan(&subject.Version)
+	if err == sql.ErrNoRows {
+		r.logger.DebugContext(ctx, "matéria não encontrada na versão para atualização", "subject_id", subject.ID, "version", subject.Version)
+		return checkVersionConflict(ctx, r.db, "subjects", subject.ID, fmt.Errorf("%w para atualização", ErrSubjectNotFound))
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar matéria", "subject_id", subject.ID, "error", err)
 		return fmt.Errorf("falha ao atualizar matéria: %w", err)
 	}
+	r.logger.DebugContext(ctx, "matéria atualizada", "subject_id", subject.ID, "version", subject.Version)
+	return nil
+}
+
+// patchableSubjectColumns são as colunas de subjects que PatchSubject pode alterar.
+var patchableSubjectColumns = map[string]bool{"name": true, "year": true, "credits": true, "mandatory": true}
+
+// PatchSubject atualiza apenas as colunas em changes (coluna -> novo valor) de uma matéria ativa,
+// desde que version seja a versão atual, e retorna a nova versão. Se outra requisição alterou
+// a matéria antes, retorna ErrVersionConflict.
+func (r *SubjectRepository) PatchSubject(ctx context.Context, id string, version int, changes map[string]interface{}) (int, error) {
+	newVersion, err := updateColumns(ctx, r.db, "subjects", patchableSubjectColumns, id, version, changes, fmt.Errorf("%w para atualização", ErrSubjectNotFound))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar colunas da matéria", "subject_id", id, "columns", changedColumns(changes), "version", version, "error", err)
+		return 0, err
+	}
+	r.logger.DebugContext(ctx, "matéria atualizada", "subject_id", id, "columns", changedColumns(changes), "version", newVersion)
+	return newVersion, nil
+}
+
+// DeleteSubject marca uma matéria como excluída (soft delete), preservando o histórico de associações.
+// version deve ser a versão atual da matéria; caso contrário retorna ErrVersionConflict.
+func (r *SubjectRepository) DeleteSubject(ctx context.Context, id string, version int) error {
+	query := `UPDATE subjects SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao excluir matéria", "subject_id", id, "error", err)
+		return fmt.Errorf("falha ao deletar matéria: %w", err)
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após atualização: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("UpdateSubject: Nenhuma matéria encontrada para atualizar com ID %s.", subject.ID)
-		return fmt.Errorf("matéria não encontrada para atualização")
+		r.logger.DebugContext(ctx, "matéria não encontrada na versão para exclusão", "subject_id", id, "version", version)
+		return checkVersionConflict(ctx, r.db, "subjects", id, fmt.Errorf("%w para exclusão", ErrSubjectNotFound))
 	}
-	log.Printf("UpdateSubject: Matéria '%s' (ID: %s) atualizada com sucesso.", subject.Name, subject.ID)
+	r.logger.DebugContext(ctx, "matéria excluída", "subject_id", id)
 	return nil
 }
 
-// DeleteSubject deleta uma matéria pelo ID.
-func (r *SubjectRepository) DeleteSubject(id string) error {
-	query := `DELETE FROM subjects WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+// RestoreSubject desfaz a exclusão (soft delete) de uma matéria.
+func (r *SubjectRepository) RestoreSubject(ctx context.Context, id string) error {
+	query := `UPDATE subjects SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		log.Printf("DeleteSubject: Erro ao deletar matéria ID %s: %v", id, err)
-		return fmt.Errorf("falha ao deletar matéria: %w", err)
+		r.logger.ErrorContext(ctx, "erro ao restaurar matéria", "subject_id", id, "error", err)
+		return fmt.Errorf("falha ao restaurar matéria: %w", err)
 	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após restauração: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("DeleteSubject: Nenhuma matéria encontrada para deletar com ID %s.", id)
-		return fmt.Errorf("matéria não encontrada para exclusão")
+		r.logger.DebugContext(ctx, "matéria excluída não encontrada para restauração", "subject_id", id)
+		return fmt.Errorf("matéria excluída não encontrada para restauração")
 	}
-	log.Printf("DeleteSubject: Matéria com ID %s deletada com sucesso.", id)
+	r.logger.DebugContext(ctx, "matéria restaurada", "subject_id", id)
 	return nil
 }
+
+// PurgeDeletedSubjects remove definitivamente matérias excluídas antes de olderThan,
+// junto com suas associações (ON DELETE CASCADE). Retorna os IDs removidos.
+func (r *SubjectRepository) PurgeDeletedSubjects(ctx context.Context, olderThan time.Time) ([]string, error) {
+	return purgeDeleted(ctx, r.db, r.logger, "subjects", olderThan)
+}
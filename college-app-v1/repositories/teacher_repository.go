@@ -4,94 +4,144 @@ package repositories
 
 import (
 	"college-app-v1/models" // Certifique-se de que este caminho está correto
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
 
-	// Adicionado para strings.ToLower
 	"github.com/google/uuid" // Adicionar este import se ainda não estiver
+	"github.com/lib/pq"
 )
 
+// ErrTeacherNotFound indica que o professor não existe (ou está excluído, quando a operação só
+// considera professores ativos). Os erros de atualização e exclusão o envolvem, com o sufixo da operação.
+var ErrTeacherNotFound = errors.New("professor não encontrado")
+
 // TeacherRepository define a interface para operações de persistência de professor.
 type TeacherRepository struct {
-	db *sql.DB
+	db     DBTX
+	logger *slog.Logger
 }
 
 // NewTeacherRepository cria uma nova instância de TeacherRepository.
-func NewTeacherRepository(db *sql.DB) *TeacherRepository {
-	return &TeacherRepository{db: db}
+func NewTeacherRepository(db *sql.DB, logger *slog.Logger) *TeacherRepository {
+	return &TeacherRepository{db: instrument("teachers", db), logger: logger}
+}
+
+// WithTx retorna um TeacherRepository que executa as operações na transação tx.
+func (r *TeacherRepository) WithTx(tx *sql.Tx) *TeacherRepository {
+	return &TeacherRepository{db: instrument("teachers", tx), logger: r.logger}
+}
+
+// teacherRegistry deriva o registro de um professor criado sem registro informado: "PROF" e os
+// oito primeiros caracteres do ID (ex: "PROF3F2A9C1B"). teachers.registry é NOT NULL.
+func teacherRegistry(id string) string {
+	return "PROF" + strings.ToUpper(id[:8])
 }
 
 // CreateTeacher insere um novo professor no banco de dados.
 // Assumimos que o ID é gerado aqui.
-func (r *TeacherRepository) CreateTeacher(teacher *models.Teacher) error {
+func (r *TeacherRepository) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
 	teacher.ID = uuid.New().String() // Gera um ID único para o professor
-	query := `INSERT INTO teachers (id, name, department, email) VALUES ($1, $2, $3, $4) RETURNING id`
-	_, err := r.db.Exec(query, teacher.ID, teacher.Name, teacher.Department, teacher.Email)
+	if teacher.Registry == "" {
+		teacher.Registry = teacherRegistry(teacher.ID)
+	}
+	query := `INSERT INTO teachers (id, registry, name, department_id, email, contract_type) VALUES ($1, $2, $3, $4, $5, $6) RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, teacher.ID, teacher.Registry, teacher.Name, teacher.DepartmentID, teacher.Email, teacher.ContractType).Scan(&teacher.Version)
 	if err != nil {
-		log.Printf("CreateTeacher: Erro ao executar INSERT para professor %s: %v", teacher.Name, err)
+		r.logger.ErrorContext(ctx, "erro ao inserir professor", "department_id", teacher.DepartmentID, "error", err)
 		return fmt.Errorf("falha ao criar professor no DB: %w", err)
 	}
-	log.Printf("CreateTeacher: Professor '%s' (ID: %s, Dept: %s, Email: %s) criado com sucesso.", teacher.Name, teacher.ID, teacher.Department, teacher.Email)
+	r.logger.DebugContext(ctx, "professor criado", "teacher_id", teacher.ID, "department_id", teacher.DepartmentID)
+	return nil
+}
+
+// EmailExists informa se algum professor, inclusive excluído, já usa o email (a coluna é única).
+func (r *TeacherRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM teachers WHERE LOWER(email) = LOWER($1))`, email).Scan(&exists)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao verificar email de professor", "email", email, "error", err)
+		return false, fmt.Errorf("falha ao verificar email de professor: %w", err)
+	}
+	return exists, nil
+}
+
+// LockTeachers bloqueia a criação e a alteração de professores por outras transações até o fim
+// da transação atual, para que a unicidade dos emails possa ser conferida antes de gravar. Exige WithTx.
+func (r *TeacherRepository) LockTeachers(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `LOCK TABLE teachers IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		r.logger.ErrorContext(ctx, "erro ao bloquear tabela de professores", "error", err)
+		return fmt.Errorf("falha ao bloquear professores: %w", err)
+	}
 	return nil
 }
 
 // GetTeacherByID busca um professor pelo ID, incluindo matérias associadas.
-func (r *TeacherRepository) GetTeacherByID(id string) (*models.Teacher, error) {
+// Professores excluídos (soft delete) só são retornados com includeDeleted.
+func (r *TeacherRepository) GetTeacherByID(ctx context.Context, id string, includeDeleted bool) (*models.Teacher, error) {
 	var teacher models.Teacher
-	query := `SELECT id, name, department, email FROM teachers WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&teacher.ID, &teacher.Name, &teacher.Department, &teacher.Email)
+	query := `SELECT t.id, t.registry, t.name, COALESCE(t.department_id, ''), COALESCE(d.name, ''), t.email, t.contract_type, t.version, t.deleted_at
+	FROM teachers t LEFT JOIN departments d ON d.id = t.department_id WHERE t.id = $1`
+	if !includeDeleted {
+		query += ` AND t.deleted_at IS NULL`
+	}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&teacher.ID, &teacher.Registry, &teacher.Name, &teacher.DepartmentID, &teacher.Department, &teacher.Email, &teacher.ContractType, &teacher.Version, &teacher.DeletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("GetTeacherByID: Professor com ID %s não encontrado no DB.", id)
-			return nil, fmt.Errorf("professor não encontrado")
+			r.logger.DebugContext(ctx, "professor não encontrado", "teacher_id", id)
+			return nil, ErrTeacherNotFound
 		}
-		log.Printf("GetTeacherByID: Erro ao buscar professor por ID %s: %v", id, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar professor", "teacher_id", id, "error", err)
 		return nil, fmt.Errorf("falha ao buscar professor por ID: %w", err)
 	}
 
 	// Buscar matérias para este professor
-	subjects, err := r.GetSubjectsByTeacherID(teacher.ID) // Assumindo que você tem essa função
+	subjects, err := r.GetSubjectsByTeacherID(ctx, teacher.ID) // Assumindo que você tem essa função
 	if err != nil {
-		log.Printf("GetTeacherByID: Erro ao buscar matérias para o professor %s (ID: %s): %v", teacher.Name, teacher.ID, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias do professor", "teacher_id", teacher.ID, "error", err)
 		return nil, fmt.Errorf("falha ao buscar matérias associadas: %w", err)
 	}
 	teacher.Subjects = subjects // Atribui as matérias
-	log.Printf("GetTeacherByID: Professor '%s' (ID: %s) encontrado com sucesso, %d matérias.", teacher.Name, teacher.ID, len(teacher.Subjects))
+	r.logger.DebugContext(ctx, "professor encontrado", "teacher_id", teacher.ID, "subjects", len(teacher.Subjects))
 	return &teacher, nil
 }
 
 // GetAllTeachers busca todos os professores com filtros.
-// nameFilter, departmentFilter, emailFilter: strings vazias significam sem filtro.
-func (r *TeacherRepository) GetAllTeachers(nameFilter, departmentFilter, emailFilter string) ([]models.Teacher, error) {
-	baseQuery := `SELECT id, name, department, email FROM teachers WHERE 1=1`
+// nameFilter, departmentFilter, emailFilter: strings vazias significam sem filtro. departmentFilter
+// aceita o ID ou o código exato do departamento, ou parte do nome.
+// includeDeleted: incluir professores excluídos (soft delete).
+func (r *TeacherRepository) GetAllTeachers(ctx context.Context, nameFilter, departmentFilter, emailFilter string, includeDeleted bool) ([]models.Teacher, error) {
+	baseQuery := `SELECT t.id, t.registry, t.name, COALESCE(t.department_id, ''), COALESCE(d.name, ''), t.email, t.contract_type, t.version, t.deleted_at
+	FROM teachers t LEFT JOIN departments d ON d.id = t.department_id WHERE 1=1`
+	if !includeDeleted {
+		baseQuery += ` AND t.deleted_at IS NULL`
+	}
 	args := []interface{}{}
 	argCounter := 1
 
 	if nameFilter != "" {
-		baseQuery += fmt.Sprintf(" AND LOWER(name) LIKE LOWER($%d)", argCounter)
+		baseQuery += fmt.Sprintf(" AND LOWER(t.name) LIKE LOWER($%d)", argCounter)
 		args = append(args, "%"+nameFilter+"%") // % para LIKE
 		argCounter++
 	}
 	if departmentFilter != "" {
-		baseQuery += fmt.Sprintf(" AND LOWER(department) LIKE LOWER($%d)", argCounter)
-		args = append(args, "%"+departmentFilter+"%")
+		baseQuery += departmentFilterClause(argCounter)
+		args = append(args, departmentFilter)
 		argCounter++
 	}
 	if emailFilter != "" {
-		baseQuery += fmt.Sprintf(" AND LOWER(email) LIKE LOWER($%d)", argCounter)
+		baseQuery += fmt.Sprintf(" AND LOWER(t.email) LIKE LOWER($%d)", argCounter)
 		args = append(args, "%"+emailFilter+"%")
 		argCounter++
 	}
 
-	// --- ADICIONE ESTES LOGS TEMPORÁRIOS PARA DEPURAR (REMOVER EM PRODUÇÃO) ---
-	log.Printf("GetAllTeachers Repository: Query SQL final: '%s'", baseQuery)
-	log.Printf("GetAllTeachers Repository: Argumentos SQL: %v", args)
-	// --- FIM DOS LOGS TEMPORÁRIOS ---
-
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
 	if err != nil {
-		log.Printf("GetAllTeachers: Erro ao executar query com filtros: %v", err)
+		r.logger.ErrorContext(ctx, "erro ao listar professores", "name_filter", nameFilter, "department", departmentFilter, "email_filter", emailFilter, "error", err)
 		return nil, fmt.Errorf("falha ao buscar professores com filtros: %w", err)
 	}
 	defer rows.Close()
@@ -99,14 +149,14 @@ func (r *TeacherRepository) GetAllTeachers(nameFilter, departmentFilter, emailFi
 	var teachers []models.Teacher
 	for rows.Next() {
 		var t models.Teacher
-		if err := rows.Scan(&t.ID, &t.Name, &t.Department, &t.Email); err != nil {
-			log.Printf("GetAllTeachers: Erro ao escanear professor: %v", err)
+		if err := rows.Scan(&t.ID, &t.Registry, &t.Name, &t.DepartmentID, &t.Department, &t.Email, &t.ContractType, &t.Version, &t.DeletedAt); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao escanear professor", "error", err)
 			return nil, fmt.Errorf("falha ao escanear dados do professor: %w", err)
 		}
 		// Buscar matérias para cada professor (abordagem N+1 - pode ser otimizada com JOINs)
-		subjects, err := r.GetSubjectsByTeacherID(t.ID) // Assumindo que você tem essa função
+		subjects, err := r.GetSubjectsByTeacherID(ctx, t.ID) // Assumindo que você tem essa função
 		if err != nil {
-			log.Printf("GetAllTeachers: Erro ao buscar matérias para professor %s (ID: %s) durante iteração: %v", t.Name, t.ID, err)
+			r.logger.ErrorContext(ctx, "erro ao buscar matérias do professor", "teacher_id", t.ID, "error", err)
 			return nil, fmt.Errorf("falha ao buscar matérias associadas ao professor %s: %w", t.ID, err)
 		}
 		t.Subjects = subjects
@@ -116,68 +166,247 @@ func (r *TeacherRepository) GetAllTeachers(nameFilter, departmentFilter, emailFi
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("erro durante iteração de professores: %w", err)
 	}
-	log.Printf("GetAllTeachers: %d professores encontrados e processados com filtros (Nome: '%s', Dept: '%s', Email: '%s').", len(teachers), nameFilter, departmentFilter, emailFilter)
+	r.logger.DebugContext(ctx, "professores listados", "count", len(teachers), "name_filter", nameFilter, "department", departmentFilter, "email_filter", emailFilter)
+	return teachers, nil
+}
+
+// StreamTeachers percorre os professores com os mesmos filtros de GetAllTeachers, chamando fn
+// para cada um sem carregar a lista inteira em memória (usado na exportação). As matérias
+// associadas vêm na mesma consulta, apenas com ID e nome. Se fn retornar erro, a iteração é interrompida.
+func (r *TeacherRepository) StreamTeachers(ctx context.Context, nameFilter, departmentFilter, emailFilter string, includeDeleted bool, fn func(*models.Teacher) error) error {
+	baseQuery := `
+	SELECT t.id, t.registry, t.name, COALESCE(t.department_id, ''), COALESCE(d.name, ''), t.email, t.contract_type, t.version, t.deleted_at,
+		COALESCE(array_agg(sub.id ORDER BY sub.name) FILTER (WHERE sub.id IS NOT NULL), '{}'),
+		COALESCE(array_agg(sub.name ORDER BY sub.name) FILTER (WHERE sub.id IS NOT NULL), '{}')
+	FROM teachers t
+	LEFT JOIN departments d ON d.id = t.department_id
+	LEFT JOIN teacher_subjects ts ON ts.teacher_id = t.id
+	LEFT JOIN subjects sub ON sub.id = ts.subject_id AND sub.deleted_at IS NULL
+	WHERE 1=1`
+	if !includeDeleted {
+		baseQuery += ` AND t.deleted_at IS NULL`
+	}
+	args := []interface{}{}
+	argCounter := 1
+	if nameFilter != "" {
+		baseQuery += fmt.Sprintf(" AND LOWER(t.name) LIKE LOWER($%d)", argCounter)
+		args = append(args, "%"+nameFilter+"%")
+		argCounter++
+	}
+	if departmentFilter != "" {
+		baseQuery += departmentFilterClause(argCounter)
+		args = append(args, departmentFilter)
+		argCounter++
+	}
+	if emailFilter != "" {
+		baseQuery += fmt.Sprintf(" AND LOWER(t.email) LIKE LOWER($%d)", argCounter)
+		args = append(args, "%"+emailFilter+"%")
+		argCounter++
+	}
+	baseQuery += ` GROUP BY t.id, d.name ORDER BY t.name, t.id`
+
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar professores para exportação", "name_filter", nameFilter, "department", departmentFilter, "email_filter", emailFilter, "error", err)
+		return fmt.Errorf("falha ao buscar professores para exportação: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var teacher models.Teacher
+		var subjectIDs, subjectNames []string
+		if err := rows.Scan(&teacher.ID, &teacher.Registry, &teacher.Name, &teacher.DepartmentID, &teacher.Department, &teacher.Email, &teacher.ContractType, &teacher.Version, &teacher.DeletedAt,
+			pq.Array(&subjectIDs), pq.Array(&subjectNames)); err != nil {
+			return fmt.Errorf("falha ao escanear dados do professor: %w", err)
+		}
+		teacher.Subjects = subjectsFromArrays(subjectIDs, subjectNames)
+		if err := fn(&teacher); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro durante iteração de professores: %w", err)
+	}
+	r.logger.DebugContext(ctx, "professores exportados", "count", count, "name_filter", nameFilter, "department", departmentFilter, "email_filter", emailFilter)
+	return nil
+}
+
+// departmentFilterClause filtra professores pelo departamento: ID ou código exato, ou parte do nome.
+func departmentFilterClause(arg int) string {
+	return fmt.Sprintf(" AND (t.department_id = $%[1]d OR LOWER(d.code) = LOWER($%[1]d) OR LOWER(d.name) LIKE LOWER('%%' || $%[1]d || '%%'))", arg)
+}
+
+// GetTeachersByDepartmentID busca os professores ativos de um departamento, sem as matérias.
+func (r *TeacherRepository) GetTeachersByDepartmentID(ctx context.Context, departmentID string) ([]models.Teacher, error) {
+	query := `SELECT t.id, t.registry, t.name, t.department_id, d.name, t.email, t.contract_type, t.version, t.deleted_at
+	FROM teachers t JOIN departments d ON d.id = t.department_id
+	WHERE t.department_id = $1 AND t.deleted_at IS NULL ORDER BY t.name`
+	rows, err := r.db.QueryContext(ctx, query, departmentID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao buscar professores do departamento", "department_id", departmentID, "error", err)
+		return nil, fmt.Errorf("falha ao buscar professores do departamento: %w", err)
+	}
+	defer rows.Close()
+
+	teachers := []models.Teacher{}
+	for rows.Next() {
+		var t models.Teacher
+		if err := rows.Scan(&t.ID, &t.Registry, &t.Name, &t.DepartmentID, &t.Department, &t.Email, &t.ContractType, &t.Version, &t.DeletedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear dados do professor: %w", err)
+		}
+		teachers = append(teachers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de professores: %w", err)
+	}
 	return teachers, nil
 }
 
-// UpdateTeacher atualiza um professor existente.
-func (r *TeacherRepository) UpdateTeacher(teacher *models.Teacher) error {
-	query := `UPDATE teachers SET name = $1, department = $2, email = $3 WHERE id = $4`
-	res, err := r.db.Exec(query, teacher.Name, teacher.Department, teacher.Email, teacher.ID)
+// GetTeacherWorkloads soma créditos e matérias (não excluídas) dos professores ativos.
+// departmentFilter segue as regras de GetAllTeachers; teacherIDs, se não vazio, restringe aos IDs informados.
+// As horas e o limite são preenchidos pelo serviço.
+func (r *TeacherRepository) GetTeacherWorkloads(ctx context.Context, departmentFilter string, teacherIDs []string) ([]models.TeacherWorkload, error) {
+	query := `
+	SELECT t.id, t.name, COALESCE(t.department_id, ''), COALESCE(d.name, ''), t.contract_type,
+		COUNT(s.id), COALESCE(SUM(s.credits), 0)
+	FROM teachers t
+	LEFT JOIN departments d ON d.id = t.department_id
+	LEFT JOIN teacher_subjects ts ON ts.teacher_id = t.id
+	LEFT JOIN subjects s ON s.id = ts.subject_id AND s.deleted_at IS NULL
+	WHERE t.deleted_at IS NULL`
+	args := []interface{}{}
+	if departmentFilter != "" {
+		args = append(args, departmentFilter)
+		query += departmentFilterClause(len(args))
+	}
+	if len(teacherIDs) > 0 {
+		args = append(args, pq.Array(teacherIDs))
+		query += fmt.Sprintf(" AND t.id = ANY($%d)", len(args))
+	}
+	query += ` GROUP BY t.id, d.name ORDER BY d.name, t.name, t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao calcular carga horária", "department", departmentFilter, "error", err)
+		return nil, fmt.Errorf("falha ao calcular carga horária dos professores: %w", err)
+	}
+	defer rows.Close()
+
+	workloads := []models.TeacherWorkload{}
+	for rows.Next() {
+		var w models.TeacherWorkload
+		if err := rows.Scan(&w.TeacherID, &w.TeacherName, &w.DepartmentID, &w.Department, &w.ContractType, &w.Subjects, &w.Credits); err != nil {
+			return nil, fmt.Errorf("falha ao escanear carga horária do professor: %w", err)
+		}
+		workloads = append(workloads, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante iteração de carga horária: %w", err)
+	}
+	return workloads, nil
+}
+
+// UpdateTeacher atualiza um professor existente, desde que teacher.Version seja a versão atual.
+// Em caso de sucesso, teacher.Version recebe a nova versão; caso contrário retorna ErrVersionConflict.
+func (r *TeacherRepository) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	query := `UPDATE teachers SET name = $1, department_id = $2, email = $3, contract_type = $4, version = version + 1
+	WHERE id = $5 AND version = $6 AND deleted_at IS NULL RETURNING version`
+	err := r.db.QueryRowContext(ctx, query, teacher.Name, teacher.DepartmentID, teacher.Email, teacher.ContractType, teacher.ID, teacher.Version).Scan(&teacher.Version)
+	if err == sql.ErrNoRows {
+		r.logger.DebugContext(ctx, "professor não encontrado na versão para atualização", "teacher_id", teacher.ID, "version", teacher.Version)
+		return checkVersionConflict(ctx, r.db, "teachers", teacher.ID, fmt.Errorf("%w para atualização", ErrTeacherNotFound))
+	}
 	if err != nil {
-		log.Printf("UpdateTeacher: Erro ao atualizar professor %s (ID: %s): %v", teacher.Name, teacher.ID, err)
+		r.logger.ErrorContext(ctx, "erro ao atualizar professor", "teacher_id", teacher.ID, "error", err)
 		return fmt.Errorf("falha ao atualizar professor: %w", err)
 	}
+	r.logger.DebugContext(ctx, "professor atualizado", "teacher_id", teacher.ID, "version", teacher.Version)
+	return nil
+}
+
+// patchableTeacherColumns são as colunas de teachers que PatchTeacher pode alterar.
+var patchableTeacherColumns = map[string]bool{"name": true, "email": true, "department_id": true, "contract_type": true}
+
+// PatchTeacher atualiza apenas as colunas em changes (coluna -> novo valor) de um professor ativo,
+// desde que version seja a versão atual, e retorna a nova versão. Se outra requisição alterou
+// o professor antes, retorna ErrVersionConflict.
+func (r *TeacherRepository) PatchTeacher(ctx context.Context, id string, version int, changes map[string]interface{}) (int, error) {
+	newVersion, err := updateColumns(ctx, r.db, "teachers", patchableTeacherColumns, id, version, changes, fmt.Errorf("%w para atualização", ErrTeacherNotFound))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao atualizar colunas do professor", "teacher_id", id, "columns", changedColumns(changes), "version", version, "error", err)
+		return 0, err
+	}
+	r.logger.DebugContext(ctx, "professor atualizado", "teacher_id", id, "columns", changedColumns(changes), "version", newVersion)
+	return newVersion, nil
+}
+
+// DeleteTeacher marca um professor como excluído (soft delete), preservando suas associações.
+// version deve ser a versão atual do professor; caso contrário retorna ErrVersionConflict.
+func (r *TeacherRepository) DeleteTeacher(ctx context.Context, id string, version int) error {
+	query := `UPDATE teachers SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "erro ao excluir professor", "teacher_id", id, "error", err)
+		return fmt.Errorf("falha ao deletar professor: %w", err)
+	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após atualização: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("UpdateTeacher: Nenhum professor encontrado para atualizar com ID %s.", teacher.ID)
-		return fmt.Errorf("professor não encontrado para atualização")
+		r.logger.DebugContext(ctx, "professor não encontrado na versão para exclusão", "teacher_id", id, "version", version)
+		return checkVersionConflict(ctx, r.db, "teachers", id, fmt.Errorf("%w para exclusão", ErrTeacherNotFound))
 	}
-	log.Printf("UpdateTeacher: Professor '%s' (ID: %s) atualizado com sucesso.", teacher.Name, teacher.ID)
+	r.logger.DebugContext(ctx, "professor excluído", "teacher_id", id)
 	return nil
 }
 
-// DeleteTeacher deleta um professor pelo ID.
-func (r *TeacherRepository) DeleteTeacher(id string) error {
-	query := `DELETE FROM teachers WHERE id = $1`
-	res, err := r.db.Exec(query, id)
+// RestoreTeacher desfaz a exclusão (soft delete) de um professor.
+func (r *TeacherRepository) RestoreTeacher(ctx context.Context, id string) error {
+	query := `UPDATE teachers SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`
+	res, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		log.Printf("DeleteTeacher: Erro ao deletar professor ID %s: %v", id, err)
-		return fmt.Errorf("falha ao deletar professor: %w", err)
+		r.logger.ErrorContext(ctx, "erro ao restaurar professor", "teacher_id", id, "error", err)
+		return fmt.Errorf("falha ao restaurar professor: %w", err)
 	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("falha ao verificar linhas afetadas após exclusão: %w", err)
+		return fmt.Errorf("falha ao verificar linhas afetadas após restauração: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("DeleteTeacher: Nenhum professor encontrado para deletar com ID %s.", id)
-		return fmt.Errorf("professor não encontrado para exclusão")
+		r.logger.DebugContext(ctx, "professor excluído não encontrado para restauração", "teacher_id", id)
+		return fmt.Errorf("professor excluído não encontrado para restauração")
 	}
-	log.Printf("DeleteTeacher: Professor com ID %s deletado com sucesso.", id)
+	r.logger.DebugContext(ctx, "professor restaurado", "teacher_id", id)
 	return nil
 }
 
+// PurgeDeletedTeachers remove definitivamente professores excluídos antes de olderThan,
+// junto com suas associações (ON DELETE CASCADE). Retorna os IDs removidos.
+func (r *TeacherRepository) PurgeDeletedTeachers(ctx context.Context, olderThan time.Time) ([]string, error) {
+	return purgeDeleted(ctx, r.db, r.logger, "teachers", olderThan)
+}
+
 // AddSubjectToTeacher associa uma matéria a um professor (tabela teacher_subjects).
-func (r *TeacherRepository) AddSubjectToTeacher(teacherID, subjectID string) error {
+func (r *TeacherRepository) AddSubjectToTeacher(ctx context.Context, teacherID, subjectID string) error {
 	query := `INSERT INTO teacher_subjects (teacher_id, subject_id) VALUES ($1, $2) ON CONFLICT (teacher_id, subject_id) DO NOTHING`
-	_, err := r.db.Exec(query, teacherID, subjectID)
+	_, err := r.db.ExecContext(ctx, query, teacherID, subjectID)
 	if err != nil {
-		log.Printf("AddSubjectToTeacher: Erro ao executar INSERT para associação professor %s - matéria %s: %v", teacherID, subjectID, err)
+		r.logger.ErrorContext(ctx, "erro ao associar matéria ao professor", "teacher_id", teacherID, "subject_id", subjectID, "error", err)
 		return fmt.Errorf("falha ao associar matéria ao professor: %w", err)
 	}
-	log.Printf("AddSubjectToTeacher: Associação professor %s - matéria %s criada/existente.", teacherID, subjectID)
+	r.logger.DebugContext(ctx, "matéria associada ao professor", "teacher_id", teacherID, "subject_id", subjectID)
 	return nil
 }
 
 // RemoveSubjectFromTeacher desassocia uma matéria de um professor.
-func (r *TeacherRepository) RemoveSubjectFromTeacher(teacherID, subjectID string) error {
+func (r *TeacherRepository) RemoveSubjectFromTeacher(ctx context.Context, teacherID, subjectID string) error {
 	query := `DELETE FROM teacher_subjects WHERE teacher_id = $1 AND subject_id = $2`
-	res, err := r.db.Exec(query, teacherID, subjectID)
+	res, err := r.db.ExecContext(ctx, query, teacherID, subjectID)
 	if err != nil {
-		log.Printf("RemoveSubjectFromTeacher: Erro ao executar DELETE para associação professor %s - matéria %s: %v", teacherID, subjectID, err)
+		r.logger.ErrorContext(ctx, "erro ao desassociar matéria do professor", "teacher_id", teacherID, "subject_id", subjectID, "error", err)
 		return fmt.Errorf("falha ao desassociar matéria do professor: %w", err)
 	}
 	rowsAffected, err := res.RowsAffected()
@@ -185,23 +414,23 @@ func (r *TeacherRepository) RemoveSubjectFromTeacher(teacherID, subjectID string
 		return fmt.Errorf("falha ao verificar linhas afetadas após desassociação: %w", err)
 	}
 	if rowsAffected == 0 {
-		log.Printf("RemoveSubjectFromTeacher: Associação professor %s - matéria %s não encontrada para deletar.", teacherID, subjectID)
+		r.logger.DebugContext(ctx, "associação professor-matéria não encontrada", "teacher_id", teacherID, "subject_id", subjectID)
 		return fmt.Errorf("associação não encontrada para desassociação")
 	}
-	log.Printf("RemoveSubjectFromTeacher: Associação professor %s - matéria %s deletada com sucesso.", teacherID, subjectID)
+	r.logger.DebugContext(ctx, "matéria desassociada do professor", "teacher_id", teacherID, "subject_id", subjectID)
 	return nil
 }
 
 // GetSubjectsByTeacherID busca todas as matérias associadas a um professor.
-func (r *TeacherRepository) GetSubjectsByTeacherID(teacherID string) ([]models.Subject, error) {
+func (r *TeacherRepository) GetSubjectsByTeacherID(ctx context.Context, teacherID string) ([]models.Subject, error) {
 	query := `
-	SELECT s.id, s.name, s.year, s.credits
+	SELECT s.id, s.name, s.year, s.credits, s.mandatory
 	FROM subjects s
 	JOIN teacher_subjects ts ON s.id = ts.subject_id
-	WHERE ts.teacher_id = $1`
-	rows, err := r.db.Query(query, teacherID)
+	WHERE ts.teacher_id = $1 AND s.deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, teacherID)
 	if err != nil {
-		log.Printf("GetSubjectsByTeacherID: Erro ao executar query para professor ID %s: %v", teacherID, err)
+		r.logger.ErrorContext(ctx, "erro ao buscar matérias do professor", "teacher_id", teacherID, "error", err)
 		return nil, fmt.Errorf("falha ao buscar matérias por professor: %w", err)
 	}
 	defer rows.Close()
@@ -209,8 +438,8 @@ func (r *TeacherRepository) GetSubjectsByTeacherID(teacherID string) ([]models.S
 	var subjects []models.Subject
 	for rows.Next() {
 		subject := models.Subject{}
-		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits); err != nil {
-			log.Printf("GetSubjectsByTeacherID: Erro ao escanear matéria do professor ID %s: %v", teacherID, err)
+		if err := rows.Scan(&subject.ID, &subject.Name, &subject.Year, &subject.Credits, &subject.Mandatory); err != nil {
+			r.logger.ErrorContext(ctx, "erro ao escanear matéria do professor", "teacher_id", teacherID, "error", err)
 			return nil, fmt.Errorf("falha ao escanear matéria: %w", err)
 		}
 		subjects = append(subjects, subject)
@@ -220,9 +449,9 @@ func (r *TeacherRepository) GetSubjectsByTeacherID(teacherID string) ([]models.S
 	}
 
 	if subjects == nil {
-		log.Printf("GetSubjectsByTeacherID: Nenhuma matéria encontrada para professor ID %s (retornando slice vazio).", teacherID)
+		r.logger.DebugContext(ctx, "nenhuma matéria associada ao professor", "teacher_id", teacherID)
 		return []models.Subject{}, nil
 	}
-	log.Printf("GetSubjectsByTeacherID: %d matérias encontradas para professor ID %s.", len(subjects), teacherID)
+	r.logger.DebugContext(ctx, "matérias do professor encontradas", "teacher_id", teacherID, "count", len(subjects))
 	return subjects, nil
 }
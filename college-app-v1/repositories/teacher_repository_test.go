@@ -0,0 +1,23 @@
+// repositories/teacher_repository_test.go
+package repositories
+
+import "testing"
+
+func TestTeacherRegistry(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{name: "UUID em minúsculas", id: "3f2a9c1b-7d4e-4b8a-9c0d-1e2f3a4b5c6d", want: "PROF3F2A9C1B"},
+		{name: "UUID em maiúsculas", id: "ABCDEF12-0000-4000-8000-000000000000", want: "PROFABCDEF12"},
+		{name: "só dígitos", id: "12345678-0000-4000-8000-000000000000", want: "PROF12345678"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := teacherRegistry(tt.id); got != tt.want {
+				t.Errorf("teacherRegistry(%q) = %q, esperava %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}
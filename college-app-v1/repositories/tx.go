@@ -0,0 +1,56 @@
+// repositories/tx.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX é satisfeita tanto por *sql.DB quanto por *sql.Tx, permitindo que os
+// repositórios sejam usados dentro ou fora de uma transação.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Transactor executa operações de vários repositórios em uma única transação.
+type Transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor cria uma nova instância de Transactor.
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTx executa fn em uma transação: confirma se fn retornar nil e desfaz caso contrário.
+// Os repositórios devem ser obtidos com WithTx(tx) dentro de fn.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// WithinSnapshot executa fn em uma transação somente leitura com isolamento REPEATABLE READ, em
+// que todas as consultas enxergam o mesmo estado do banco (ex: cópia de segurança consistente).
+func (t *Transactor) WithinSnapshot(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback() // Somente leitura: nada a confirmar
+
+	return fn(tx)
+}
@@ -0,0 +1,27 @@
+// repositories/versioning.go
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrVersionConflict indica que o registro foi alterado por outra requisição desde que foi lido
+// (controle de concorrência otimista pela coluna version).
+var ErrVersionConflict = errors.New("registro modificado por outra requisição: versão desatualizada")
+
+// checkVersionConflict é chamado quando um UPDATE condicionado à versão não afetou nenhuma linha.
+// Retorna ErrVersionConflict se o registro ativo existe (logo a versão mudou) ou notFound caso contrário.
+// table vem sempre de constantes internas, nunca de entrada do usuário.
+func checkVersionConflict(ctx context.Context, db DBTX, table, id string, notFound error) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NULL)`, table)
+	if err := db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("falha ao verificar versão do registro em %s: %w", table, err)
+	}
+	if exists {
+		return ErrVersionConflict
+	}
+	return notFound
+}
@@ -0,0 +1,61 @@
+// reqctx/reqctx.go
+package reqctx
+
+import "context"
+
+// Pacote com os valores por requisição que atravessam handlers, serviços e repositórios
+// via context.Context (ID da requisição, autor da ação e permissão de administrador).
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	actorKey
+	adminKey
+)
+
+// AnonymousActor é o autor registrado quando a requisição não se identifica.
+const AnonymousActor = "anonymous"
+
+// AdminActor é o autor registrado para um administrador autenticado; com X-User-ID, o ID
+// declarado vem depois de "admin:".
+const AdminActor = "admin"
+
+// ClaimedActorPrefix antecede um X-User-ID enviado sem autenticação: é apenas a identidade
+// declarada pelo cliente, não verificada.
+const ClaimedActorPrefix = "claimed:"
+
+// WithRequestID retorna um contexto com o ID da requisição.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID retorna o ID da requisição, ou "" se não houver.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithActor retorna um contexto com o autor (usuário ou sistema) responsável pela requisição.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor retorna o autor da requisição, ou AnonymousActor se não houver.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// WithAdmin marca o contexto como pertencente a um administrador autenticado.
+func WithAdmin(ctx context.Context, admin bool) context.Context {
+	return context.WithValue(ctx, adminKey, admin)
+}
+
+// IsAdmin informa se a requisição foi autenticada como administrador.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
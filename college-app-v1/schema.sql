@@ -1,9 +1,46 @@
 -- Remover tabelas existentes para garantir um estado limpo (apenas para desenvolvimento)
 DROP TABLE IF EXISTS student_subjects;
 DROP TABLE IF EXISTS teacher_subjects;
+DROP TABLE IF EXISTS curriculum_subjects;
+DROP TABLE IF EXISTS curriculum_elective_buckets;
 DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS curricula;
+DROP TABLE IF EXISTS programs;
 DROP TABLE IF EXISTS subjects;
-DROP TABLE IF EXISTS teachers;
+DROP TABLE IF EXISTS teachers CASCADE;
+DROP TABLE IF EXISTS departments;
+DROP TABLE IF EXISTS rate_limit_buckets;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS schema_version;
+
+-- Cursos de graduação (ex: Sistemas de Informação)
+CREATE TABLE programs (
+    id VARCHAR(255) PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL, -- Código curto (ex: 'SI'), opcionalmente usado na matrícula
+    name VARCHAR(255) NOT NULL,
+    use_code_in_enrollment BOOLEAN NOT NULL DEFAULT FALSE, -- Matrículas no formato SI2025M0001
+    version INT NOT NULL DEFAULT 1 -- Incrementada a cada alteração (ETag / If-Match)
+);
+
+-- Versões da grade curricular de cada curso (imutáveis; mudanças geram nova versão)
+CREATE TABLE curricula (
+    id VARCHAR(255) PRIMARY KEY,
+    program_id VARCHAR(255) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    version INT NOT NULL, -- Número da versão da grade no curso
+    description TEXT NOT NULL DEFAULT '',
+    required_credits INT NOT NULL DEFAULT 0 CHECK (required_credits >= 0), -- Créditos aprovados exigidos para a formatura
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (program_id, version)
+);
+
+-- Grupos de eletivas da grade, com o mínimo de créditos aprovados exigido para a formatura
+CREATE TABLE curriculum_elective_buckets (
+    curriculum_id VARCHAR(255) REFERENCES curricula(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    min_credits INT NOT NULL CHECK (min_credits > 0),
+    PRIMARY KEY (curriculum_id, name)
+);
 
 -- Tabela de Estudantes
 CREATE TABLE students (
@@ -11,7 +48,11 @@ CREATE TABLE students (
     enrollment VARCHAR(255) UNIQUE NOT NULL, -- Matrícula do aluno, única
     name VARCHAR(255) NOT NULL,
     current_year INT NOT NULL, -- Ano atual do curso (ex: 1, 2, 3)
-    shift VARCHAR(50) NOT NULL -- Turno (ex: 'Manhã', 'Tarde', 'Noite')
+    shift VARCHAR(50) NOT NULL, -- Turno (ex: 'Manhã', 'Tarde', 'Noite')
+    program_id VARCHAR(255) REFERENCES programs(id), -- Curso do aluno (opcional)
+    curriculum_id VARCHAR(255) REFERENCES curricula(id), -- Versão da grade seguida pelo aluno
+    version INT NOT NULL DEFAULT 1, -- Incrementada a cada alteração (ETag / If-Match)
+    deleted_at TIMESTAMPTZ -- Soft delete: preenchido na exclusão, NULL se ativo
 );
 
 -- Tabela de Matérias
@@ -19,7 +60,19 @@ CREATE TABLE subjects (
     id VARCHAR(255) PRIMARY KEY,
     name VARCHAR(255) UNIQUE NOT NULL,
     year INT NOT NULL, -- Ano em que a matéria é oferecida (ex: 1º ano, 2º ano)
-    credits INT NOT NULL
+    credits INT NOT NULL,
+    mandatory BOOLEAN NOT NULL DEFAULT TRUE, -- Obrigatória no ano (matrícula automática do currículo)
+    version INT NOT NULL DEFAULT 1, -- Incrementada a cada alteração (ETag / If-Match)
+    deleted_at TIMESTAMPTZ -- Soft delete: preenchido na exclusão, NULL se ativo
+);
+
+-- Departamentos acadêmicos (ex: Ciência da Computação)
+CREATE TABLE departments (
+    id VARCHAR(255) PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL, -- Código curto (ex: 'DCC')
+    name VARCHAR(255) UNIQUE NOT NULL,
+    head_teacher_id VARCHAR(255), -- Chefe do departamento (FK adicionada após criar teachers)
+    version INT NOT NULL DEFAULT 1 -- Incrementada a cada alteração (ETag / If-Match)
 );
 
 -- Tabela de Professores
@@ -28,7 +81,24 @@ CREATE TABLE teachers (
     registry VARCHAR(255) UNIQUE NOT NULL, -- <-- ADICIONADO: Registro único do professor
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) UNIQUE NOT NULL,
-    department VARCHAR(255) -- Departamento do professor (ex: "Ciência da Computação")
+    department_id VARCHAR(255) REFERENCES departments(id), -- Departamento do professor
+    contract_type VARCHAR(20) NOT NULL DEFAULT 'integral' CHECK (contract_type IN ('integral', 'parcial', 'horista')), -- Define a carga horária máxima
+    version INT NOT NULL DEFAULT 1, -- Incrementada a cada alteração (ETag / If-Match)
+    deleted_at TIMESTAMPTZ -- Soft delete: preenchido na exclusão, NULL se ativo
+);
+
+ALTER TABLE departments ADD CONSTRAINT departments_head_teacher_id_fkey
+    FOREIGN KEY (head_teacher_id) REFERENCES teachers(id) ON DELETE SET NULL;
+
+-- Matérias de cada versão da grade, por ano, obrigatórias ou eletivas
+CREATE TABLE curriculum_subjects (
+    curriculum_id VARCHAR(255) REFERENCES curricula(id) ON DELETE CASCADE,
+    subject_id VARCHAR(255) REFERENCES subjects(id) ON DELETE CASCADE,
+    year INT NOT NULL CHECK (year > 0), -- Ano do curso em que a matéria é cursada nesta grade
+    mandatory BOOLEAN NOT NULL DEFAULT TRUE, -- Obrigatória ou eletiva
+    bucket VARCHAR(255), -- Grupo de eletivas da matéria (NULL para obrigatórias)
+    PRIMARY KEY (curriculum_id, subject_id),
+    FOREIGN KEY (curriculum_id, bucket) REFERENCES curriculum_elective_buckets(curriculum_id, name)
 );
 
 -- Tabela de associação Aluno-Matéria (muitos-para-muitos)
@@ -36,6 +106,7 @@ CREATE TABLE teachers (
 CREATE TABLE student_subjects (
     student_id VARCHAR(255) REFERENCES students(id) ON DELETE CASCADE,
     subject_id VARCHAR(255) REFERENCES subjects(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'enrolled' CHECK (status IN ('enrolled', 'passed', 'failed')), -- Situação do aluno na matéria
     PRIMARY KEY (student_id, subject_id)
 );
 
@@ -47,11 +118,67 @@ CREATE TABLE teacher_subjects (
     PRIMARY KEY (teacher_id, subject_id)
 );
 
+-- Buckets do rate limiter (RATE_LIMIT_STORE=postgres), compartilhados entre instâncias
+CREATE TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Respostas guardadas por Idempotency-Key (IDEMPOTENCY_STORE=postgres)
+CREATE TABLE idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY, -- Hash do autor + Idempotency-Key
+    fingerprint VARCHAR(64) NOT NULL, -- Hash do método, rota e corpo da requisição original
+    status_code INT, -- NULL enquanto a requisição original está em andamento
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Log de auditoria (append-only) de todas as mutações feitas pelos serviços
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor VARCHAR(255) NOT NULL, -- 'admin', 'admin:<X-User-ID>', 'claimed:<X-User-ID>' ou 'anonymous'
+    request_id VARCHAR(128),
+    entity_type VARCHAR(50) NOT NULL, -- 'student', 'teacher', 'subject', 'program' ou 'department'
+    entity_id VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    before JSONB,
+    after JSONB,
+    changes JSONB -- Diff campo a campo: {"campo": {"from": ..., "to": ...}}
+);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events é append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_mutation BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- Versão do esquema aplicada (linha única), conferida pelo /readyz (config.SchemaVersion)
+CREATE TABLE schema_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version INT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+INSERT INTO schema_version (id, version) VALUES (TRUE, 3);
+
 -- Índices para melhor performance em colunas frequentemente usadas em buscas ou junções
 CREATE INDEX idx_students_enrollment ON students(enrollment);
+CREATE INDEX idx_students_program_id ON students(program_id);
 CREATE INDEX idx_subjects_name ON subjects(name);
+CREATE INDEX idx_subjects_year ON subjects(year) WHERE deleted_at IS NULL;
 CREATE INDEX idx_teachers_email ON teachers(email);
+CREATE INDEX idx_teachers_department_id ON teachers(department_id);
 CREATE INDEX idx_student_subjects_student_id ON student_subjects(student_id);
 CREATE INDEX idx_student_subjects_subject_id ON student_subjects(subject_id);
 CREATE INDEX idx_teacher_subjects_teacher_id ON teacher_subjects(teacher_id);
-CREATE INDEX idx_teacher_subjects_subject_id ON teacher_subjects(subject_id);
\ No newline at end of file
+CREATE INDEX idx_teacher_subjects_subject_id ON teacher_subjects(subject_id);
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, id DESC);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,136 @@
+// seed/apply.go
+package seed
+
+import (
+	"college-app-v1/services"
+	"college-app-v1/tracing"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Services são os serviços usados para gravar os dados, com as mesmas validações, geração de
+// matrícula e auditoria da API.
+type Services struct {
+	Departments  *services.DepartmentService
+	Subjects     *services.SubjectService
+	Teachers     *services.TeacherService
+	Students     *services.StudentService
+	Associations *services.AssociationService
+}
+
+// Result resume os registros gravados.
+type Result struct {
+	Seed            int64 `json:"seed"`
+	DryRun          bool  `json:"dry_run"`
+	Departments     int   `json:"departments"`
+	Subjects        int   `json:"subjects"`
+	Teachers        int   `json:"teachers"`
+	Students        int   `json:"students"`
+	TeacherSubjects int   `json:"teacher_subjects"` // Associações professor-matéria
+	StudentSubjects int   `json:"student_subjects"` // Associações aluno-matéria
+}
+
+// Summary resume d sem gravá-lo (dry run).
+func (d *Dataset) Summary() *Result {
+	result := &Result{Seed: d.Seed, DryRun: true, Departments: len(d.Departments), Subjects: len(d.Subjects),
+		Teachers: len(d.Teachers), Students: len(d.Students)}
+	for _, subjects := range d.TeacherSubjects {
+		result.TeacherSubjects += len(subjects)
+	}
+	for _, students := range d.SubjectStudents {
+		result.StudentSubjects += len(students)
+	}
+	return result
+}
+
+// Apply grava d pelos serviços, na ordem: departamentos, matérias, professores, alunos e
+// associações. Os registros de d recebem os IDs e matrículas gerados. Cada registro é gravado
+// em sua própria transação: em caso de erro, o que já foi gravado permanece.
+func Apply(ctx context.Context, svc Services, d *Dataset, logger *slog.Logger) (*Result, error) {
+	ctx, span := tracing.Start(ctx, "seed.Apply")
+	defer span.End()
+	result := &Result{Seed: d.Seed}
+
+	for i := range d.Departments {
+		if err := svc.Departments.CreateDepartment(ctx, &d.Departments[i]); err != nil {
+			return result, fmt.Errorf("departamento %s: %w", d.Departments[i].Code, err)
+		}
+		result.Departments++
+	}
+	for i := range d.Subjects {
+		if err := svc.Subjects.CreateSubject(ctx, &d.Subjects[i]); err != nil {
+			return result, fmt.Errorf("matéria %s: %w", d.Subjects[i].Name, err)
+		}
+		result.Subjects++
+	}
+	for i := range d.Teachers {
+		if err := svc.Teachers.CreateTeacher(ctx, &d.Teachers[i]); err != nil {
+			return result, fmt.Errorf("professor %s: %w", d.Teachers[i].Registry, err)
+		}
+		result.Teachers++
+	}
+	logger.InfoContext(ctx, "seed: cadastros gravados", "departments", result.Departments, "subjects", result.Subjects, "teachers", result.Teachers)
+
+	for i := range d.Students {
+		if err := svc.Students.CreateStudent(ctx, &d.Students[i]); err != nil {
+			return result, fmt.Errorf("aluno %s: %w", d.Students[i].Name, err)
+		}
+		result.Students++
+		if result.Students%500 == 0 {
+			logger.InfoContext(ctx, "seed: alunos gravados", "students", result.Students, "total", len(d.Students))
+		}
+	}
+
+	for teacher, subjects := range d.TeacherSubjects {
+		if len(subjects) == 0 {
+			continue
+		}
+		ids := make([]string, len(subjects))
+		for i, subject := range subjects {
+			ids[i] = d.Subjects[subject].ID
+		}
+		added, err := associate(svc.Associations.ReplaceTeacherSubjects(ctx, d.Teachers[teacher].ID, ids))
+		if err != nil {
+			return result, fmt.Errorf("matérias do professor %s: %w", d.Teachers[teacher].Registry, err)
+		}
+		result.TeacherSubjects += added
+	}
+	for subject, students := range d.SubjectStudents {
+		if len(students) == 0 {
+			continue
+		}
+		ids := make([]string, len(students))
+		for i, student := range students {
+			ids[i] = d.Students[student].ID
+		}
+		// EnrollStudentsInSubject mantém os alunos já associados, então os lotes (limitados a
+		// MaxAssociationItems IDs) se somam
+		for start := 0; start < len(ids); start += services.MaxAssociationItems {
+			end := min(start+services.MaxAssociationItems, len(ids))
+			added, err := associate(svc.Associations.EnrollStudentsInSubject(ctx, d.Subjects[subject].ID, ids[start:end]))
+			if err != nil {
+				return result, fmt.Errorf("alunos da matéria %s: %w", d.Subjects[subject].Name, err)
+			}
+			result.StudentSubjects += added
+		}
+	}
+	logger.InfoContext(ctx, "seed: associações gravadas", "teacher_subjects", result.TeacherSubjects, "student_subjects", result.StudentSubjects)
+	return result, nil
+}
+
+// associate converte uma associação recusada em erro, com a mensagem do primeiro item com falha.
+func associate(result *services.AssociationResult, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	if !result.Applied {
+		for _, item := range result.Items {
+			if item.Message != "" {
+				return 0, fmt.Errorf("associação recusada: %s: %s", item.ID, item.Message)
+			}
+		}
+		return 0, fmt.Errorf("associação recusada: %d itens com falha", result.Failed)
+	}
+	return result.Added, nil
+}
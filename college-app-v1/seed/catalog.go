@@ -0,0 +1,69 @@
+// seed/catalog.go
+package seed
+
+import "strings"
+
+// departmentEntry é um departamento do catálogo, com as matérias que seus professores lecionam.
+type departmentEntry struct {
+	code     string
+	name     string
+	subjects []string
+}
+
+// catalog lista os departamentos na ordem em que são usados (os primeiros N, conforme o tamanho).
+var catalog = []departmentEntry{
+	{"DCC", "Ciência da Computação", []string{
+		"Algoritmos", "Estruturas de Dados", "Programação Orientada a Objetos", "Banco de Dados",
+		"Redes de Computadores", "Sistemas Operacionais", "Engenharia de Software", "Compiladores",
+		"Inteligência Artificial", "Computação Gráfica",
+	}},
+	{"DMAT", "Matemática", []string{
+		"Cálculo", "Álgebra Linear", "Geometria Analítica", "Matemática Discreta",
+		"Equações Diferenciais", "Análise Real", "Cálculo Numérico",
+	}},
+	{"DEST", "Estatística", []string{
+		"Probabilidade", "Estatística", "Inferência Estatística", "Análise de Regressão", "Séries Temporais",
+	}},
+	{"DFIS", "Física", []string{
+		"Física Geral", "Mecânica Clássica", "Eletromagnetismo", "Óptica", "Termodinâmica",
+	}},
+	{"DELE", "Engenharia Elétrica", []string{
+		"Circuitos Elétricos", "Eletrônica Digital", "Arquitetura de Computadores", "Sinais e Sistemas",
+		"Sistemas Embarcados",
+	}},
+	{"DADM", "Administração", []string{
+		"Gestão de Projetos", "Empreendedorismo", "Contabilidade", "Economia", "Marketing",
+	}},
+	{"DLET", "Letras", []string{
+		"Português Instrumental", "Inglês Técnico", "Redação Acadêmica", "Comunicação e Expressão",
+	}},
+	{"DFIL", "Filosofia", []string{
+		"Ética", "Lógica", "Metodologia Científica", "Filosofia da Ciência",
+	}},
+}
+
+var firstNames = []string{
+	"Ana", "Beatriz", "Bruno", "Camila", "Carlos", "Daniel", "Eduarda", "Felipe", "Fernanda", "Gabriel",
+	"Giovana", "Gustavo", "Helena", "Igor", "Isabela", "João", "Juliana", "Larissa", "Leonardo", "Letícia",
+	"Lucas", "Luíza", "Marcelo", "Mariana", "Matheus", "Natália", "Otávio", "Paula", "Pedro", "Rafael",
+	"Renata", "Rodrigo", "Sofia", "Thiago", "Valentina", "Vinícius",
+}
+
+var lastNames = []string{
+	"Almeida", "Alves", "Araújo", "Barbosa", "Cardoso", "Carvalho", "Castro", "Costa", "Dias", "Ferreira",
+	"Gomes", "Lima", "Martins", "Melo", "Mendes", "Nascimento", "Oliveira", "Pereira", "Ribeiro", "Rocha",
+	"Rodrigues", "Santos", "Silva", "Soares", "Sousa", "Teixeira", "Vieira",
+}
+
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+
+// accents remove os acentos dos nomes usados nos emails.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c",
+)
+
+// emailLocalPart monta a parte local do email a partir do nome (ex: "João Araújo" → "joao.araujo").
+func emailLocalPart(first, last string) string {
+	return accents.Replace(strings.ToLower(first + "." + last))
+}
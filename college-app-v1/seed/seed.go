@@ -0,0 +1,222 @@
+// seed/seed.go
+
+// Package seed gera um conjunto de dados fictício e realista (departamentos, matérias por ano,
+// professores, alunos por ano e turno e suas associações) e o grava pelos serviços da aplicação.
+// A geração é determinística: a mesma semente e o mesmo tamanho produzem os mesmos dados. IDs e
+// matrículas são gerados pelos serviços na gravação e, em um banco vazio, também se repetem
+// (exceto os IDs e o ano corrente da matrícula).
+package seed
+
+import (
+	"college-app-v1/models"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Size define a quantidade de registros gerados.
+type Size struct {
+	Departments           int // No máximo len(catalog)
+	TeachersPerDepartment int
+	Years                 int // Anos do curso (matérias e alunos de 1 a Years)
+	SubjectsPerYear       int
+	StudentsPerYearShift  int // Alunos por ano e turno
+}
+
+// Sizes são os tamanhos predefinidos; "load" é voltado a testes de carga.
+var Sizes = map[string]Size{
+	"small":  {Departments: 3, TeachersPerDepartment: 2, Years: 4, SubjectsPerYear: 4, StudentsPerYearShift: 5},
+	"medium": {Departments: 5, TeachersPerDepartment: 5, Years: 4, SubjectsPerYear: 8, StudentsPerYearShift: 50},
+	"large":  {Departments: 8, TeachersPerDepartment: 12, Years: 5, SubjectsPerYear: 10, StudentsPerYearShift: 300},
+	"load":   {Departments: 8, TeachersPerDepartment: 30, Years: 5, SubjectsPerYear: 12, StudentsPerYearShift: 2000},
+}
+
+// SizeNames lista os tamanhos predefinidos em ordem crescente.
+var SizeNames = []string{"small", "medium", "large", "load"}
+
+// capacity é a carga horária semanal usada para distribuir as matérias entre os professores,
+// igual aos limites padrão por regime (1 hora por crédito), para que os dados gerados não
+// deixem professores sobrecarregados com a configuração padrão.
+var capacity = map[string]int{
+	models.ContractTypeIntegral: 20,
+	models.ContractTypeParcial:  12,
+	models.ContractTypeHorista:  8,
+}
+
+// Dataset é o conjunto de dados gerado. As associações referenciam matérias e alunos pela
+// posição nas listas, pois os IDs só existem após a gravação.
+type Dataset struct {
+	Seed            int64
+	Departments     []models.Department
+	Subjects        []models.Subject
+	Teachers        []models.Teacher // Department traz o código do departamento
+	Students        []models.Student
+	TeacherSubjects [][]int // Matérias (índices em Subjects) de cada professor
+	SubjectStudents [][]int // Alunos (índices em Students) de cada matéria
+}
+
+// Generate gera os dados de size com a semente seed. shifts são os códigos de turno dos alunos
+// (ex: M, T e N), distribuídos igualmente em cada ano.
+func Generate(seed int64, size Size, shifts []string) (*Dataset, error) {
+	if size.Departments < 1 || size.Departments > len(catalog) {
+		return nil, fmt.Errorf("quantidade de departamentos deve estar entre 1 e %d", len(catalog))
+	}
+	if size.TeachersPerDepartment < 1 || size.Years < 1 || size.SubjectsPerYear < 1 || size.StudentsPerYearShift < 0 {
+		return nil, fmt.Errorf("tamanho inválido: %+v", size)
+	}
+	if len(shifts) == 0 {
+		return nil, fmt.Errorf("nenhum turno configurado")
+	}
+	shifts = append([]string(nil), shifts...)
+	sort.Strings(shifts)
+
+	g := &generator{rng: rand.New(rand.NewSource(seed)), emails: map[string]int{}}
+	d := &Dataset{Seed: seed}
+	departments := catalog[:size.Departments]
+	for _, entry := range departments {
+		d.Departments = append(d.Departments, models.Department{Code: entry.code, Name: entry.name})
+	}
+
+	// Matérias: cada ano recebe matérias dos departamentos em rodízio; nomes sorteados mais de
+	// uma vez ganham numeração romana (Cálculo I, Cálculo II, ...).
+	subjectDepartment := []int{}
+	ordinals := []int{}
+	used := map[string]int{}
+	for year := 1; year <= size.Years; year++ {
+		for i := 0; i < size.SubjectsPerYear; i++ {
+			dept := ((year-1)*size.SubjectsPerYear + i) % len(departments)
+			pool := departments[dept].subjects
+			base := pool[g.rng.Intn(len(pool))]
+			used[base]++
+			d.Subjects = append(d.Subjects, models.Subject{
+				Name:      base,
+				Year:      year,
+				Credits:   2 + g.rng.Intn(3), // 2 a 4 créditos
+				Mandatory: g.rng.Intn(5) > 0, // 80% obrigatórias
+			})
+			subjectDepartment = append(subjectDepartment, dept)
+			ordinals = append(ordinals, used[base])
+		}
+	}
+	for i := range d.Subjects {
+		if used[d.Subjects[i].Name] > 1 {
+			d.Subjects[i].Name = numbered(d.Subjects[i].Name, ordinals[i])
+		}
+	}
+
+	// Professores de cada departamento, com registro sequencial e email derivado do nome
+	teacherDepartment := []int{}
+	for dept, entry := range departments {
+		for i := 0; i < size.TeachersPerDepartment; i++ {
+			first, last := g.name()
+			d.Teachers = append(d.Teachers, models.Teacher{
+				Registry:     fmt.Sprintf("PROF%04d", len(d.Teachers)+1),
+				Name:         first + " " + last,
+				Email:        g.email(first, last),
+				Department:   entry.code,
+				ContractType: g.contractType(),
+			})
+			teacherDepartment = append(teacherDepartment, dept)
+		}
+	}
+	d.TeacherSubjects = assignTeachers(d, subjectDepartment, teacherDepartment)
+
+	// Alunos por ano e turno, cursando as obrigatórias do seu ano e, com 50% de chance, cada eletiva
+	d.SubjectStudents = make([][]int, len(d.Subjects))
+	for year := 1; year <= size.Years; year++ {
+		for _, shift := range shifts {
+			for i := 0; i < size.StudentsPerYearShift; i++ {
+				first, last := g.name()
+				student := len(d.Students)
+				d.Students = append(d.Students, models.Student{Name: first + " " + g.lastName() + " " + last, CurrentYear: year, Shift: shift})
+				for subject := range d.Subjects {
+					if d.Subjects[subject].Year == year && (d.Subjects[subject].Mandatory || g.rng.Intn(2) == 0) {
+						d.SubjectStudents[subject] = append(d.SubjectStudents[subject], student)
+					}
+				}
+			}
+		}
+	}
+	return d, nil
+}
+
+// assignTeachers distribui cada matéria a um professor do mesmo departamento, escolhendo o de
+// menor ocupação relativa à sua capacidade (empates pela ordem de cadastro). Se o departamento
+// não tiver capacidade livre, a matéria vai para o professor menos ocupado de qualquer departamento.
+func assignTeachers(d *Dataset, subjectDepartment, teacherDepartment []int) [][]int {
+	assigned := make([][]int, len(d.Teachers))
+	load := make([]int, len(d.Teachers))
+	best := func(subject int, sameDepartment bool) int {
+		chosen := -1
+		for teacher := range d.Teachers {
+			if sameDepartment && teacherDepartment[teacher] != subjectDepartment[subject] {
+				continue
+			}
+			limit := capacity[d.Teachers[teacher].ContractType]
+			if load[teacher]+d.Subjects[subject].Credits > limit {
+				continue
+			}
+			if chosen < 0 || load[teacher]*capacity[d.Teachers[chosen].ContractType] < load[chosen]*limit {
+				chosen = teacher
+			}
+		}
+		return chosen
+	}
+	for subject := range d.Subjects {
+		teacher := best(subject, true)
+		if teacher < 0 {
+			teacher = best(subject, false)
+		}
+		if teacher < 0 {
+			continue // Sem capacidade em nenhum professor: a matéria fica sem professor
+		}
+		load[teacher] += d.Subjects[subject].Credits
+		assigned[teacher] = append(assigned[teacher], subject)
+	}
+	return assigned
+}
+
+// numbered acrescenta a numeração romana n ao nome de uma matéria (ex: "Cálculo II").
+func numbered(name string, n int) string {
+	if n <= len(romanNumerals) {
+		return name + " " + romanNumerals[n-1]
+	}
+	return fmt.Sprintf("%s %d", name, n)
+}
+
+// generator reúne o gerador pseudoaleatório e o estado que garante emails únicos.
+type generator struct {
+	rng    *rand.Rand
+	emails map[string]int
+}
+
+// name sorteia um nome e um sobrenome.
+func (g *generator) name() (string, string) {
+	return firstNames[g.rng.Intn(len(firstNames))], g.lastName()
+}
+
+func (g *generator) lastName() string {
+	return lastNames[g.rng.Intn(len(lastNames))]
+}
+
+// email gera um email institucional único (ex: joao.araujo@universidade.edu.br, joao.araujo2@...).
+func (g *generator) email(first, last string) string {
+	local := emailLocalPart(first, last)
+	g.emails[local]++
+	if n := g.emails[local]; n > 1 {
+		local = fmt.Sprintf("%s%d", local, n)
+	}
+	return local + "@universidade.edu.br"
+}
+
+// contractType sorteia o regime de contratação: 70% integral, 20% parcial e 10% horista.
+func (g *generator) contractType() string {
+	switch n := g.rng.Intn(10); {
+	case n < 7:
+		return models.ContractTypeIntegral
+	case n < 9:
+		return models.ContractTypeParcial
+	default:
+		return models.ContractTypeHorista
+	}
+}
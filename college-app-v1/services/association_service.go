@@ -0,0 +1,393 @@
+// services/association_service.go
+package services
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// MaxAssociationItems limita a quantidade de IDs de uma associação em lote.
+const MaxAssociationItems = 1000
+
+// Situação de cada item no resultado de uma associação em lote.
+const (
+	AssociationAdded     = "added"     // Associação criada
+	AssociationRemoved   = "removed"   // Associação removida (apenas na substituição do conjunto)
+	AssociationUnchanged = "unchanged" // Já estava associado
+	AssociationNotFound  = "not_found" // ID inexistente ou excluído; impede a gravação do lote
+	AssociationSkipped   = "skipped"   // Matéria do currículo já aprovada, não matriculada novamente
+	// Professor acima da carga horária máxima com a política "reject"; impede a gravação do lote
+	AssociationWorkloadExceeded = "workload_exceeded"
+)
+
+var (
+	// ErrAssociationOwnerNotFound indica que o aluno, professor ou matéria da URL não existe.
+	ErrAssociationOwnerNotFound = errors.New("registro não encontrado para associação")
+	// ErrInvalidAssociationRequest indica uma lista de IDs vazia (quando não permitido) ou grande demais.
+	ErrInvalidAssociationRequest = errors.New("lista de IDs inválida")
+
+	// errAssociationRejected desfaz a transação quando algum item do lote é inválido.
+	errAssociationRejected = errors.New("associação em lote rejeitada")
+)
+
+// AssociationItemResult é a situação de um ID em uma associação em lote.
+type AssociationItemResult struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// AssociationResult é o resumo de uma associação em lote. Se algum item falhar, nada é gravado
+// (Applied false) e os itens indicam quais IDs impediram a operação.
+type AssociationResult struct {
+	Entity    string                  `json:"entity"` // Entidade dos itens: "student", "teacher" ou "subject"
+	ID        string                  `json:"id"`     // Registro da URL ao qual os itens são associados
+	Applied   bool                    `json:"applied"`
+	Added     int                     `json:"added"`
+	Removed   int                     `json:"removed"`
+	Unchanged int                     `json:"unchanged"`
+	Skipped   int                     `json:"skipped"`
+	Failed    int                     `json:"failed"`
+	Items     []AssociationItemResult `json:"items"`
+}
+
+// AssociationService associa matérias a alunos e professores em lote, em uma única transação.
+type AssociationService struct {
+	transactor  *repositories.Transactor
+	studentRepo *repositories.StudentRepository
+	teacherRepo *repositories.TeacherRepository
+	subjectRepo *repositories.SubjectRepository
+	workload    WorkloadPolicy
+	audit       *AuditService
+}
+
+// NewAssociationService cria uma nova instância de AssociationService.
+func NewAssociationService(transactor *repositories.Transactor, sr *repositories.StudentRepository, tr *repositories.TeacherRepository, subR *repositories.SubjectRepository, workload WorkloadPolicy, audit *AuditService) *AssociationService {
+	return &AssociationService{transactor: transactor, studentRepo: sr, teacherRepo: tr, subjectRepo: subR, workload: workload, audit: audit}
+}
+
+// associationPlan descreve uma associação em lote de forma independente da entidade.
+type associationPlan struct {
+	result  *AssociationResult
+	replace bool // Substitui o conjunto inteiro (remove o que não foi enviado)
+	// load verifica o registro da URL e retorna os IDs associados atualmente.
+	load func(tx *sql.Tx) ([]string, error)
+	// active retorna, dentre ids, os itens existentes e não excluídos.
+	active func(tx *sql.Tx, ids []string) (map[string]bool, error)
+	link   func(tx *sql.Tx, itemID string) error
+	unlink func(tx *sql.Tx, itemID string) error
+	// audit registra a alteração de um item na transação, depois de todas as gravações.
+	audit func(tx *sql.Tx, itemID, status string) error
+	// overloaded, se definido, é chamado após a gravação (ainda na transação) com os itens
+	// adicionados e retorna os que deixaram algum professor acima da carga horária máxima.
+	overloaded func(tx *sql.Tx, added []string) (map[string]string, error)
+}
+
+// ReplaceStudentSubjects substitui o conjunto de matérias de um aluno por subjectIDs.
+func (s *AssociationService) ReplaceStudentSubjects(ctx context.Context, studentID string, subjectIDs []string) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "AssociationService.ReplaceStudentSubjects")
+	defer span.End()
+	return s.run(ctx, subjectIDs, &associationPlan{
+		result:  &AssociationResult{Entity: AuditEntitySubject, ID: studentID},
+		replace: true,
+		load: func(tx *sql.Tx) ([]string, error) {
+			student, err := s.studentRepo.WithTx(tx).GetStudentByID(ctx, studentID, false)
+			if err != nil {
+				if errors.Is(err, ErrStudentNotFound) {
+					return nil, fmt.Errorf("aluno com ID %s não encontrado: %w", studentID, ErrAssociationOwnerNotFound)
+				}
+				return nil, err
+			}
+			return subjectIDsOf(student.Subjects), nil
+		},
+		active: func(tx *sql.Tx, ids []string) (map[string]bool, error) {
+			return s.subjectRepo.WithTx(tx).ActiveSubjectIDs(ctx, ids)
+		},
+		link: func(tx *sql.Tx, subjectID string) error {
+			return s.studentRepo.WithTx(tx).AddSubjectToStudent(ctx, studentID, subjectID)
+		},
+		unlink: func(tx *sql.Tx, subjectID string) error {
+			return s.studentRepo.WithTx(tx).RemoveSubjectFromStudent(ctx, studentID, subjectID)
+		},
+		audit: func(tx *sql.Tx, subjectID, status string) error {
+			return s.recordSubjectChange(ctx, tx, AuditEntityStudent, studentID, subjectID, status)
+		},
+	})
+}
+
+// EnrollStudentsInSubject associa vários alunos a uma matéria, mantendo os já associados.
+func (s *AssociationService) EnrollStudentsInSubject(ctx context.Context, subjectID string, studentIDs []string) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "AssociationService.EnrollStudentsInSubject")
+	defer span.End()
+	return s.run(ctx, studentIDs, &associationPlan{
+		result: &AssociationResult{Entity: AuditEntityStudent, ID: subjectID},
+		load: func(tx *sql.Tx) ([]string, error) {
+			if err := s.checkSubject(ctx, tx, subjectID); err != nil {
+				return nil, err
+			}
+			return s.studentRepo.WithTx(tx).GetStudentIDsBySubjectID(ctx, subjectID)
+		},
+		active: func(tx *sql.Tx, ids []string) (map[string]bool, error) {
+			return s.studentRepo.WithTx(tx).ActiveStudentIDs(ctx, ids)
+		},
+		link: func(tx *sql.Tx, studentID string) error {
+			return s.studentRepo.WithTx(tx).AddSubjectToStudent(ctx, studentID, subjectID)
+		},
+		audit: func(tx *sql.Tx, studentID, status string) error {
+			return s.recordSubjectChange(ctx, tx, AuditEntityStudent, studentID, subjectID, status)
+		},
+	})
+}
+
+// ReplaceTeacherSubjects substitui o conjunto de matérias de um professor por subjectIDs.
+func (s *AssociationService) ReplaceTeacherSubjects(ctx context.Context, teacherID string, subjectIDs []string) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "AssociationService.ReplaceTeacherSubjects")
+	defer span.End()
+	return s.run(ctx, subjectIDs, &associationPlan{
+		result:  &AssociationResult{Entity: AuditEntitySubject, ID: teacherID},
+		replace: true,
+		load: func(tx *sql.Tx) ([]string, error) {
+			teacher, err := s.teacherRepo.WithTx(tx).GetTeacherByID(ctx, teacherID, false)
+			if err != nil {
+				if errors.Is(err, ErrTeacherNotFound) {
+					return nil, fmt.Errorf("professor com ID %s não encontrado: %w", teacherID, ErrAssociationOwnerNotFound)
+				}
+				return nil, err
+			}
+			return subjectIDsOf(teacher.Subjects), nil
+		},
+		active: func(tx *sql.Tx, ids []string) (map[string]bool, error) {
+			return s.subjectRepo.WithTx(tx).ActiveSubjectIDs(ctx, ids)
+		},
+		link: func(tx *sql.Tx, subjectID string) error {
+			return s.teacherRepo.WithTx(tx).AddSubjectToTeacher(ctx, teacherID, subjectID)
+		},
+		unlink: func(tx *sql.Tx, subjectID string) error {
+			return s.teacherRepo.WithTx(tx).RemoveSubjectFromTeacher(ctx, teacherID, subjectID)
+		},
+		audit: func(tx *sql.Tx, subjectID, status string) error {
+			return s.recordSubjectChange(ctx, tx, AuditEntityTeacher, teacherID, subjectID, status)
+		},
+		overloaded: func(tx *sql.Tx, added []string) (map[string]string, error) {
+			exceeded, err := s.exceededWorkloads(ctx, tx, []string{teacherID})
+			if err != nil || len(exceeded) == 0 {
+				return nil, err
+			}
+			messages := make(map[string]string, len(added))
+			for _, subjectID := range added {
+				messages[subjectID] = exceeded[teacherID]
+			}
+			return messages, nil
+		},
+	})
+}
+
+// AssignTeachersToSubject associa vários professores a uma matéria, mantendo os já associados.
+func (s *AssociationService) AssignTeachersToSubject(ctx context.Context, subjectID string, teacherIDs []string) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "AssociationService.AssignTeachersToSubject")
+	defer span.End()
+	return s.run(ctx, teacherIDs, &associationPlan{
+		result: &AssociationResult{Entity: AuditEntityTeacher, ID: subjectID},
+		load: func(tx *sql.Tx) ([]string, error) {
+			if err := s.checkSubject(ctx, tx, subjectID); err != nil {
+				return nil, err
+			}
+			return s.teacherRepo.WithTx(tx).GetTeacherIDsBySubjectID(ctx, subjectID)
+		},
+		active: func(tx *sql.Tx, ids []string) (map[string]bool, error) {
+			return s.teacherRepo.WithTx(tx).ActiveTeacherIDs(ctx, ids)
+		},
+		link: func(tx *sql.Tx, teacherID string) error {
+			return s.teacherRepo.WithTx(tx).AddSubjectToTeacher(ctx, teacherID, subjectID)
+		},
+		audit: func(tx *sql.Tx, teacherID, status string) error {
+			return s.recordSubjectChange(ctx, tx, AuditEntityTeacher, teacherID, subjectID, status)
+		},
+		overloaded: func(tx *sql.Tx, added []string) (map[string]string, error) {
+			return s.exceededWorkloads(ctx, tx, added)
+		},
+	})
+}
+
+// exceededWorkloads retorna, dentre teacherIDs, os professores acima da carga horária máxima,
+// com a mensagem de sobrecarga de cada um.
+func (s *AssociationService) exceededWorkloads(ctx context.Context, tx *sql.Tx, teacherIDs []string) (map[string]string, error) {
+	if len(teacherIDs) == 0 {
+		return nil, nil
+	}
+	workloads, err := s.teacherRepo.WithTx(tx).GetTeacherWorkloads(ctx, "", teacherIDs)
+	if err != nil {
+		return nil, err
+	}
+	exceeded := map[string]string{}
+	for i := range workloads {
+		s.workload.apply(&workloads[i])
+		if workloads[i].Overloaded {
+			exceeded[workloads[i].TeacherID] = exceededMessage(&workloads[i])
+		}
+	}
+	return exceeded, nil
+}
+
+// run executa o plano em uma transação: todos os IDs enviados são verificados e, se algum
+// falhar, nada é gravado e o resultado (Applied false) indica os itens inválidos.
+func (s *AssociationService) run(ctx context.Context, ids []string, plan *associationPlan) (*AssociationResult, error) {
+	requested := uniqueIDs(ids)
+	if len(requested) == 0 && !plan.replace {
+		return nil, fmt.Errorf("%w: informe ao menos um ID", ErrInvalidAssociationRequest)
+	}
+	if len(requested) > MaxAssociationItems {
+		return nil, fmt.Errorf("%w: máximo de %d IDs por requisição", ErrInvalidAssociationRequest, MaxAssociationItems)
+	}
+
+	result := plan.result
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		result.Items = []AssociationItemResult{}
+		result.Added, result.Removed, result.Unchanged, result.Failed = 0, 0, 0, 0
+
+		current, err := plan.load(tx)
+		if err != nil {
+			return err
+		}
+		active, err := plan.active(tx, requested)
+		if err != nil {
+			return err
+		}
+		associated := make(map[string]bool, len(current))
+		for _, id := range current {
+			associated[id] = true
+		}
+
+		keep := make(map[string]bool, len(requested))
+		for _, id := range requested {
+			keep[id] = true
+			switch {
+			case !active[id]:
+				result.Failed++
+				result.Items = append(result.Items, AssociationItemResult{ID: id, Status: AssociationNotFound, Message: "registro não encontrado"})
+			case associated[id]:
+				result.Unchanged++
+				result.Items = append(result.Items, AssociationItemResult{ID: id, Status: AssociationUnchanged})
+			default:
+				result.Added++
+				result.Items = append(result.Items, AssociationItemResult{ID: id, Status: AssociationAdded})
+			}
+		}
+		if plan.replace {
+			for _, id := range current {
+				if !keep[id] {
+					result.Removed++
+					result.Items = append(result.Items, AssociationItemResult{ID: id, Status: AssociationRemoved})
+				}
+			}
+		}
+		if result.Failed > 0 {
+			return errAssociationRejected
+		}
+
+		added := []string{}
+		for _, item := range result.Items {
+			switch item.Status {
+			case AssociationAdded:
+				err = plan.link(tx, item.ID)
+				added = append(added, item.ID)
+			case AssociationRemoved:
+				err = plan.unlink(tx, item.ID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Carga horária: com a política "reject" os itens que a excedem impedem o lote;
+		// com "warn" o lote é gravado e os itens trazem o aviso.
+		if plan.overloaded != nil && len(added) > 0 {
+			overloaded, err := plan.overloaded(tx, added)
+			if err != nil {
+				return err
+			}
+			for i := range result.Items {
+				message, ok := overloaded[result.Items[i].ID]
+				if !ok || result.Items[i].Status != AssociationAdded {
+					continue
+				}
+				result.Items[i].Message = message
+				if s.workload.Reject {
+					result.Items[i].Status = AssociationWorkloadExceeded
+					result.Added--
+					result.Failed++
+				}
+			}
+			if len(overloaded) > 0 && s.workload.Reject {
+				return errAssociationRejected
+			}
+		}
+
+		for _, item := range result.Items {
+			if item.Status == AssociationAdded || item.Status == AssociationRemoved {
+				if err := plan.audit(tx, item.ID, item.Status); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errAssociationRejected) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// checkSubject verifica, dentro da transação, se a matéria da URL existe.
+func (s *AssociationService) checkSubject(ctx context.Context, tx *sql.Tx, subjectID string) error {
+	if _, err := s.subjectRepo.WithTx(tx).GetSubjectByID(ctx, subjectID, false); err != nil {
+		if errors.Is(err, ErrSubjectNotFound) {
+			return fmt.Errorf("matéria com ID %s não encontrada: %w", subjectID, ErrAssociationOwnerNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// recordSubjectChange registra no log de auditoria, na transação tx, a associação ou desassociação
+// de uma matéria, com os mesmos eventos das rotas individuais.
+func (s *AssociationService) recordSubjectChange(ctx context.Context, tx *sql.Tx, entityType, entityID, subjectID, status string) error {
+	link := map[string]string{"subject_id": subjectID}
+	if status == AssociationRemoved {
+		return s.audit.Record(ctx, tx, entityType, entityID, AuditActionRemoveSubject, link, nil)
+	}
+	return s.audit.Record(ctx, tx, entityType, entityID, AuditActionAddSubject, nil, link)
+}
+
+// subjectIDsOf retorna os IDs de uma lista de matérias.
+func subjectIDsOf(subjects []models.Subject) []string {
+	ids := make([]string, len(subjects))
+	for i, subject := range subjects {
+		ids[i] = subject.ID
+	}
+	return ids
+}
+
+// uniqueIDs remove IDs vazios e repetidos, preservando a ordem.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,163 @@
+// services/audit_service.go
+package services
+
+import (
+	"bytes"
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/reqctx"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Tipos de entidade registrados no log de auditoria.
+const (
+	AuditEntityStudent    = "student"
+	AuditEntityTeacher    = "teacher"
+	AuditEntitySubject    = "subject"
+	AuditEntityProgram    = "program"
+	AuditEntityDepartment = "department"
+)
+
+// Ações registradas no log de auditoria.
+const (
+	AuditActionCreate           = "create"
+	AuditActionUpdate           = "update"
+	AuditActionDelete           = "delete"
+	AuditActionRestore          = "restore"
+	AuditActionPurge            = "purge"
+	AuditActionAddSubject       = "add_subject"
+	AuditActionRemoveSubject    = "remove_subject"
+	AuditActionSetSubjectStatus = "set_subject_status"
+	AuditActionCreateCurriculum = "create_curriculum"
+)
+
+// ErrInvalidAuditEntity indica um filtro de entidade desconhecido em ListEvents.
+var ErrInvalidAuditEntity = errors.New("entidade inválida: deve ser 'student', 'teacher', 'subject', 'program' ou 'department'")
+
+// AuditService registra as mutações dos demais serviços no log de auditoria.
+type AuditService struct {
+	repo   *repositories.AuditRepository
+	logger *slog.Logger
+}
+
+// NewAuditService cria uma nova instância de AuditService.
+func NewAuditService(repo *repositories.AuditRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{repo: repo, logger: logger}
+}
+
+// Record registra uma mutação na transação tx, que deve ser a mesma da mutação. before e after
+// são os estados da entidade (nil quando não se aplicam, ex: before na criação); o autor e o ID
+// da requisição vêm do contexto. Um erro deve desfazer a transação: mutação sem auditoria não é
+// confirmada.
+func (s *AuditService) Record(ctx context.Context, tx *sql.Tx, entityType, entityID, action string, before, after interface{}) error {
+	ctx, span := tracing.Start(ctx, "AuditService.Record")
+	defer span.End()
+	event := &models.AuditEvent{
+		Actor:      reqctx.Actor(ctx),
+		RequestID:  reqctx.RequestID(ctx),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+	}
+
+	var err error
+	if event.Before, err = marshalAuditState(before); err == nil {
+		if event.After, err = marshalAuditState(after); err == nil {
+			event.Changes, err = diffAuditStates(event.Before, event.After)
+		}
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "erro ao serializar estado para auditoria", "entity_type", entityType, "entity_id", entityID, "action", action, "error", err)
+		return fmt.Errorf("erro ao serializar estado para auditoria: %w", err)
+	}
+
+	return s.repo.WithTx(tx).AppendEvent(ctx, event)
+}
+
+// ListEvents busca eventos de auditoria de uma entidade. limit <= 0 usa o padrão de 100.
+func (s *AuditService) ListEvents(ctx context.Context, entityType, entityID string, limit int) ([]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.ListEvents")
+	defer span.End()
+	switch entityType {
+	case "", AuditEntityStudent, AuditEntityTeacher, AuditEntitySubject, AuditEntityProgram, AuditEntityDepartment:
+	default:
+		return nil, ErrInvalidAuditEntity
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	events, err := s.repo.ListEvents(ctx, entityType, entityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar eventos de auditoria: %w", err)
+	}
+	return events, nil
+}
+
+// marshalAuditState serializa um estado de entidade; nil gera JSON vazio (NULL no banco).
+func marshalAuditState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(state)
+	if err != nil || bytes.Equal(raw, []byte("null")) { // ponteiro nil tipado
+		return nil, err
+	}
+	return raw, nil
+}
+
+// diffAuditStates compara dois objetos JSON campo a campo (primeiro nível) e retorna
+// {"campo": {"from": ..., "to": ...}} apenas com os campos alterados.
+func diffAuditStates(before, after json.RawMessage) (json.RawMessage, error) {
+	beforeFields := map[string]json.RawMessage{}
+	afterFields := map[string]json.RawMessage{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &beforeFields); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &afterFields); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := map[string]bool{}
+	for k := range beforeFields {
+		keys[k] = true
+	}
+	for k := range afterFields {
+		keys[k] = true
+	}
+	type change struct {
+		From json.RawMessage `json:"from"`
+		To   json.RawMessage `json:"to"`
+	}
+	changes := map[string]change{}
+	for k := range keys {
+		from, to := beforeFields[k], afterFields[k]
+		if bytes.Equal(from, to) {
+			continue
+		}
+		if from == nil {
+			from = json.RawMessage("null")
+		}
+		if to == nil {
+			to = json.RawMessage("null")
+		}
+		changes[k] = change{From: from, To: to}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changes)
+}
@@ -0,0 +1,535 @@
+// services/backup_service.go
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Identificação do arquivo de cópia de segurança. BackupFormatVersion muda quando a estrutura
+// das linhas muda; as colunas de cada tabela acompanham a versão do esquema (schema_version).
+const (
+	BackupFormat        = "college-app-backup"
+	BackupFormatVersion = 1
+)
+
+// Limites da restauração: linhas gravadas por comando e problemas listados no resultado.
+const (
+	restoreBatchSize   = 500
+	maxRestoreProblems = 100
+)
+
+// BackupHeader é a primeira linha do arquivo. Cada linha seguinte é um objeto
+// {"table": "...", "row": {coluna: valor}}, com as tabelas na ordem de backupTables.
+type BackupHeader struct {
+	Format        string         `json:"format"`
+	FormatVersion int            `json:"format_version"`
+	SchemaVersion int            `json:"schema_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Tables        map[string]int `json:"tables"` // Linhas de cada tabela incluída no arquivo
+}
+
+// backupLine é uma linha de dados do arquivo.
+type backupLine struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// backupTable descreve uma tabela incluída na cópia de segurança.
+type backupTable struct {
+	name       string
+	columns    []string
+	key        []string // Chave primária, que também define a ordem das linhas no arquivo
+	references []backupReference
+	serial     string // Coluna serial cuja sequência é ajustada após a restauração
+}
+
+// backupReference é uma chave estrangeira: columns referenciam a chave de table, na mesma ordem.
+// Referências com algum valor nulo são ignoradas.
+type backupReference struct {
+	columns []string
+	table   string
+	// deferred indica uma referência circular (a tabela referenciada vem depois no arquivo):
+	// a coluna é gravada apenas depois de todas as tabelas.
+	deferred bool
+}
+
+// backupTables lista as tabelas na ordem de gravação, em que cada tabela só referencia as
+// anteriores (exceto as referências deferred). Buckets do rate limiter, chaves de idempotência
+// e schema_version não fazem parte da cópia.
+var backupTables = []backupTable{
+	{name: "programs", columns: []string{"id", "code", "name", "use_code_in_enrollment", "version"}, key: []string{"id"}},
+	{name: "curricula", columns: []string{"id", "program_id", "version", "description", "required_credits", "created_at"}, key: []string{"id"},
+		references: []backupReference{{columns: []string{"program_id"}, table: "programs"}}},
+	{name: "curriculum_elective_buckets", columns: []string{"curriculum_id", "name", "min_credits"}, key: []string{"curriculum_id", "name"},
+		references: []backupReference{{columns: []string{"curriculum_id"}, table: "curricula"}}},
+	{name: "subjects", columns: []string{"id", "name", "year", "credits", "mandatory", "version", "deleted_at"}, key: []string{"id"}},
+	{name: "curriculum_subjects", columns: []string{"curriculum_id", "subject_id", "year", "mandatory", "bucket"}, key: []string{"curriculum_id", "subject_id"},
+		references: []backupReference{
+			{columns: []string{"curriculum_id"}, table: "curricula"},
+			{columns: []string{"subject_id"}, table: "subjects"},
+			{columns: []string{"curriculum_id", "bucket"}, table: "curriculum_elective_buckets"},
+		}},
+	{name: "departments", columns: []string{"id", "code", "name", "head_teacher_id", "version"}, key: []string{"id"},
+		references: []backupReference{{columns: []string{"head_teacher_id"}, table: "teachers", deferred: true}}},
+	{name: "teachers", columns: []string{"id", "registry", "name", "email", "department_id", "contract_type", "version", "deleted_at"}, key: []string{"id"},
+		references: []backupReference{{columns: []string{"department_id"}, table: "departments"}}},
+	{name: "students", columns: []string{"id", "enrollment", "name", "current_year", "shift", "program_id", "curriculum_id", "version", "deleted_at"}, key: []string{"id"},
+		references: []backupReference{
+			{columns: []string{"program_id"}, table: "programs"},
+			{columns: []string{"curriculum_id"}, table: "curricula"},
+		}},
+	{name: "student_subjects", columns: []string{"student_id", "subject_id", "status"}, key: []string{"student_id", "subject_id"},
+		references: []backupReference{
+			{columns: []string{"student_id"}, table: "students"},
+			{columns: []string{"subject_id"}, table: "subjects"},
+		}},
+	{name: "teacher_subjects", columns: []string{"teacher_id", "subject_id"}, key: []string{"teacher_id", "subject_id"},
+		references: []backupReference{
+			{columns: []string{"teacher_id"}, table: "teachers"},
+			{columns: []string{"subject_id"}, table: "subjects"},
+		}},
+	// A auditoria referencia registros que podem já ter sido removidos, por isso não tem referências.
+	{name: "audit_events", columns: []string{"id", "occurred_at", "actor", "request_id", "entity_type", "entity_id", "action", "before", "after", "changes"},
+		key: []string{"id"}, serial: "id"},
+}
+
+// RestoreProblem é um problema encontrado no arquivo; Line 0 se refere ao arquivo inteiro.
+type RestoreProblem struct {
+	Line    int    `json:"line"`
+	Table   string `json:"table,omitempty"`
+	Message string `json:"message"`
+}
+
+// RestoreResult resume uma restauração. Com problemas, nada é gravado.
+type RestoreResult struct {
+	DryRun       bool             `json:"dry_run"`
+	Applied      bool             `json:"applied"`
+	Header       BackupHeader     `json:"header"`
+	Tables       map[string]int   `json:"tables"`        // Linhas lidas (e gravadas, se Applied) por tabela
+	ProblemCount int              `json:"problem_count"` // Total de problemas; Problems lista os primeiros
+	Problems     []RestoreProblem `json:"problems"`
+}
+
+// errRestoreRejected desfaz a transação da restauração quando o arquivo tem problemas.
+var errRestoreRejected = errors.New("restauração recusada")
+
+// BackupService gera e restaura a cópia de segurança lógica do banco: todas as entidades,
+// associações e a auditoria em um arquivo JSON lines versionado.
+type BackupService struct {
+	transactor    *repositories.Transactor
+	repo          *repositories.BackupRepository
+	schemaVersion int
+	logger        *slog.Logger
+}
+
+// NewBackupService cria uma nova instância de BackupService. schemaVersion é a versão do esquema
+// da aplicação, gravada no arquivo e exigida na restauração.
+func NewBackupService(transactor *repositories.Transactor, repo *repositories.BackupRepository, schemaVersion int, logger *slog.Logger) *BackupService {
+	return &BackupService{transactor: transactor, repo: repo, schemaVersion: schemaVersion, logger: logger}
+}
+
+// Backup escreve a cópia de segurança em w, lida de um único snapshot do banco. Sem
+// includeAudit, a auditoria fica de fora.
+func (s *BackupService) Backup(ctx context.Context, w io.Writer, includeAudit bool) (*BackupHeader, error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Backup")
+	defer span.End()
+	tables := []backupTable{}
+	for _, table := range backupTables {
+		if table.name != "audit_events" || includeAudit {
+			tables = append(tables, table)
+		}
+	}
+	header := &BackupHeader{Format: BackupFormat, FormatVersion: BackupFormatVersion, SchemaVersion: s.schemaVersion,
+		CreatedAt: time.Now().UTC(), Tables: map[string]int{}}
+
+	err := s.transactor.WithinSnapshot(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		for _, table := range tables {
+			count, err := repo.CountRows(ctx, table.name)
+			if err != nil {
+				return err
+			}
+			header.Tables[table.name] = count
+		}
+		if err := writeJSONLine(w, header); err != nil {
+			return err
+		}
+		for _, table := range tables {
+			err := repo.StreamRows(ctx, table.name, table.columns, table.key, func(row json.RawMessage) error {
+				return writeJSONLine(w, backupLine{Table: table.name, Row: row})
+			})
+			if err != nil {
+				return err
+			}
+			s.logger.InfoContext(ctx, "tabela copiada", "table", table.name, "rows", header.Tables[table.name])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// writeJSONLine escreve value como uma linha JSON.
+func writeJSONLine(w io.Writer, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(raw, '\n'))
+	return err
+}
+
+// Restore lê a cópia de segurança de r, valida o formato, as chaves e a integridade referencial
+// e grava tudo em uma única transação, preservando IDs, matrículas e versões. O banco deve estar
+// vazio e na mesma versão de esquema do arquivo. Com problemas no arquivo, nada é gravado e o
+// resultado os lista; dryRun apenas valida o arquivo, sem gravar.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader, dryRun bool) (*RestoreResult, error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Restore")
+	defer span.End()
+	reader := bufio.NewReader(r)
+	raw, err := readLine(reader)
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("arquivo de cópia de segurança vazio")
+		}
+		return nil, err
+	}
+	var header BackupHeader
+	if err := json.Unmarshal(raw, &header); err != nil || header.Format != BackupFormat {
+		return nil, fmt.Errorf("arquivo não é uma cópia de segurança do %s", BackupFormat)
+	}
+	if header.FormatVersion != BackupFormatVersion {
+		return nil, fmt.Errorf("versão do formato não suportada: %d (esperada %d)", header.FormatVersion, BackupFormatVersion)
+	}
+	if header.SchemaVersion != s.schemaVersion {
+		return nil, fmt.Errorf("cópia gerada com o esquema na versão %d, mas a aplicação usa a versão %d", header.SchemaVersion, s.schemaVersion)
+	}
+
+	result := &RestoreResult{DryRun: dryRun, Header: header, Tables: map[string]int{}, Problems: []RestoreProblem{}}
+	if dryRun {
+		if err := s.replay(ctx, reader, result, nil); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		names := make([]string, len(backupTables))
+		for i, table := range backupTables {
+			names[i] = table.name
+		}
+		nonEmpty, err := repo.FirstNonEmpty(ctx, names)
+		if err != nil {
+			return err
+		}
+		if nonEmpty != "" {
+			return fmt.Errorf("o banco de destino não está vazio (tabela %s tem registros)", nonEmpty)
+		}
+		if err := s.replay(ctx, reader, result, repo); err != nil {
+			return err
+		}
+		if result.ProblemCount > 0 {
+			return errRestoreRejected
+		}
+		return nil
+	})
+	if err == errRestoreRejected {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// replay percorre as linhas de dados validando cada uma e, se repo não for nil, grava as linhas
+// em lotes enquanto nenhum problema tiver sido encontrado.
+func (s *BackupService) replay(ctx context.Context, reader *bufio.Reader, result *RestoreResult, repo *repositories.BackupRepository) error {
+	v := &restoreValidator{result: result, keys: map[string]map[string]bool{}, position: map[string]int{}}
+	for i, table := range backupTables {
+		v.position[table.name] = i
+		v.keys[table.name] = map[string]bool{}
+	}
+	for name := range result.Header.Tables {
+		if _, ok := v.position[name]; !ok {
+			v.problem(0, name, "tabela desconhecida no cabeçalho")
+		}
+	}
+
+	var batchTable *backupTable
+	var batch []json.RawMessage
+	deferredRows := map[string][]json.RawMessage{} // Linhas com referências deferred, por tabela
+	flush := func() error {
+		if repo == nil || batchTable == nil || len(batch) == 0 || result.ProblemCount > 0 {
+			batch = batch[:0]
+			return nil
+		}
+		err := repo.InsertRows(ctx, batchTable.name, batchTable.insertColumns(), batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for lineNumber := 2; ; lineNumber++ {
+		raw, err := readLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		table, line := v.check(lineNumber, raw)
+		if table == nil {
+			continue
+		}
+		if batchTable != table || len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			batchTable = table
+		}
+		batch = append(batch, line.Row)
+		if table.hasDeferred() {
+			deferredRows[table.name] = append(deferredRows[table.name], line.Row)
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	v.finish()
+
+	if repo == nil || result.ProblemCount > 0 {
+		return nil
+	}
+	for _, table := range backupTables {
+		for _, ref := range table.references {
+			if ref.deferred {
+				if err := repo.UpdateColumn(ctx, table.name, table.key, ref.columns[0], deferredRows[table.name]); err != nil {
+					return err
+				}
+			}
+		}
+		if table.serial != "" && result.Tables[table.name] > 0 {
+			if err := repo.ResetSequence(ctx, table.name, table.serial); err != nil {
+				return err
+			}
+		}
+		if rows, ok := result.Tables[table.name]; ok {
+			s.logger.InfoContext(ctx, "tabela restaurada", "table", table.name, "rows", rows)
+		}
+	}
+	return nil
+}
+
+// readLine lê uma linha inteira (sem limite de tamanho), sem o '\n' final.
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	return bytes.TrimRight(line, "\r\n"), err
+}
+
+// insertColumns são as colunas gravadas na inserção: todas, exceto as referências deferred.
+func (t *backupTable) insertColumns() []string {
+	columns := []string{}
+	for _, column := range t.columns {
+		deferred := false
+		for _, ref := range t.references {
+			if ref.deferred && ref.columns[0] == column {
+				deferred = true
+			}
+		}
+		if !deferred {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// hasDeferred informa se a tabela tem alguma referência deferred.
+func (t *backupTable) hasDeferred() bool {
+	for _, ref := range t.references {
+		if ref.deferred {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreValidator confere as linhas do arquivo: tabela conhecida e na ordem, colunas
+// completas, chave única e referências para linhas já lidas (ou, nas deferred, para linhas
+// do arquivo inteiro, conferidas em finish).
+type restoreValidator struct {
+	result   *RestoreResult
+	keys     map[string]map[string]bool // Chaves lidas por tabela
+	position map[string]int             // Posição de cada tabela em backupTables
+	current  int                        // Posição da última tabela lida
+	deferred []pendingReference
+}
+
+// pendingReference é uma referência deferred a conferir ao final do arquivo.
+type pendingReference struct {
+	line   int
+	table  string
+	column string
+	target string
+	key    string
+}
+
+// check valida uma linha e retorna sua tabela, ou nil se a linha tiver problemas.
+func (v *restoreValidator) check(lineNumber int, raw []byte) (*backupTable, *backupLine) {
+	var line backupLine
+	if err := json.Unmarshal(raw, &line); err != nil || line.Table == "" {
+		v.problem(lineNumber, "", "linha inválida: esperado {\"table\": ..., \"row\": {...}}")
+		return nil, nil
+	}
+	position, ok := v.position[line.Table]
+	if !ok {
+		v.problem(lineNumber, line.Table, "tabela desconhecida")
+		return nil, nil
+	}
+	table := &backupTables[position]
+	if _, declared := v.result.Header.Tables[table.name]; !declared {
+		v.problem(lineNumber, table.name, "tabela ausente do cabeçalho")
+		return nil, nil
+	}
+	if position < v.current {
+		v.problem(lineNumber, table.name, fmt.Sprintf("tabela fora de ordem (depois de %s)", backupTables[v.current].name))
+		return nil, nil
+	}
+	v.current = position
+	v.result.Tables[table.name]++
+
+	decoder := json.NewDecoder(bytes.NewReader(line.Row))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil || row == nil {
+		v.problem(lineNumber, table.name, "row deve ser um objeto JSON")
+		return nil, nil
+	}
+	valid := true
+	for _, column := range table.columns {
+		if _, ok := row[column]; !ok {
+			v.problem(lineNumber, table.name, "coluna ausente: "+column)
+			valid = false
+		}
+	}
+	if len(row) > len(table.columns) {
+		extra := []string{}
+		for column := range row {
+			if !slices.Contains(table.columns, column) {
+				extra = append(extra, column)
+			}
+		}
+		sort.Strings(extra)
+		v.problem(lineNumber, table.name, "colunas desconhecidas: "+strings.Join(extra, ", "))
+		valid = false
+	}
+	if !valid {
+		return nil, nil
+	}
+
+	key, ok := rowKey(row, table.key)
+	if !ok {
+		v.problem(lineNumber, table.name, "chave nula: "+strings.Join(table.key, ", "))
+		return nil, nil
+	}
+	if v.keys[table.name][key] {
+		v.problem(lineNumber, table.name, "linha duplicada: "+describeKey(table.key, key))
+		return nil, nil
+	}
+	v.keys[table.name][key] = true
+
+	for _, ref := range table.references {
+		target, ok := rowKey(row, ref.columns)
+		if !ok {
+			continue
+		}
+		if ref.deferred {
+			v.deferred = append(v.deferred, pendingReference{line: lineNumber, table: table.name, column: strings.Join(ref.columns, ", "), target: ref.table, key: target})
+		} else if !v.keys[ref.table][target] {
+			v.problem(lineNumber, table.name, fmt.Sprintf("referência inexistente em %s: %s", ref.table, describeKey(ref.columns, target)))
+			valid = false
+		}
+	}
+	if !valid {
+		return nil, nil
+	}
+	return table, &line
+}
+
+// finish confere as referências deferred e se cada tabela tem as linhas anunciadas no cabeçalho.
+func (v *restoreValidator) finish() {
+	for _, ref := range v.deferred {
+		if !v.keys[ref.target][ref.key] {
+			v.problem(ref.line, ref.table, fmt.Sprintf("referência inexistente em %s: %s", ref.target, describeKey([]string{ref.column}, ref.key)))
+		}
+	}
+	names := make([]string, 0, len(v.result.Header.Tables))
+	for name := range v.result.Header.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if expected, found := v.result.Header.Tables[name], v.result.Tables[name]; expected != found {
+			v.problem(0, name, fmt.Sprintf("esperadas %d linhas, encontradas %d (arquivo incompleto ou alterado?)", expected, found))
+		}
+	}
+	// Problemas por linha; os do arquivo inteiro (linha 0) por último
+	sort.SliceStable(v.result.Problems, func(i, j int) bool {
+		a, b := v.result.Problems[i].Line, v.result.Problems[j].Line
+		return a != 0 && (b == 0 || a < b)
+	})
+}
+
+// problem registra um problema; apenas os primeiros maxRestoreProblems são listados.
+func (v *restoreValidator) problem(line int, table, message string) {
+	v.result.ProblemCount++
+	if len(v.result.Problems) < maxRestoreProblems {
+		v.result.Problems = append(v.result.Problems, RestoreProblem{Line: line, Table: table, Message: message})
+	}
+}
+
+// rowKey monta a chave das colunas de row; ok é false se alguma delas for nula.
+func rowKey(row map[string]interface{}, columns []string) (string, bool) {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		value := row[column]
+		if value == nil {
+			return "", false
+		}
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, "\x1f"), true
+}
+
+// describeKey formata uma chave para as mensagens (ex: "student_id=a1, subject_id=b2").
+func describeKey(columns []string, key string) string {
+	values := strings.Split(key, "\x1f")
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = column + "=" + values[i]
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,175 @@
+// services/backup_service_test.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Linhas de dados válidas usadas nos arquivos de teste.
+const (
+	backupProgram        = `{"table":"programs","row":{"id":"p1","code":"CC","name":"Ciência da Computação","use_code_in_enrollment":true,"version":1}}`
+	backupSubject        = `{"table":"subjects","row":{"id":"s1","name":"Cálculo I","year":1,"credits":4,"mandatory":true,"version":1,"deleted_at":null}}`
+	backupDepartment     = `{"table":"departments","row":{"id":"d1","code":"CC","name":"Computação","head_teacher_id":"t1","version":1}}`
+	backupTeacher        = `{"table":"teachers","row":{"id":"t1","registry":"PROF1","name":"Ana","email":"ana@exemplo.com","department_id":"d1","contract_type":"integral","version":1,"deleted_at":null}}`
+	backupTeacherSubject = `{"table":"teacher_subjects","row":{"teacher_id":"t1","subject_id":"s1"}}`
+)
+
+// backupFile monta um arquivo de cópia de segurança com o cabeçalho (tables) e as linhas de dados.
+func backupFile(schemaVersion int, tables map[string]int, lines ...string) string {
+	header, _ := json.Marshal(BackupHeader{Format: BackupFormat, FormatVersion: BackupFormatVersion, SchemaVersion: schemaVersion, Tables: tables})
+	return string(header) + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+func TestRestoreValidation(t *testing.T) {
+	all := map[string]int{"programs": 1, "subjects": 1, "departments": 1, "teachers": 1, "teacher_subjects": 1}
+	tests := []struct {
+		name         string
+		tables       map[string]int
+		lines        []string
+		wantTables   map[string]int
+		wantProblems []RestoreProblem
+	}{
+		{
+			name:         "arquivo válido",
+			tables:       all,
+			lines:        []string{backupProgram, backupSubject, backupDepartment, backupTeacher, backupTeacherSubject},
+			wantTables:   all,
+			wantProblems: []RestoreProblem{},
+		},
+		{
+			name:         "linhas em branco são ignoradas",
+			tables:       map[string]int{"programs": 1},
+			lines:        []string{"", backupProgram, "  "},
+			wantTables:   map[string]int{"programs": 1},
+			wantProblems: []RestoreProblem{},
+		},
+		{
+			name:       "referência circular conferida no fim do arquivo",
+			tables:     map[string]int{"departments": 1, "teachers": 1},
+			lines:      []string{strings.Replace(backupDepartment, `"t1"`, `"t9"`, 1), backupTeacher},
+			wantTables: map[string]int{"departments": 1, "teachers": 1},
+			wantProblems: []RestoreProblem{
+				{Line: 2, Table: "departments", Message: "referência inexistente em teachers: head_teacher_id=t9"},
+			},
+		},
+		{
+			name:       "referência para linha inexistente",
+			tables:     map[string]int{"subjects": 1, "departments": 1, "teachers": 1, "teacher_subjects": 1},
+			lines:      []string{backupSubject, backupDepartment, backupTeacher, strings.Replace(backupTeacherSubject, `"s1"`, `"s9"`, 1)},
+			wantTables: map[string]int{"subjects": 1, "departments": 1, "teachers": 1, "teacher_subjects": 1},
+			wantProblems: []RestoreProblem{
+				{Line: 5, Table: "teacher_subjects", Message: "referência inexistente em subjects: subject_id=s9"},
+			},
+		},
+		{
+			name:       "tabela fora de ordem",
+			tables:     map[string]int{"programs": 1, "subjects": 1},
+			lines:      []string{backupSubject, backupProgram},
+			wantTables: map[string]int{"subjects": 1},
+			wantProblems: []RestoreProblem{
+				{Line: 3, Table: "programs", Message: "tabela fora de ordem (depois de subjects)"},
+				{Line: 0, Table: "programs", Message: "esperadas 1 linhas, encontradas 0 (arquivo incompleto ou alterado?)"},
+			},
+		},
+		{
+			name:       "colunas ausentes e desconhecidas",
+			tables:     map[string]int{"programs": 1},
+			lines:      []string{`{"table":"programs","row":{"id":"p1","code":"CC","name":"CC","version":1,"sigla":"x","ativo":true}}`},
+			wantTables: map[string]int{"programs": 1},
+			wantProblems: []RestoreProblem{
+				{Line: 2, Table: "programs", Message: "coluna ausente: use_code_in_enrollment"},
+				{Line: 2, Table: "programs", Message: "colunas desconhecidas: ativo, sigla"},
+			},
+		},
+		{
+			name:       "chave duplicada e nula",
+			tables:     map[string]int{"subjects": 3},
+			lines:      []string{backupSubject, backupSubject, strings.Replace(backupSubject, `"s1"`, `null`, 1)},
+			wantTables: map[string]int{"subjects": 3},
+			wantProblems: []RestoreProblem{
+				{Line: 3, Table: "subjects", Message: "linha duplicada: id=s1"},
+				{Line: 4, Table: "subjects", Message: "chave nula: id"},
+			},
+		},
+		{
+			name:   "linhas e tabelas inválidas",
+			tables: map[string]int{"programs": 1, "rate_limit_buckets": 1},
+			lines: []string{`não é JSON`, `{"row":{}}`, `{"table":"schema_version","row":{"version":3}}`, backupSubject,
+				`{"table":"programs","row":[1,2]}`},
+			wantTables: map[string]int{"programs": 1},
+			wantProblems: []RestoreProblem{
+				{Line: 2, Message: "linha inválida: esperado {\"table\": ..., \"row\": {...}}"},
+				{Line: 3, Message: "linha inválida: esperado {\"table\": ..., \"row\": {...}}"},
+				{Line: 4, Table: "schema_version", Message: "tabela desconhecida"},
+				{Line: 5, Table: "subjects", Message: "tabela ausente do cabeçalho"},
+				{Line: 6, Table: "programs", Message: "row deve ser um objeto JSON"},
+				{Line: 0, Table: "rate_limit_buckets", Message: "tabela desconhecida no cabeçalho"},
+				{Line: 0, Table: "rate_limit_buckets", Message: "esperadas 1 linhas, encontradas 0 (arquivo incompleto ou alterado?)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &BackupService{schemaVersion: 3, logger: slog.New(slog.DiscardHandler)}
+			result, err := svc.Restore(context.Background(), strings.NewReader(backupFile(3, tt.tables, tt.lines...)), true)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if result.Applied || !result.DryRun {
+				t.Errorf("Applied/DryRun = %v/%v, esperava false/true", result.Applied, result.DryRun)
+			}
+			if !reflect.DeepEqual(result.Tables, tt.wantTables) {
+				t.Errorf("tabelas = %v, esperava %v", result.Tables, tt.wantTables)
+			}
+			if !reflect.DeepEqual(result.Problems, tt.wantProblems) || result.ProblemCount != len(tt.wantProblems) {
+				t.Errorf("problemas (%d) = %+v, esperava %+v", result.ProblemCount, result.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestRestoreProblemLimit(t *testing.T) {
+	lines := make([]string, maxRestoreProblems+10)
+	for i := range lines {
+		lines[i] = `não é JSON`
+	}
+	svc := &BackupService{schemaVersion: 3, logger: slog.New(slog.DiscardHandler)}
+	result, err := svc.Restore(context.Background(), strings.NewReader(backupFile(3, map[string]int{}, lines...)), true)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if result.ProblemCount != len(lines) || len(result.Problems) != maxRestoreProblems {
+		t.Errorf("problemas = %d contados, %d listados; esperava %d e %d", result.ProblemCount, len(result.Problems), len(lines), maxRestoreProblems)
+	}
+}
+
+func TestRestoreHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "arquivo vazio", input: "", wantErr: "vazio"},
+		{name: "outro formato", input: `{"format":"outro","format_version":1,"schema_version":3}` + "\n", wantErr: "não é uma cópia de segurança"},
+		{name: "JSON inválido", input: "programs,subjects\n", wantErr: "não é uma cópia de segurança"},
+		{name: "versão do formato", input: `{"format":"college-app-backup","format_version":2,"schema_version":3}` + "\n", wantErr: "versão do formato não suportada"},
+		{name: "versão do esquema", input: backupFile(2, map[string]int{}), wantErr: "esquema na versão 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &BackupService{schemaVersion: 3, logger: slog.New(slog.DiscardHandler)}
+			result, err := svc.Restore(context.Background(), strings.NewReader(tt.input), true)
+			if err == nil {
+				t.Fatalf("resultado = %+v, esperava erro", result)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %q, esperava conter %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// services/curriculum_service.go
+package services
+
+import (
+	"college-app-v1/metrics"
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// CurriculumBatchResult é o resumo da matrícula por currículo de todos os alunos de um ano/turno.
+type CurriculumBatchResult struct {
+	Year      int                  `json:"current_year"`
+	Shift     string               `json:"shift,omitempty"`
+	Students  int                  `json:"students"`
+	Added     int                  `json:"added"`
+	Unchanged int                  `json:"unchanged"`
+	Skipped   int                  `json:"skipped"`
+	Results   []*AssociationResult `json:"results"`
+}
+
+// CurriculumService matricula alunos nas matérias obrigatórias do ano em que estão, pulando as já
+// aprovadas. Alunos com grade curricular usam o ano das matérias na grade; os demais usam
+// Subject.Year.
+type CurriculumService struct {
+	transactor  *repositories.Transactor
+	studentRepo *repositories.StudentRepository
+	subjectRepo *repositories.SubjectRepository
+	programRepo *repositories.ProgramRepository
+	enrollment  EnrollmentPolicy
+	audit       *AuditService
+	logger      *slog.Logger
+}
+
+// NewCurriculumService cria uma nova instância de CurriculumService.
+func NewCurriculumService(transactor *repositories.Transactor, sr *repositories.StudentRepository, subR *repositories.SubjectRepository, pr *repositories.ProgramRepository, enrollment EnrollmentPolicy, audit *AuditService, logger *slog.Logger) *CurriculumService {
+	return &CurriculumService{transactor: transactor, studentRepo: sr, subjectRepo: subR, programRepo: pr, enrollment: enrollment, audit: audit, logger: logger}
+}
+
+// CreateStudentWithCurriculum cria o aluno e já o matricula nas matérias obrigatórias do seu
+// ano, na mesma transação. Em caso de sucesso, student.Subjects traz as matérias matriculadas.
+func (s *CurriculumService) CreateStudentWithCurriculum(ctx context.Context, student *models.Student) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "CurriculumService.CreateStudentWithCurriculum")
+	defer span.End()
+	if err := validateNewStudent(s.enrollment, student); err != nil {
+		return nil, err
+	}
+	programCode, err := resolveStudentProgram(ctx, s.programRepo, student)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *AssociationResult
+	var subjects []models.Subject
+	// Um conflito de matrícula aborta a transação, então cada tentativa usa uma nova.
+	err = retryEnrollmentConflict(ctx, s.logger, student, func() error {
+		return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+			if err := createStudentWithEnrollment(ctx, s.logger, s.enrollment, s.studentRepo.WithTx(tx), programCode, student); err != nil {
+				return err
+			}
+			var err error
+			if result, subjects, err = s.enrollInTx(ctx, tx, student); err != nil {
+				return err
+			}
+			student.Subjects = subjects
+			if err := s.audit.Record(ctx, tx, AuditEntityStudent, student.ID, AuditActionCreate, nil, student); err != nil {
+				return err
+			}
+			return s.recordEnrollment(ctx, tx, result)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.StudentsCreated.WithLabelValues("curriculum").Inc()
+	return result, nil
+}
+
+// EnrollStudent matricula um aluno existente nas matérias obrigatórias do seu ano atual.
+func (s *CurriculumService) EnrollStudent(ctx context.Context, studentID string) (*AssociationResult, error) {
+	ctx, span := tracing.Start(ctx, "CurriculumService.EnrollStudent")
+	defer span.End()
+	var result *AssociationResult
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		student, err := s.studentRepo.WithTx(tx).GetStudentByID(ctx, studentID, false)
+		if err != nil {
+			if errors.Is(err, ErrStudentNotFound) {
+				return fmt.Errorf("aluno com ID %s não encontrado: %w", studentID, ErrAssociationOwnerNotFound)
+			}
+			return err
+		}
+		if result, _, err = s.enrollInTx(ctx, tx, student); err != nil {
+			return err
+		}
+		return s.recordEnrollment(ctx, tx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EnrollYearAndShift matricula pelo currículo todos os alunos ativos do ano informado e,
+// se shift não for vazio, apenas os do turno. A operação é tudo ou nada.
+func (s *CurriculumService) EnrollYearAndShift(ctx context.Context, year int, shift string) (*CurriculumBatchResult, error) {
+	ctx, span := tracing.Start(ctx, "CurriculumService.EnrollYearAndShift")
+	defer span.End()
+	validation := &ValidationError{}
+	if year < 1 {
+		validation.add("current_year", "deve ser um inteiro positivo")
+	}
+	shift = strings.ToUpper(strings.TrimSpace(shift))
+	if shift != "" && !s.enrollment.validShift(shift) {
+		validation.add("shift", "deve ser "+s.enrollment.shiftCodes(false))
+	}
+	if err := validation.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	batch := &CurriculumBatchResult{Year: year, Shift: shift}
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		batch.Results = []*AssociationResult{}
+		students, err := s.studentRepo.WithTx(tx).GetAllStudents(ctx, &year, shift, false)
+		if err != nil {
+			return err
+		}
+		for i := range students {
+			result, _, err := s.enrollInTx(ctx, tx, &students[i])
+			if err != nil {
+				return err
+			}
+			if err := s.recordEnrollment(ctx, tx, result); err != nil {
+				return err
+			}
+			batch.Results = append(batch.Results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batch.Students = len(batch.Results)
+	for _, result := range batch.Results {
+		batch.Added += result.Added
+		batch.Unchanged += result.Unchanged
+		batch.Skipped += result.Skipped
+	}
+	s.logger.InfoContext(ctx, "matrícula por currículo concluída", "year", year, "shift", shift, "students", batch.Students, "added", batch.Added)
+	return batch, nil
+}
+
+// enrollInTx matricula o aluno nas matérias obrigatórias do seu ano: aprovadas são puladas,
+// reprovadas voltam a "enrolled" e as demais são associadas. Retorna o resumo por matéria e as
+// matérias em que o aluno ficou matriculado.
+func (s *CurriculumService) enrollInTx(ctx context.Context, tx *sql.Tx, student *models.Student) (*AssociationResult, []models.Subject, error) {
+	studentRepo := s.studentRepo.WithTx(tx)
+	var subjects []models.Subject
+	var err error
+	if student.CurriculumID != "" {
+		subjects, err = s.programRepo.WithTx(tx).GetMandatorySubjectsForYear(ctx, student.CurriculumID, student.CurrentYear)
+	} else {
+		subjects, err = s.subjectRepo.WithTx(tx).GetMandatorySubjectsByYear(ctx, student.CurrentYear)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	statuses, err := studentRepo.GetSubjectStatuses(ctx, student.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &AssociationResult{Entity: AuditEntitySubject, ID: student.ID, Applied: true, Items: []AssociationItemResult{}}
+	enrolled := []models.Subject{}
+	for _, subject := range subjects {
+		switch statuses[subject.ID] {
+		case models.SubjectStatusPassed:
+			result.Skipped++
+			result.Items = append(result.Items, AssociationItemResult{ID: subject.ID, Status: AssociationSkipped, Message: "matéria já aprovada"})
+			continue
+		case models.SubjectStatusEnrolled:
+			result.Unchanged++
+			result.Items = append(result.Items, AssociationItemResult{ID: subject.ID, Status: AssociationUnchanged})
+		case models.SubjectStatusFailed:
+			if err := studentRepo.SetSubjectStatus(ctx, student.ID, subject.ID, models.SubjectStatusEnrolled); err != nil {
+				return nil, nil, err
+			}
+			result.Added++
+			result.Items = append(result.Items, AssociationItemResult{ID: subject.ID, Status: AssociationAdded, Message: "rematrícula após reprovação"})
+		default:
+			if err := studentRepo.AddSubjectToStudent(ctx, student.ID, subject.ID); err != nil {
+				return nil, nil, err
+			}
+			result.Added++
+			result.Items = append(result.Items, AssociationItemResult{ID: subject.ID, Status: AssociationAdded})
+		}
+		enrolled = append(enrolled, subject)
+	}
+	return result, enrolled, nil
+}
+
+// recordEnrollment registra no log de auditoria, na transação tx, as matrículas criadas por enrollInTx.
+func (s *CurriculumService) recordEnrollment(ctx context.Context, tx *sql.Tx, result *AssociationResult) error {
+	for _, item := range result.Items {
+		if item.Status == AssociationAdded {
+			if err := s.audit.Record(ctx, tx, AuditEntityStudent, result.ID, AuditActionAddSubject, nil, map[string]string{"subject_id": item.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+// services/degree_audit_service.go
+package services
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GraduationCandidate é um aluno que cumpre todos os requisitos de formatura.
+type GraduationCandidate struct {
+	StudentID       string `json:"student_id"`
+	Enrollment      string `json:"enrollment"`
+	Name            string `json:"name"`
+	CurrentYear     int    `json:"current_year"`
+	ProgramID       string `json:"program_id,omitempty"`
+	CurriculumID    string `json:"curriculum_id,omitempty"`
+	EarnedCredits   int    `json:"earned_credits"`
+	RequiredCredits int    `json:"required_credits"`
+}
+
+// GraduationReport lista os alunos ativos aptos a se formar.
+type GraduationReport struct {
+	ProgramID   string                `json:"program_id,omitempty"`   // Filtro aplicado
+	CurrentYear *int                  `json:"current_year,omitempty"` // Filtro aplicado
+	Evaluated   int                   `json:"evaluated"`              // Alunos auditados
+	Eligible    int                   `json:"eligible"`
+	Students    []GraduationCandidate `json:"students"`
+}
+
+// degreeRequirements são os requisitos de formatura de uma grade curricular (ou do catálogo).
+type degreeRequirements struct {
+	source          string // "curriculum" ou "catalog"
+	requiredCredits int
+	mandatory       []models.CurriculumSubject
+	buckets         []models.ElectiveBucket
+	bucketOf        map[string]string // ID da matéria -> grupo de eletivas
+}
+
+// DegreeAuditService compara as matérias cursadas pelos alunos com os requisitos de formatura.
+// Alunos com grade curricular seguem os requisitos da grade (obrigatórias por ano, total de
+// créditos e grupos de eletivas); os demais seguem as matérias obrigatórias do catálogo e o total
+// de créditos configurado. Só contam como cumpridas as matérias com situação "passed".
+type DegreeAuditService struct {
+	studentRepo    *repositories.StudentRepository
+	subjectRepo    *repositories.SubjectRepository
+	programRepo    *repositories.ProgramRepository
+	catalogCredits int // Créditos exigidos de alunos sem grade curricular
+}
+
+// NewDegreeAuditService cria uma nova instância de DegreeAuditService. catalogCredits é o total
+// de créditos exigido dos alunos sem grade curricular (0 = sem mínimo).
+func NewDegreeAuditService(sr *repositories.StudentRepository, subR *repositories.SubjectRepository, pr *repositories.ProgramRepository, catalogCredits int) *DegreeAuditService {
+	return &DegreeAuditService{studentRepo: sr, subjectRepo: subR, programRepo: pr, catalogCredits: catalogCredits}
+}
+
+// AuditStudent audita um aluno ativo, listando os requisitos cumpridos e os que faltam.
+func (s *DegreeAuditService) AuditStudent(ctx context.Context, studentID string) (*models.DegreeAudit, error) {
+	ctx, span := tracing.Start(ctx, "DegreeAuditService.AuditStudent")
+	defer span.End()
+	student, err := s.studentRepo.GetStudentByID(ctx, studentID, false)
+	if err != nil {
+		if errors.Is(err, ErrStudentNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao buscar aluno por ID: %w", err)
+	}
+	requirements, err := s.requirementsFor(ctx, student, map[string]*degreeRequirements{})
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.studentRepo.GetSubjectRecords(ctx, []string{student.ID})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar matérias do aluno: %w", err)
+	}
+	return evaluateDegree(student, requirements, records[student.ID]), nil
+}
+
+// GetGraduationCandidates audita os alunos ativos (opcionalmente de um curso e/ou ano) e lista os
+// aptos a se formar. Os requisitos de cada grade são carregados uma única vez.
+func (s *DegreeAuditService) GetGraduationCandidates(ctx context.Context, programID string, year *int) (*GraduationReport, error) {
+	ctx, span := tracing.Start(ctx, "DegreeAuditService.GetGraduationCandidates")
+	defer span.End()
+	var students []models.Student
+	err := s.studentRepo.StreamStudents(ctx, year, "", false, func(student *models.Student) error {
+		if programID == "" || student.ProgramID == programID {
+			students = append(students, *student)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alunos para auditoria de formatura: %w", err)
+	}
+
+	ids := make([]string, 0, len(students))
+	for _, student := range students {
+		ids = append(ids, student.ID)
+	}
+	records, err := s.studentRepo.GetSubjectRecords(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar matérias dos alunos: %w", err)
+	}
+
+	report := &GraduationReport{ProgramID: programID, CurrentYear: year, Evaluated: len(students), Students: []GraduationCandidate{}}
+	cache := map[string]*degreeRequirements{}
+	for i := range students {
+		student := &students[i]
+		requirements, err := s.requirementsFor(ctx, student, cache)
+		if err != nil {
+			return nil, err
+		}
+		audit := evaluateDegree(student, requirements, records[student.ID])
+		if !audit.Eligible {
+			continue
+		}
+		report.Students = append(report.Students, GraduationCandidate{
+			StudentID:       audit.StudentID,
+			Enrollment:      audit.Enrollment,
+			Name:            audit.Name,
+			CurrentYear:     audit.CurrentYear,
+			ProgramID:       audit.ProgramID,
+			CurriculumID:    audit.CurriculumID,
+			EarnedCredits:   audit.EarnedCredits,
+			RequiredCredits: audit.RequiredCredits,
+		})
+	}
+	report.Eligible = len(report.Students)
+	return report, nil
+}
+
+// requirementsFor retorna os requisitos de formatura do aluno: os da sua grade curricular ou, sem
+// grade, os do catálogo. cache guarda os requisitos já carregados (chave: ID da grade, "" = catálogo).
+func (s *DegreeAuditService) requirementsFor(ctx context.Context, student *models.Student, cache map[string]*degreeRequirements) (*degreeRequirements, error) {
+	if requirements, ok := cache[student.CurriculumID]; ok {
+		return requirements, nil
+	}
+
+	requirements := &degreeRequirements{bucketOf: map[string]string{}}
+	if student.CurriculumID != "" {
+		curriculum, err := s.programRepo.GetCurriculumByID(ctx, student.CurriculumID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar grade curricular do aluno: %w", err)
+		}
+		requirements.source = "curriculum"
+		requirements.requiredCredits = curriculum.RequiredCredits
+		requirements.buckets = curriculum.ElectiveBuckets
+		for _, subject := range curriculum.Subjects {
+			if subject.Mandatory {
+				requirements.mandatory = append(requirements.mandatory, subject)
+			} else if subject.Bucket != "" {
+				requirements.bucketOf[subject.SubjectID] = subject.Bucket
+			}
+		}
+	} else {
+		subjects, err := s.subjectRepo.GetAllMandatorySubjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar matérias obrigatórias do catálogo: %w", err)
+		}
+		requirements.source = "catalog"
+		requirements.requiredCredits = s.catalogCredits
+		for _, subject := range subjects {
+			requirements.mandatory = append(requirements.mandatory, models.CurriculumSubject{
+				SubjectID: subject.ID, Name: subject.Name, Credits: subject.Credits, Year: subject.Year, Mandatory: true,
+			})
+		}
+	}
+	cache[student.CurriculumID] = requirements
+	return requirements, nil
+}
+
+// evaluateDegree compara as matérias do aluno (records) com os requisitos. Matérias aprovadas
+// cumprem o requisito; as em curso o deixam "in_progress", ainda entre os que faltam.
+func evaluateDegree(student *models.Student, requirements *degreeRequirements, records []models.StudentSubjectRecord) *models.DegreeAudit {
+	audit := &models.DegreeAudit{
+		StudentID:        student.ID,
+		Enrollment:       student.Enrollment,
+		Name:             student.Name,
+		CurrentYear:      student.CurrentYear,
+		ProgramID:        student.ProgramID,
+		CurriculumID:     student.CurriculumID,
+		RequirementsFrom: requirements.source,
+		RequiredCredits:  requirements.requiredCredits,
+		Satisfied:        []models.DegreeRequirement{},
+		Missing:          []models.DegreeRequirement{},
+	}
+
+	statuses := map[string]string{}
+	bucketEarned, bucketInProgress := map[string]int{}, map[string]int{}
+	for _, record := range records {
+		statuses[record.SubjectID] = record.Status
+		bucket := requirements.bucketOf[record.SubjectID]
+		switch record.Status {
+		case models.SubjectStatusPassed:
+			audit.EarnedCredits += record.Credits
+			bucketEarned[bucket] += record.Credits
+		case models.SubjectStatusEnrolled:
+			audit.InProgressCredits += record.Credits
+			bucketInProgress[bucket] += record.Credits
+		}
+	}
+
+	add := func(requirement models.DegreeRequirement) {
+		if requirement.Status == models.DegreeRequirementSatisfied {
+			audit.Satisfied = append(audit.Satisfied, requirement)
+		} else {
+			audit.Missing = append(audit.Missing, requirement)
+		}
+	}
+
+	if requirements.requiredCredits > 0 {
+		add(models.DegreeRequirement{
+			Type:              models.DegreeRequirementTotalCredits,
+			Description:       "Total de créditos",
+			RequiredCredits:   requirements.requiredCredits,
+			EarnedCredits:     audit.EarnedCredits,
+			InProgressCredits: audit.InProgressCredits,
+			Status:            creditStatus(requirements.requiredCredits, audit.EarnedCredits, audit.InProgressCredits),
+		})
+	}
+	for _, subject := range requirements.mandatory {
+		requirement := models.DegreeRequirement{
+			Type:            models.DegreeRequirementMandatory,
+			Description:     subject.Name,
+			SubjectID:       subject.SubjectID,
+			Year:            subject.Year,
+			RequiredCredits: subject.Credits,
+			Status:          models.DegreeRequirementMissing,
+		}
+		switch statuses[subject.SubjectID] {
+		case models.SubjectStatusPassed:
+			requirement.EarnedCredits = subject.Credits
+			requirement.Status = models.DegreeRequirementSatisfied
+		case models.SubjectStatusEnrolled:
+			requirement.InProgressCredits = subject.Credits
+			requirement.Status = models.DegreeRequirementInProgress
+		}
+		add(requirement)
+	}
+	for _, bucket := range requirements.buckets {
+		add(models.DegreeRequirement{
+			Type:              models.DegreeRequirementElective,
+			Description:       bucket.Name,
+			Bucket:            bucket.Name,
+			RequiredCredits:   bucket.MinCredits,
+			EarnedCredits:     bucketEarned[bucket.Name],
+			InProgressCredits: bucketInProgress[bucket.Name],
+			Status:            creditStatus(bucket.MinCredits, bucketEarned[bucket.Name], bucketInProgress[bucket.Name]),
+		})
+	}
+	audit.Eligible = len(audit.Missing) == 0
+	return audit
+}
+
+// creditStatus é a situação de um requisito de créditos mínimos.
+func creditStatus(required, earned, inProgress int) string {
+	switch {
+	case earned >= required:
+		return models.DegreeRequirementSatisfied
+	case earned+inProgress >= required:
+		return models.DegreeRequirementInProgress
+	default:
+		return models.DegreeRequirementMissing
+	}
+}
@@ -0,0 +1,138 @@
+// services/degree_audit_service_test.go
+package services
+
+import (
+	"college-app-v1/models"
+	"reflect"
+	"testing"
+)
+
+func TestCreditStatus(t *testing.T) {
+	tests := []struct {
+		name                         string
+		required, earned, inProgress int
+		want                         string
+	}{
+		{name: "exatamente o exigido", required: 20, earned: 20, want: models.DegreeRequirementSatisfied},
+		{name: "acima do exigido", required: 20, earned: 24, inProgress: 4, want: models.DegreeRequirementSatisfied},
+		{name: "completado pelas matérias em curso", required: 20, earned: 12, inProgress: 8, want: models.DegreeRequirementInProgress},
+		{name: "em curso insuficiente", required: 20, earned: 12, inProgress: 7, want: models.DegreeRequirementMissing},
+		{name: "nada cursado", required: 4, want: models.DegreeRequirementMissing},
+		{name: "sem mínimo", required: 0, want: models.DegreeRequirementSatisfied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := creditStatus(tt.required, tt.earned, tt.inProgress); got != tt.want {
+				t.Errorf("creditStatus(%d, %d, %d) = %q, esperava %q", tt.required, tt.earned, tt.inProgress, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDegree(t *testing.T) {
+	curriculum := &degreeRequirements{
+		source:          "curriculum",
+		requiredCredits: 20,
+		mandatory: []models.CurriculumSubject{
+			{SubjectID: "calc", Name: "Cálculo I", Credits: 4, Year: 1, Mandatory: true},
+			{SubjectID: "alg", Name: "Álgebra Linear", Credits: 4, Year: 1, Mandatory: true},
+		},
+		buckets:  []models.ElectiveBucket{{Name: "Humanas", MinCredits: 4}},
+		bucketOf: map[string]string{"hum1": "Humanas", "hum2": "Humanas"},
+	}
+	catalog := &degreeRequirements{
+		source:    "catalog",
+		mandatory: []models.CurriculumSubject{{SubjectID: "calc", Name: "Cálculo I", Credits: 4, Year: 1, Mandatory: true}},
+		bucketOf:  map[string]string{},
+	}
+	record := func(subjectID string, credits int, status string) models.StudentSubjectRecord {
+		return models.StudentSubjectRecord{SubjectID: subjectID, Name: subjectID, Credits: credits, Status: status}
+	}
+
+	tests := []struct {
+		name           string
+		requirements   *degreeRequirements
+		records        []models.StudentSubjectRecord
+		wantEarned     int
+		wantInProgress int
+		wantEligible   bool
+		wantStatus     map[string]string // Descrição do requisito -> situação
+	}{
+		{
+			name:         "sem matérias",
+			requirements: curriculum,
+			wantStatus: map[string]string{"Total de créditos": models.DegreeRequirementMissing, "Cálculo I": models.DegreeRequirementMissing,
+				"Álgebra Linear": models.DegreeRequirementMissing, "Humanas": models.DegreeRequirementMissing},
+		},
+		{
+			name:         "todos os requisitos aprovados",
+			requirements: curriculum,
+			records: []models.StudentSubjectRecord{record("calc", 4, models.SubjectStatusPassed), record("alg", 4, models.SubjectStatusPassed),
+				record("hum1", 4, models.SubjectStatusPassed), record("extra", 8, models.SubjectStatusPassed)},
+			wantEarned:   20,
+			wantEligible: true,
+			wantStatus: map[string]string{"Total de créditos": models.DegreeRequirementSatisfied, "Cálculo I": models.DegreeRequirementSatisfied,
+				"Álgebra Linear": models.DegreeRequirementSatisfied, "Humanas": models.DegreeRequirementSatisfied},
+		},
+		{
+			name:         "matérias em curso deixam os requisitos em andamento",
+			requirements: curriculum,
+			records: []models.StudentSubjectRecord{record("calc", 4, models.SubjectStatusPassed), record("alg", 4, models.SubjectStatusEnrolled),
+				record("hum1", 4, models.SubjectStatusEnrolled), record("extra", 8, models.SubjectStatusPassed)},
+			wantEarned:     12,
+			wantInProgress: 8,
+			wantStatus: map[string]string{"Total de créditos": models.DegreeRequirementInProgress, "Cálculo I": models.DegreeRequirementSatisfied,
+				"Álgebra Linear": models.DegreeRequirementInProgress, "Humanas": models.DegreeRequirementInProgress},
+		},
+		{
+			name:         "reprovações não contam",
+			requirements: curriculum,
+			records: []models.StudentSubjectRecord{record("calc", 4, models.SubjectStatusFailed), record("alg", 4, models.SubjectStatusPassed),
+				record("hum1", 4, models.SubjectStatusFailed), record("hum2", 2, models.SubjectStatusPassed)},
+			wantEarned: 6,
+			wantStatus: map[string]string{"Total de créditos": models.DegreeRequirementMissing, "Cálculo I": models.DegreeRequirementMissing,
+				"Álgebra Linear": models.DegreeRequirementSatisfied, "Humanas": models.DegreeRequirementMissing},
+		},
+		{
+			name:         "catálogo sem mínimo de créditos",
+			requirements: catalog,
+			records:      []models.StudentSubjectRecord{record("calc", 4, models.SubjectStatusPassed)},
+			wantEarned:   4,
+			wantEligible: true,
+			wantStatus:   map[string]string{"Cálculo I": models.DegreeRequirementSatisfied},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student := &models.Student{ID: "a1", Enrollment: "2025M0001", Name: "Ana", CurrentYear: 4}
+			audit := evaluateDegree(student, tt.requirements, tt.records)
+
+			if audit.StudentID != student.ID || audit.RequirementsFrom != tt.requirements.source || audit.RequiredCredits != tt.requirements.requiredCredits {
+				t.Errorf("cabeçalho = %s/%s/%d, esperava %s/%s/%d", audit.StudentID, audit.RequirementsFrom, audit.RequiredCredits,
+					student.ID, tt.requirements.source, tt.requirements.requiredCredits)
+			}
+			if audit.EarnedCredits != tt.wantEarned || audit.InProgressCredits != tt.wantInProgress {
+				t.Errorf("créditos = %d aprovados, %d em curso; esperava %d e %d", audit.EarnedCredits, audit.InProgressCredits, tt.wantEarned, tt.wantInProgress)
+			}
+			if audit.Eligible != tt.wantEligible {
+				t.Errorf("Eligible = %v, esperava %v", audit.Eligible, tt.wantEligible)
+			}
+			got := map[string]string{}
+			for _, requirement := range audit.Satisfied {
+				if requirement.Status != models.DegreeRequirementSatisfied {
+					t.Errorf("requisito %q em Satisfied com situação %q", requirement.Description, requirement.Status)
+				}
+				got[requirement.Description] = requirement.Status
+			}
+			for _, requirement := range audit.Missing {
+				if requirement.Status == models.DegreeRequirementSatisfied {
+					t.Errorf("requisito %q cumprido em Missing", requirement.Description)
+				}
+				got[requirement.Description] = requirement.Status
+			}
+			if !reflect.DeepEqual(got, tt.wantStatus) {
+				t.Errorf("requisitos = %v, esperava %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
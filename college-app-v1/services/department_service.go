@@ -0,0 +1,197 @@
+// services/department_service.go
+package services
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// departmentCodePattern define o formato dos códigos de departamento (ex: DCC, ENG2).
+var departmentCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// DepartmentService implementa as regras de negócio de departamentos.
+type DepartmentService struct {
+	transactor     *repositories.Transactor
+	departmentRepo *repositories.DepartmentRepository
+	teacherRepo    *repositories.TeacherRepository
+	subjectRepo    *repositories.SubjectRepository
+	audit          *AuditService
+}
+
+// NewDepartmentService cria uma nova instância de DepartmentService.
+func NewDepartmentService(transactor *repositories.Transactor, dr *repositories.DepartmentRepository, tr *repositories.TeacherRepository, subR *repositories.SubjectRepository, audit *AuditService) *DepartmentService {
+	return &DepartmentService{transactor: transactor, departmentRepo: dr, teacherRepo: tr, subjectRepo: subR, audit: audit}
+}
+
+// CreateDepartment cria um departamento. Como ainda não tem professores, é criado sem chefe.
+func (s *DepartmentService) CreateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, span := tracing.Start(ctx, "DepartmentService.CreateDepartment")
+	defer span.End()
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return err
+	}
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.departmentRepo.WithTx(tx).CreateDepartment(ctx, department); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityDepartment, department.ID, AuditActionCreate, nil, department)
+	})
+}
+
+// GetDepartmentByID busca um departamento pelo ID.
+func (s *DepartmentService) GetDepartmentByID(ctx context.Context, id string) (*models.Department, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetDepartmentByID")
+	defer span.End()
+	department, err := s.departmentRepo.GetDepartmentByID(ctx, id)
+	if err != nil {
+		if err.Error() == "departamento não encontrado" {
+			return nil, fmt.Errorf("departamento com ID %s não encontrado", id)
+		}
+		return nil, fmt.Errorf("erro ao buscar departamento por ID: %w", err)
+	}
+	return department, nil
+}
+
+// GetAllDepartments busca todos os departamentos.
+func (s *DepartmentService) GetAllDepartments(ctx context.Context) ([]models.Department, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetAllDepartments")
+	defer span.End()
+	departments, err := s.departmentRepo.GetAllDepartments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar departamentos: %w", err)
+	}
+	return departments, nil
+}
+
+// UpdateDepartment atualiza um departamento. department.Version é a versão conhecida pelo cliente (If-Match).
+func (s *DepartmentService) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	ctx, span := tracing.Start(ctx, "DepartmentService.UpdateDepartment")
+	defer span.End()
+	existing, err := s.departmentRepo.GetDepartmentByID(ctx, department.ID)
+	if err != nil {
+		if err.Error() == "departamento não encontrado" {
+			return errors.New("departamento não encontrado para atualização")
+		}
+		return fmt.Errorf("erro ao buscar departamento para atualização: %w", err)
+	}
+	if existing.Version != department.Version {
+		return repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return err
+	}
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.departmentRepo.WithTx(tx).UpdateDepartment(ctx, department); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityDepartment, department.ID, AuditActionUpdate, existing, department)
+	})
+}
+
+// validateDepartment valida código e nome (únicos) e o chefe, que deve ser um professor ativo
+// do próprio departamento.
+func (s *DepartmentService) validateDepartment(ctx context.Context, department *models.Department) error {
+	validation := &ValidationError{}
+	department.Name = strings.Join(strings.Fields(department.Name), " ")
+	if department.Name == "" {
+		validation.add("name", "nome do departamento é obrigatório")
+	}
+	department.Code = strings.ToUpper(strings.TrimSpace(department.Code))
+	if !departmentCodePattern.MatchString(department.Code) {
+		validation.add("code", "código deve ter de 2 a 10 letras ou dígitos (ex: DCC)")
+	}
+	if err := validation.errOrNil(); err != nil {
+		return err
+	}
+
+	codeTaken, nameTaken, err := s.departmentRepo.CodeOrNameTaken(ctx, department.Code, department.Name, department.ID)
+	if err != nil {
+		return err
+	}
+	if codeTaken {
+		validation.add("code", "código já cadastrado")
+	}
+	if nameTaken {
+		validation.add("name", "já existe um departamento com este nome")
+	}
+
+	department.HeadTeacherID = strings.TrimSpace(department.HeadTeacherID)
+	department.HeadTeacherName = ""
+	if department.HeadTeacherID != "" {
+		head, err := s.teacherRepo.GetTeacherByID(ctx, department.HeadTeacherID, false)
+		if err != nil && !errors.Is(err, ErrTeacherNotFound) {
+			return err
+		}
+		if head == nil || head.DepartmentID != department.ID {
+			validation.add("head_teacher_id", "o chefe deve ser um professor ativo do departamento")
+		} else {
+			department.HeadTeacherName = head.Name
+		}
+	}
+	return validation.errOrNil()
+}
+
+// GetDepartmentTeachers busca os professores ativos do departamento.
+func (s *DepartmentService) GetDepartmentTeachers(ctx context.Context, id string) ([]models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetDepartmentTeachers")
+	defer span.End()
+	if _, err := s.GetDepartmentByID(ctx, id); err != nil {
+		return nil, err
+	}
+	teachers, err := s.teacherRepo.GetTeachersByDepartmentID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar professores do departamento: %w", err)
+	}
+	return teachers, nil
+}
+
+// GetDepartmentSubjects busca as matérias lecionadas pelos professores do departamento.
+func (s *DepartmentService) GetDepartmentSubjects(ctx context.Context, id string) ([]models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetDepartmentSubjects")
+	defer span.End()
+	if _, err := s.GetDepartmentByID(ctx, id); err != nil {
+		return nil, err
+	}
+	subjects, err := s.subjectRepo.GetSubjectsByDepartmentID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar matérias do departamento: %w", err)
+	}
+	return subjects, nil
+}
+
+// resolveTeacherDepartment valida o departamento de um professor, informado por department_id
+// ou, alternativamente, pelo código ou nome em department. Em caso de sucesso preenche
+// DepartmentID e Department (nome oficial).
+func resolveTeacherDepartment(ctx context.Context, departmentRepo *repositories.DepartmentRepository, teacher *models.Teacher) error {
+	field, value := "department_id", strings.TrimSpace(teacher.DepartmentID)
+	if value == "" {
+		field, value = "department", strings.TrimSpace(teacher.Department)
+	}
+	if value == "" {
+		return &ValidationError{Fields: map[string]string{"department_id": "departamento do professor é obrigatório"}}
+	}
+
+	var department *models.Department
+	var err error
+	if field == "department_id" {
+		department, err = departmentRepo.GetDepartmentByID(ctx, value)
+	} else {
+		department, err = departmentRepo.FindDepartment(ctx, value)
+	}
+	if err != nil {
+		if err.Error() == "departamento não encontrado" {
+			return &ValidationError{Fields: map[string]string{field: "departamento não encontrado"}}
+		}
+		return err
+	}
+	teacher.DepartmentID = department.ID
+	teacher.Department = department.Name
+	return nil
+}
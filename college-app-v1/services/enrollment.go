@@ -0,0 +1,189 @@
+// services/enrollment.go
+package services
+
+import (
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnrollmentPolicy define o formato das matrículas geradas e os turnos aceitos.
+type EnrollmentPolicy struct {
+	SequenceDigits int               // Dígitos da sequência anual (ex: 4 gera 2025M0001)
+	Shifts         map[string]string // Código -> nome do turno (ex: "M" -> "Manhã")
+}
+
+// validShift indica se shift (já em maiúscula) é um dos turnos configurados.
+func (p EnrollmentPolicy) validShift(shift string) bool {
+	_, ok := p.Shifts[shift]
+	return ok
+}
+
+// shiftCodes lista os turnos em ordem alfabética, com ou sem o nome (ex: "'M' (Manhã), 'N' (Noite) ou 'T' (Tarde)").
+func (p EnrollmentPolicy) shiftCodes(withNames bool) string {
+	codes := make([]string, 0, len(p.Shifts))
+	for code := range p.Shifts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	items := make([]string, len(codes))
+	for i, code := range codes {
+		items[i] = "'" + code + "'"
+		if withNames && p.Shifts[code] != "" {
+			items[i] += " (" + p.Shifts[code] + ")"
+		}
+	}
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " ou " + items[len(items)-1]
+}
+
+// nextSequence retorna a sequência seguinte à da última matrícula do ano e turno
+// (os últimos SequenceDigits caracteres), ou 1 se não houver matrícula ou o formato for inesperado.
+func (p EnrollmentPolicy) nextSequence(ctx context.Context, logger *slog.Logger, lastEnrollment string) int {
+	if lastEnrollment == "" {
+		return 1
+	}
+	if len(lastEnrollment) <= p.SequenceDigits { // Verifica se há caracteres suficientes para a sequência
+		logger.WarnContext(ctx, "última matrícula com formato inesperado, reiniciando sequência", "enrollment", lastEnrollment)
+		return 1
+	}
+	seqStr := lastEnrollment[len(lastEnrollment)-p.SequenceDigits:]
+	lastSequence, err := strconv.Atoi(seqStr)
+	if err != nil {
+		logger.WarnContext(ctx, "sequência da última matrícula inválida, reiniciando sequência", "enrollment", lastEnrollment, "error", err)
+		return 1
+	}
+	return lastSequence + 1
+}
+
+// format monta a matrícula no formato [código do curso]<ano><turno><sequência com SequenceDigits dígitos>
+// (ex: 2025M0001, ou SI2025M0001 para cursos que usam o código na matrícula).
+func (p EnrollmentPolicy) format(programCode string, year int, shift string, sequence int) string {
+	return fmt.Sprintf("%s%d%s%0*d", programCode, year, shift, p.SequenceDigits, sequence)
+}
+
+// enrollmentPattern separa ano, turno e sequência no fim da matrícula (ex: SI2025M0001).
+var enrollmentPattern = regexp.MustCompile(`(\d{4})([A-Z])(\d+)$`)
+
+// EnrollmentChange descreve a matrícula de um aluno antes e depois da renumeração.
+type EnrollmentChange struct {
+	StudentID string `json:"student_id"`
+	Name      string `json:"name"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Reason    string `json:"reason,omitempty"` // Motivo de o aluno ter sido ignorado
+}
+
+// EnrollmentRegenerationResult é o resultado de EnrollmentService.RegenerateEnrollments.
+type EnrollmentRegenerationResult struct {
+	DryRun  bool               `json:"dry_run"`        // Apenas simulação, nada foi gravado
+	Year    int                `json:"year,omitempty"` // Ano de ingresso filtrado (0 = todos)
+	Total   int                `json:"total"`          // Alunos considerados
+	Changed int                `json:"changed"`
+	Changes []EnrollmentChange `json:"changes"`
+	Skipped []EnrollmentChange `json:"skipped"`
+}
+
+// EnrollmentService gera novamente as matrículas dos alunos no formato configurado.
+type EnrollmentService struct {
+	transactor  *repositories.Transactor
+	studentRepo *repositories.StudentRepository
+	enrollment  EnrollmentPolicy
+	audit       *AuditService
+	logger      *slog.Logger
+}
+
+// NewEnrollmentService cria uma nova instância de EnrollmentService.
+func NewEnrollmentService(transactor *repositories.Transactor, sr *repositories.StudentRepository, enrollment EnrollmentPolicy, audit *AuditService, logger *slog.Logger) *EnrollmentService {
+	return &EnrollmentService{transactor: transactor, studentRepo: sr, enrollment: enrollment, audit: audit, logger: logger}
+}
+
+// RegenerateEnrollments renumera as matrículas a partir do turno e do curso atuais de cada aluno
+// (ex: depois de trocas de turno ou de mudar ENROLLMENT_SEQUENCE_DIGITS). O ano de ingresso vem
+// da matrícula atual, e a ordem entre os alunos do mesmo curso, ano e turno é mantida, com a
+// sequência recomeçando em 1. year filtra pelo ano de ingresso (0 = todos). Alunos excluídos
+// também são renumerados, pois continuam ocupando a matrícula. A operação é tudo ou nada e
+// bloqueia cadastros de alunos até terminar.
+func (s *EnrollmentService) RegenerateEnrollments(ctx context.Context, year int, dryRun bool) (*EnrollmentRegenerationResult, error) {
+	ctx, span := tracing.Start(ctx, "EnrollmentService.RegenerateEnrollments")
+	defer span.End()
+	if year < 0 {
+		return nil, &ValidationError{Fields: map[string]string{"year": "deve ser um inteiro positivo"}}
+	}
+
+	result := &EnrollmentRegenerationResult{DryRun: dryRun, Year: year, Changes: []EnrollmentChange{}, Skipped: []EnrollmentChange{}}
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		studentRepo := s.studentRepo.WithTx(tx)
+		if !dryRun {
+			if err := studentRepo.LockEnrollments(ctx); err != nil {
+				return err
+			}
+		}
+		records, err := studentRepo.GetEnrollmentRecords(ctx)
+		if err != nil {
+			return err
+		}
+
+		nextSequence := map[string]int{} // curso + ano + turno -> última sequência atribuída
+		enrollments := map[string]string{}
+		for _, record := range records {
+			change := EnrollmentChange{StudentID: record.ID, Name: record.Name, From: record.Enrollment}
+			match := enrollmentPattern.FindStringSubmatch(record.Enrollment)
+			if match == nil {
+				change.Reason = "matrícula em formato inesperado; ano de ingresso desconhecido"
+				result.Skipped = append(result.Skipped, change)
+				continue
+			}
+			enrollmentYear, _ := strconv.Atoi(match[1])
+			if year != 0 && enrollmentYear != year {
+				continue
+			}
+			result.Total++
+			if !s.enrollment.validShift(record.Shift) {
+				change.Reason = fmt.Sprintf("turno %s não configurado; deve ser %s", record.Shift, s.enrollment.shiftCodes(false))
+				result.Skipped = append(result.Skipped, change)
+				continue
+			}
+
+			group := record.ProgramCode + match[1] + record.Shift
+			nextSequence[group]++
+			change.To = s.enrollment.format(record.ProgramCode, enrollmentYear, record.Shift, nextSequence[group])
+			if change.To != change.From {
+				result.Changes = append(result.Changes, change)
+				enrollments[record.ID] = change.To
+			}
+		}
+		result.Changed = len(result.Changes)
+		if dryRun || len(enrollments) == 0 {
+			return nil
+		}
+		if err := studentRepo.ReplaceEnrollments(ctx, enrollments); err != nil {
+			return err
+		}
+		for _, change := range result.Changes {
+			err := s.audit.Record(ctx, tx, AuditEntityStudent, change.StudentID, AuditActionUpdate,
+				map[string]string{"enrollment": change.From}, map[string]string{"enrollment": change.To})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar novamente as matrículas: %w", err)
+	}
+
+	if !dryRun {
+		s.logger.InfoContext(ctx, "matrículas geradas novamente", "year", year, "total", result.Total, "changed", result.Changed, "skipped", len(result.Skipped))
+	}
+	return result, nil
+}
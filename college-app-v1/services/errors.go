@@ -0,0 +1,68 @@
+// services/errors.go
+package services
+
+import (
+	"college-app-v1/repositories"
+	"sort"
+	"strings"
+)
+
+// ErrVersionConflict é retornado por atualizações e exclusões quando a versão informada
+// pelo cliente (If-Match) não é mais a atual. Reexportado para uso pelos handlers.
+var ErrVersionConflict = repositories.ErrVersionConflict
+
+// ErrTeacherNotFound é retornado (envolvido) quando o professor da operação não existe.
+// Reexportado para uso pelos handlers.
+var ErrTeacherNotFound = repositories.ErrTeacherNotFound
+
+// ErrStudentNotFound é retornado (envolvido) quando o aluno da operação não existe.
+// Reexportado para uso pelos handlers.
+var ErrStudentNotFound = repositories.ErrStudentNotFound
+
+// ErrSubjectNotFound é retornado (envolvido) quando a matéria da operação não existe.
+// Reexportado para uso pelos handlers.
+var ErrSubjectNotFound = repositories.ErrSubjectNotFound
+
+// ValidationError reúne erros de validação por campo (ex: {"shift": "deve ser 'M', 'N' ou 'T'"}).
+// Os handlers respondem 422 com os campos no corpo.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+// Error lista os campos inválidos em ordem alfabética (ex: "name: não pode ser vazio; shift: ...").
+func (e *ValidationError) Error() string {
+	fields := sortedFields(e.Fields)
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + ": " + e.Fields[field]
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add registra um erro para field, mantendo o primeiro erro de cada campo.
+func (e *ValidationError) add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	if _, exists := e.Fields[field]; !exists {
+		e.Fields[field] = message
+	}
+}
+
+// errOrNil retorna e como error apenas se houver algum campo inválido.
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// sortedFields retorna os nomes dos campos inválidos em ordem alfabética.
+func sortedFields(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	return names
+}
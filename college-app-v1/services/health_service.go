@@ -0,0 +1,106 @@
+// services/health_service.go
+package services
+
+import (
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Situações de uma verificação de saúde.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// DependencyCheck é o resultado da verificação de uma dependência.
+type DependencyCheck struct {
+	Status    string `json:"status"`          // "ok" ou "unavailable"
+	LatencyMS int64  `json:"latency_ms"`      // Duração da verificação
+	Error     string `json:"error,omitempty"` // Motivo da falha
+	// Versões do esquema (apenas na verificação "migrations")
+	CurrentVersion  *int `json:"current_version,omitempty"`
+	ExpectedVersion *int `json:"expected_version,omitempty"`
+}
+
+// HealthReport é a resposta do /readyz: a situação geral e a de cada dependência.
+type HealthReport struct {
+	Status string                     `json:"status"` // "ok" somente se todas as dependências estiverem ok
+	Checks map[string]DependencyCheck `json:"checks"`
+}
+
+// HealthService verifica se a aplicação está pronta para receber tráfego.
+type HealthService struct {
+	repo          *repositories.HealthRepository
+	schemaVersion int
+	timeout       time.Duration
+	shuttingDown  atomic.Bool
+}
+
+// NewHealthService cria uma nova instância de HealthService. schemaVersion é a versão do
+// esquema esperada pelo código; timeout limita cada verificação.
+func NewHealthService(repo *repositories.HealthRepository, schemaVersion int, timeout time.Duration) *HealthService {
+	return &HealthService{repo: repo, schemaVersion: schemaVersion, timeout: timeout}
+}
+
+// MarkShuttingDown faz o /readyz responder 503 a partir de agora, para que o balanceador de
+// carga deixe de enviar tráfego enquanto as requisições em andamento terminam.
+func (s *HealthService) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness verifica o banco (ping com tempo limite) e se o esquema está na versão esperada.
+// A verificação do esquema só é feita se o banco responder.
+func (s *HealthService) Readiness(ctx context.Context) *HealthReport {
+	ctx, span := tracing.Start(ctx, "HealthService.Readiness")
+	defer span.End()
+	report := &HealthReport{Status: HealthStatusOK, Checks: map[string]DependencyCheck{}}
+	if s.shuttingDown.Load() {
+		report.Checks["server"] = DependencyCheck{Status: HealthStatusUnavailable, Error: "servidor em desligamento"}
+	}
+
+	database := s.check(ctx, func(ctx context.Context) error { return s.repo.Ping(ctx) })
+	report.Checks["database"] = database
+
+	migrations := DependencyCheck{Status: HealthStatusUnavailable, Error: "banco de dados indisponível", ExpectedVersion: &s.schemaVersion}
+	if database.Status == HealthStatusOK {
+		var current int
+		migrations = s.check(ctx, func(ctx context.Context) error {
+			var err error
+			if current, err = s.repo.SchemaVersion(ctx); err != nil {
+				return err
+			}
+			if current < s.schemaVersion {
+				return fmt.Errorf("esquema desatualizado: versão %d, esperada %d", current, s.schemaVersion)
+			}
+			return nil
+		})
+		migrations.CurrentVersion, migrations.ExpectedVersion = &current, &s.schemaVersion
+	}
+	report.Checks["migrations"] = migrations
+
+	for _, check := range report.Checks {
+		if check.Status != HealthStatusOK {
+			report.Status = HealthStatusUnavailable
+		}
+	}
+	return report
+}
+
+// check executa fn com o tempo limite configurado e mede sua duração.
+func (s *HealthService) check(ctx context.Context, fn func(ctx context.Context) error) DependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	result := DependencyCheck{Status: HealthStatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = HealthStatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
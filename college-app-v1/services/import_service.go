@@ -0,0 +1,457 @@
+// services/import_service.go
+package services
+
+import (
+	"bufio"
+	"college-app-v1/metrics"
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxImportRows limita a quantidade de linhas de um arquivo de importação.
+const MaxImportRows = 10000
+
+// ErrInvalidImportFile indica um CSV que não pode ser processado (cabeçalho ausente ou
+// inválido, arquivo grande demais ou malformado). O relatório por linha não se aplica.
+var ErrInvalidImportFile = errors.New("arquivo de importação inválido")
+
+// errImportConflict desfaz a transação de gravação quando a nova verificação de unicidade, feita
+// com a tabela bloqueada, encontra registros cadastrados depois da leitura do arquivo.
+var errImportConflict = errors.New("importação com registros já cadastrados")
+
+// ImportRowError descreve um problema em uma linha do CSV. Row é a linha no arquivo
+// (o cabeçalho é a linha 1); Field fica vazio quando o erro não se refere a uma coluna.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult é o relatório de uma importação em lote.
+type ImportResult struct {
+	Entity    string           `json:"entity"`     // "student", "teacher" ou "subject"
+	DryRun    bool             `json:"dry_run"`    // Apenas validação, nada foi gravado
+	TotalRows int              `json:"total_rows"` // Linhas de dados lidas (sem o cabeçalho)
+	Imported  int              `json:"imported"`   // Registros criados; 0 em dry run ou se houver erros
+	Errors    []ImportRowError `json:"errors"`     // Erros por linha; qualquer erro impede a gravação
+	Created   interface{}      `json:"created,omitempty"`
+}
+
+// ImportService importa alunos, professores e matérias de arquivos CSV. Cada linha passa pelas
+// mesmas validações da criação individual, e a gravação é tudo ou nada, em uma única transação.
+type ImportService struct {
+	transactor     *repositories.Transactor
+	studentRepo    *repositories.StudentRepository
+	teacherRepo    *repositories.TeacherRepository
+	subjectRepo    *repositories.SubjectRepository
+	departmentRepo *repositories.DepartmentRepository
+	enrollment     EnrollmentPolicy
+	audit          *AuditService
+	logger         *slog.Logger
+}
+
+// NewImportService cria uma nova instância de ImportService.
+func NewImportService(transactor *repositories.Transactor, sr *repositories.StudentRepository, tr *repositories.TeacherRepository, subR *repositories.SubjectRepository, dr *repositories.DepartmentRepository, enrollment EnrollmentPolicy, audit *AuditService, logger *slog.Logger) *ImportService {
+	return &ImportService{transactor: transactor, studentRepo: sr, teacherRepo: tr, subjectRepo: subR, departmentRepo: dr, enrollment: enrollment, audit: audit, logger: logger}
+}
+
+// ImportStudents importa alunos de um CSV com as colunas name, shift e current_year (opcional).
+// As matrículas são geradas como em CreateStudent, em sequência por turno.
+func (s *ImportService) ImportStudents(ctx context.Context, file io.Reader, dryRun bool) (*ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportStudents")
+	defer span.End()
+	result := &ImportResult{Entity: AuditEntityStudent, DryRun: dryRun, Errors: []ImportRowError{}}
+	var students []*models.Student
+
+	err := readImportCSV(file, []string{"name", "shift"}, []string{"current_year"}, func(row int, values map[string]string) error {
+		result.TotalRows++
+		student := &models.Student{Name: strings.TrimSpace(values["name"]), Shift: strings.TrimSpace(values["shift"])}
+		parseErrors := map[string]string{}
+		student.CurrentYear = parseImportInt(values["current_year"], "current_year", "ano atual deve ser um número inteiro", parseErrors)
+		if !result.addRowErrors(row, parseErrors, validateNewStudent(s.enrollment, student)) {
+			students = append(students, student)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	// As matrículas são calculadas depois de bloquear a tabela de alunos, como em
+	// RegenerateEnrollments, para que cadastros concorrentes não gerem as mesmas. Um conflito
+	// restante (ex: matrícula gravada fora da API) aborta a transação, então cada tentativa
+	// recalcula todas as matrículas em uma nova.
+	enrollmentYear := time.Now().Year()
+	conflict := &models.Student{} // Aluno cuja matrícula conflitou, para o log de retryEnrollmentConflict
+	err = retryEnrollmentConflict(ctx, s.logger, conflict, func() error {
+		return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+			studentRepo := s.studentRepo.WithTx(tx)
+			if err := studentRepo.LockEnrollments(ctx); err != nil {
+				return err
+			}
+			nextSequence := map[string]int{} // turno -> próxima sequência de matrícula
+			for _, student := range students {
+				if _, ok := nextSequence[student.Shift]; !ok {
+					lastEnrollment, err := studentRepo.GetLastEnrollmentForYearAndShift(ctx, "", enrollmentYear, student.Shift)
+					if err != nil {
+						return fmt.Errorf("erro ao buscar última matrícula para geração automática: %w", err)
+					}
+					nextSequence[student.Shift] = s.enrollment.nextSequence(ctx, s.logger, lastEnrollment)
+				}
+				student.Enrollment = s.enrollment.format("", enrollmentYear, student.Shift, nextSequence[student.Shift])
+				nextSequence[student.Shift]++
+				if err := studentRepo.CreateStudent(ctx, student); err != nil {
+					conflict.Enrollment = student.Enrollment
+					return err
+				}
+				if err := s.audit.Record(ctx, tx, AuditEntityStudent, student.ID, AuditActionCreate, nil, student); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gravar alunos importados: %w", err)
+	}
+
+	metrics.StudentsCreated.WithLabelValues("import").Add(float64(len(students)))
+	result.Imported = len(students)
+	result.Created = students
+	s.logger.InfoContext(ctx, "alunos importados", "count", result.Imported)
+	return result, nil
+}
+
+// ImportTeachers importa professores de um CSV com as colunas name, email, department (código ou
+// nome de um departamento cadastrado) e contract_type (opcional, padrão "integral"). Emails repetidos no arquivo ou já cadastrados e
+// departamentos inexistentes são reportados como erro da linha.
+func (s *ImportService) ImportTeachers(ctx context.Context, file io.Reader, dryRun bool) (*ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportTeachers")
+	defer span.End()
+	result := &ImportResult{Entity: AuditEntityTeacher, DryRun: dryRun, Errors: []ImportRowError{}}
+	var teachers []*models.Teacher
+	var teacherRows []int          // Linha do arquivo de cada professor
+	seenEmails := map[string]int{} // email normalizado -> linha em que apareceu
+
+	err := readImportCSV(file, []string{"name", "email", "department"}, []string{"contract_type"}, func(row int, values map[string]string) error {
+		result.TotalRows++
+		teacher := &models.Teacher{
+			Name:         strings.TrimSpace(values["name"]),
+			Email:        strings.TrimSpace(values["email"]),
+			Department:   strings.TrimSpace(values["department"]),
+			ContractType: strings.TrimSpace(values["contract_type"]),
+		}
+		if result.addRowErrors(row, nil, validateNewTeacher(teacher)) {
+			return nil
+		}
+		if err := resolveTeacherDepartment(ctx, s.departmentRepo, teacher); err != nil {
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				return err
+			}
+			result.addRowErrors(row, nil, err)
+			return nil
+		}
+		email := strings.ToLower(teacher.Email)
+		if firstRow, dup := seenEmails[email]; dup {
+			result.Errors = append(result.Errors, ImportRowError{Row: row, Field: "email", Message: fmt.Sprintf("email repetido no arquivo (linha %d)", firstRow)})
+			return nil
+		}
+		seenEmails[email] = row
+		exists, err := s.teacherRepo.EmailExists(ctx, teacher.Email)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.Errors = append(result.Errors, ImportRowError{Row: row, Field: "email", Message: "email já cadastrado"})
+			return nil
+		}
+		teachers = append(teachers, teacher)
+		teacherRows = append(teacherRows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		teacherRepo := s.teacherRepo.WithTx(tx)
+		// Outro cadastro pode ter usado um dos emails depois da verificação acima: com a tabela
+		// bloqueada, a verificação é refeita antes de gravar
+		if err := teacherRepo.LockTeachers(ctx); err != nil {
+			return err
+		}
+		for i, teacher := range teachers {
+			exists, err := teacherRepo.EmailExists(ctx, teacher.Email)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Errors = append(result.Errors, ImportRowError{Row: teacherRows[i], Field: "email", Message: "email já cadastrado"})
+			}
+		}
+		if len(result.Errors) > 0 {
+			return errImportConflict
+		}
+		for _, teacher := range teachers {
+			if err := teacherRepo.CreateTeacher(ctx, teacher); err != nil {
+				return err
+			}
+			if err := s.audit.Record(ctx, tx, AuditEntityTeacher, teacher.ID, AuditActionCreate, nil, teacher); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errImportConflict) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gravar professores importados: %w", err)
+	}
+
+	result.Imported = len(teachers)
+	result.Created = teachers
+	s.logger.InfoContext(ctx, "professores importados", "count", result.Imported)
+	return result, nil
+}
+
+// ImportSubjects importa matérias de um CSV com as colunas name, year, credits (opcional) e
+// mandatory (opcional, padrão true).
+// Nomes repetidos no arquivo ou já cadastrados são reportados como erro da linha.
+func (s *ImportService) ImportSubjects(ctx context.Context, file io.Reader, dryRun bool) (*ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportSubjects")
+	defer span.End()
+	result := &ImportResult{Entity: AuditEntitySubject, DryRun: dryRun, Errors: []ImportRowError{}}
+	var subjects []*models.Subject
+	var subjectRows []int         // Linha do arquivo de cada matéria
+	seenNames := map[string]int{} // nome -> linha em que apareceu
+
+	err := readImportCSV(file, []string{"name", "year"}, []string{"credits", "mandatory"}, func(row int, values map[string]string) error {
+		result.TotalRows++
+		subject := &models.Subject{Name: strings.TrimSpace(values["name"])}
+		parseErrors := map[string]string{}
+		subject.Year = parseImportInt(values["year"], "year", "ano deve ser um número inteiro", parseErrors)
+		subject.Credits = parseImportInt(values["credits"], "credits", "créditos devem ser um número inteiro", parseErrors)
+		subject.Mandatory = parseImportBool(values["mandatory"], true, "mandatory", "obrigatoriedade deve ser true ou false", parseErrors)
+		if result.addRowErrors(row, parseErrors, validateNewSubject(subject)) {
+			return nil
+		}
+		if firstRow, dup := seenNames[subject.Name]; dup {
+			result.Errors = append(result.Errors, ImportRowError{Row: row, Field: "name", Message: fmt.Sprintf("nome repetido no arquivo (linha %d)", firstRow)})
+			return nil
+		}
+		seenNames[subject.Name] = row
+		exists, err := s.subjectRepo.NameExists(ctx, subject.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.Errors = append(result.Errors, ImportRowError{Row: row, Field: "name", Message: "matéria já cadastrada com este nome"})
+			return nil
+		}
+		subjects = append(subjects, subject)
+		subjectRows = append(subjectRows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		subjectRepo := s.subjectRepo.WithTx(tx)
+		// Como nos professores, os nomes são conferidos de novo com a tabela bloqueada
+		if err := subjectRepo.LockSubjects(ctx); err != nil {
+			return err
+		}
+		for i, subject := range subjects {
+			exists, err := subjectRepo.NameExists(ctx, subject.Name)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Errors = append(result.Errors, ImportRowError{Row: subjectRows[i], Field: "name", Message: "matéria já cadastrada com este nome"})
+			}
+		}
+		if len(result.Errors) > 0 {
+			return errImportConflict
+		}
+		for _, subject := range subjects {
+			if err := subjectRepo.CreateSubject(ctx, subject); err != nil {
+				return err
+			}
+			if err := s.audit.Record(ctx, tx, AuditEntitySubject, subject.ID, AuditActionCreate, nil, subject); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errImportConflict) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gravar matérias importadas: %w", err)
+	}
+
+	result.Imported = len(subjects)
+	result.Created = subjects
+	s.logger.InfoContext(ctx, "matérias importadas", "count", result.Imported)
+	return result, nil
+}
+
+// addRowErrors adiciona ao relatório os erros de conversão (parseErrors, campo -> mensagem) e os
+// campos inválidos de err (um *ValidationError) da linha, e informa se a linha tinha erros.
+// Um campo que não pôde ser convertido não é reportado de novo pela validação.
+func (r *ImportResult) addRowErrors(row int, parseErrors map[string]string, err error) bool {
+	fields := map[string]string{}
+	for field, message := range parseErrors {
+		fields[field] = message
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		for field, message := range validationErr.Fields {
+			if _, exists := fields[field]; !exists {
+				fields[field] = message
+			}
+		}
+	} else if err != nil {
+		r.Errors = append(r.Errors, ImportRowError{Row: row, Message: err.Error()})
+	}
+	for _, field := range sortedFields(fields) {
+		r.Errors = append(r.Errors, ImportRowError{Row: row, Field: field, Message: fields[field]})
+	}
+	return err != nil || len(fields) > 0
+}
+
+// parseImportInt converte uma célula numérica; vazia resulta em 0 (valor padrão da regra de
+// criação). Se a conversão falhar, registra message em parseErrors[field].
+func parseImportInt(raw, field, message string, parseErrors map[string]string) int {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		parseErrors[field] = message
+	}
+	return value
+}
+
+// parseImportBool converte uma célula booleana (true/false, 1/0, sim/não); vazia resulta em
+// defaultValue. Em caso de erro, registra message em parseErrors[field].
+func parseImportBool(raw string, defaultValue bool, field, message string, parseErrors map[string]string) bool {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return defaultValue
+	case "sim", "s":
+		return true
+	case "não", "nao", "n":
+		return false
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		parseErrors[field] = message
+		return defaultValue
+	}
+	return value
+}
+
+// readImportCSV lê o CSV linha a linha, chamando handleRow com os valores indexados pelo nome
+// da coluna. O cabeçalho deve conter as colunas required e pode conter as optional; a ordem é livre.
+// O separador (vírgula ou ponto e vírgula, comum no Excel em português) é detectado pelo cabeçalho.
+func readImportCSV(file io.Reader, required, optional []string, handleRow func(row int, values map[string]string) error) error {
+	buffered := bufio.NewReader(file)
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+	separator := ','
+	if header, _, _ := strings.Cut(string(firstLine), "\n"); strings.Count(header, ";") > strings.Count(header, ",") {
+		separator = ';'
+	}
+	return readImportCSVWith(csvReader(buffered, separator), required, optional, handleRow)
+}
+
+func csvReader(r io.Reader, separator rune) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = separator
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Linhas com colunas a menos são tratadas como valores vazios
+	return reader
+}
+
+func readImportCSVWith(reader *csv.Reader, required, optional []string, handleRow func(row int, values map[string]string) error) error {
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: arquivo vazio", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+
+	allowed := map[string]bool{}
+	for _, column := range append(append([]string{}, required...), optional...) {
+		allowed[column] = true
+	}
+	columns := make([]string, len(header))
+	present := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) // BOM do Excel
+		if !allowed[column] {
+			return fmt.Errorf("%w: coluna desconhecida %q (colunas aceitas: %s)", ErrInvalidImportFile, column, strings.Join(append(append([]string{}, required...), optional...), ", "))
+		}
+		if present[column] {
+			return fmt.Errorf("%w: coluna %q repetida", ErrInvalidImportFile, column)
+		}
+		columns[i] = column
+		present[column] = true
+	}
+	for _, column := range required {
+		if !present[column] {
+			return fmt.Errorf("%w: coluna obrigatória %q ausente", ErrInvalidImportFile, column)
+		}
+	}
+
+	for rows := 0; ; rows++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+		}
+		if rows >= MaxImportRows {
+			return fmt.Errorf("%w: o arquivo excede o limite de %d linhas", ErrInvalidImportFile, MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if i < len(record) {
+				values[column] = record[i]
+			}
+		}
+		if err := handleRow(line, values); err != nil {
+			return err
+		}
+	}
+}
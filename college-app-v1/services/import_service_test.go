@@ -0,0 +1,160 @@
+// services/import_service_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []map[string]string // Valores de cada linha de dados
+		rows    []int               // Linha de cada registro no arquivo
+		wantErr bool
+	}{
+		{name: "vírgula", input: "name,shift\nAna,M\nBia,N\n",
+			want: []map[string]string{{"name": "Ana", "shift": "M"}, {"name": "Bia", "shift": "N"}}, rows: []int{2, 3}},
+		{name: "ponto e vírgula do Excel", input: "name;shift\nSilva, Ana;M\n",
+			want: []map[string]string{{"name": "Silva, Ana", "shift": "M"}}, rows: []int{2}},
+		{name: "mais vírgulas que ponto e vírgula no cabeçalho", input: "name,shift,current_year\n\"Ana; Bia\",M,2\n",
+			want: []map[string]string{{"name": "Ana; Bia", "shift": "M", "current_year": "2"}}, rows: []int{2}},
+		{name: "BOM, maiúsculas e ordem livre", input: "\ufeffSHIFT, Name\nM,Ana\n",
+			want: []map[string]string{{"name": "Ana", "shift": "M"}}, rows: []int{2}},
+		{name: "colunas a menos viram valores vazios", input: "name,shift,current_year\nAna\n",
+			want: []map[string]string{{"name": "Ana"}}, rows: []int{2}},
+		{name: "campo com quebra de linha mantém a linha do registro", input: "name,shift\n\"Ana\nMaria\",M\nBia,N\n",
+			want: []map[string]string{{"name": "Ana\nMaria", "shift": "M"}, {"name": "Bia", "shift": "N"}}, rows: []int{2, 4}},
+		{name: "só cabeçalho", input: "name,shift\n", want: nil},
+		{name: "arquivo vazio", input: "", wantErr: true},
+		{name: "coluna obrigatória ausente", input: "name\nAna\n", wantErr: true},
+		{name: "coluna desconhecida", input: "name,shift,idade\nAna,M,20\n", wantErr: true},
+		{name: "coluna repetida", input: "name,shift,name\nAna,M,Ana\n", wantErr: true},
+		{name: "aspas malformadas", input: "name,shift\n\"Ana,M\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []map[string]string
+			var rows []int
+			err := readImportCSV(strings.NewReader(tt.input), []string{"name", "shift"}, []string{"current_year"}, func(row int, values map[string]string) error {
+				// Colunas ausentes da linha não entram no mapa; remove as vazias para comparar
+				for column, value := range values {
+					if value == "" {
+						delete(values, column)
+					}
+				}
+				got = append(got, values)
+				rows = append(rows, row)
+				return nil
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImportFile) {
+					t.Fatalf("erro = %v, esperava ErrInvalidImportFile", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valores = %v, esperava %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("linhas = %v, esperava %v", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestReadImportCSVRowLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    int
+		wantErr bool
+	}{
+		{name: "no limite", rows: MaxImportRows},
+		{name: "acima do limite", rows: MaxImportRows + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "name,shift\n" + strings.Repeat("Ana,M\n", tt.rows)
+			err := readImportCSV(strings.NewReader(input), []string{"name", "shift"}, nil, func(int, map[string]string) error { return nil })
+			if gotErr := errors.Is(err, ErrInvalidImportFile); gotErr != tt.wantErr {
+				t.Errorf("erro = %v, esperava erro: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportStudentsRowValidation(t *testing.T) {
+	svc := &ImportService{enrollment: EnrollmentPolicy{SequenceDigits: 4, Shifts: map[string]string{"M": "Manhã", "N": "Noite"}}}
+	tests := []struct {
+		name       string
+		input      string
+		wantTotal  int
+		wantErrors []ImportRowError
+	}{
+		{name: "linhas válidas", input: "name,shift,current_year\nAna,m,2\nBia,N,\n", wantTotal: 2, wantErrors: []ImportRowError{}},
+		{name: "erros por campo com a linha do arquivo", input: "name;shift;current_year\nAna;M;1\n;X;-1\nBia;N;dois\n", wantTotal: 3,
+			wantErrors: []ImportRowError{
+				{Row: 3, Field: "current_year", Message: "ano atual deve ser um inteiro positivo"},
+				{Row: 3, Field: "name", Message: "nome do aluno é obrigatório"},
+				{Row: 3, Field: "shift", Message: "turno inválido: X. Deve ser 'M' (Manhã) ou 'N' (Noite)"},
+				{Row: 4, Field: "current_year", Message: "ano atual deve ser um número inteiro"},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.ImportStudents(context.Background(), strings.NewReader(tt.input), true)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if result.TotalRows != tt.wantTotal || result.Imported != 0 || !result.DryRun {
+				t.Errorf("TotalRows/Imported/DryRun = %d/%d/%v, esperava %d/0/true", result.TotalRows, result.Imported, result.DryRun, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(result.Errors, tt.wantErrors) {
+				t.Errorf("erros = %+v, esperava %+v", result.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestParseImportCells(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantInt   int
+		wantBool  bool
+		intError  bool
+		boolError bool
+	}{
+		{name: "vazio usa o padrão", raw: "", wantInt: 0, wantBool: true},
+		{name: "espaços", raw: " 3 ", wantInt: 3, wantBool: true, boolError: true},
+		{name: "sim", raw: "Sim", wantBool: true, intError: true},
+		{name: "não sem acento", raw: "nao", wantBool: false, intError: true},
+		{name: "zero", raw: "0", wantInt: 0, wantBool: false},
+		{name: "false", raw: "FALSE", wantBool: false, intError: true},
+		{name: "texto inválido", raw: "talvez", wantBool: true, intError: true, boolError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parseErrors := map[string]string{}
+			if got := parseImportInt(tt.raw, "credits", "inválido", parseErrors); !tt.intError && got != tt.wantInt {
+				t.Errorf("parseImportInt(%q) = %d, esperava %d", tt.raw, got, tt.wantInt)
+			}
+			if _, failed := parseErrors["credits"]; failed != tt.intError {
+				t.Errorf("parseImportInt(%q): erro = %v, esperava %v", tt.raw, failed, tt.intError)
+			}
+			if got := parseImportBool(tt.raw, true, "mandatory", "inválido", parseErrors); got != tt.wantBool {
+				t.Errorf("parseImportBool(%q) = %v, esperava %v", tt.raw, got, tt.wantBool)
+			}
+			if _, failed := parseErrors["mandatory"]; failed != tt.boolError {
+				t.Errorf("parseImportBool(%q): erro = %v, esperava %v", tt.raw, failed, tt.boolError)
+			}
+		})
+	}
+}
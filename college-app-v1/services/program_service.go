@@ -0,0 +1,268 @@
+// services/program_service.go
+package services
+
+import (
+	"college-app-v1/models"
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// programCodePattern restringe os códigos de curso a letras maiúsculas, para que o prefixo da
+// matrícula (ex: SI2025M0001) nunca se confunda com o ano.
+var programCodePattern = regexp.MustCompile(`^[A-Z]{2,10}$`)
+
+// ProgramService implementa as regras de negócio de cursos e grades curriculares.
+type ProgramService struct {
+	transactor  *repositories.Transactor
+	programRepo *repositories.ProgramRepository
+	subjectRepo *repositories.SubjectRepository
+	audit       *AuditService
+}
+
+// NewProgramService cria uma nova instância de ProgramService.
+func NewProgramService(transactor *repositories.Transactor, pr *repositories.ProgramRepository, subR *repositories.SubjectRepository, audit *AuditService) *ProgramService {
+	return &ProgramService{transactor: transactor, programRepo: pr, subjectRepo: subR, audit: audit}
+}
+
+// CreateProgram cria um novo curso. O código é normalizado para maiúsculas e deve ser único.
+func (s *ProgramService) CreateProgram(ctx context.Context, program *models.Program) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.CreateProgram")
+	defer span.End()
+	if err := s.validateProgram(ctx, program, ""); err != nil {
+		return err
+	}
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.programRepo.WithTx(tx).CreateProgram(ctx, program); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityProgram, program.ID, AuditActionCreate, nil, program)
+	})
+}
+
+// GetProgramByID busca um curso pelo ID.
+func (s *ProgramService) GetProgramByID(ctx context.Context, id string) (*models.Program, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetProgramByID")
+	defer span.End()
+	program, err := s.programRepo.GetProgramByID(ctx, id)
+	if err != nil {
+		if err.Error() == "curso não encontrado" {
+			return nil, fmt.Errorf("curso com ID %s não encontrado", id)
+		}
+		return nil, fmt.Errorf("erro ao buscar curso por ID: %w", err)
+	}
+	return program, nil
+}
+
+// GetAllPrograms busca todos os cursos.
+func (s *ProgramService) GetAllPrograms(ctx context.Context) ([]models.Program, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetAllPrograms")
+	defer span.End()
+	programs, err := s.programRepo.GetAllPrograms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar cursos: %w", err)
+	}
+	return programs, nil
+}
+
+// UpdateProgram atualiza um curso. program.Version é a versão conhecida pelo cliente (If-Match).
+// Alterar o código não muda as matrículas já geradas.
+func (s *ProgramService) UpdateProgram(ctx context.Context, program *models.Program) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.UpdateProgram")
+	defer span.End()
+	existing, err := s.programRepo.GetProgramByID(ctx, program.ID)
+	if err != nil {
+		if err.Error() == "curso não encontrado" {
+			return errors.New("curso não encontrado para atualização")
+		}
+		return fmt.Errorf("erro ao buscar curso para atualização: %w", err)
+	}
+	if existing.Version != program.Version {
+		return repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
+	if err := s.validateProgram(ctx, program, existing.Code); err != nil {
+		return err
+	}
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.programRepo.WithTx(tx).UpdateProgram(ctx, program); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityProgram, program.ID, AuditActionUpdate, existing, program)
+	})
+}
+
+// validateProgram valida nome e código do curso. currentCode é o código atual em uma
+// atualização (pode ser mantido sem acusar duplicidade).
+func (s *ProgramService) validateProgram(ctx context.Context, program *models.Program, currentCode string) error {
+	validation := &ValidationError{}
+	program.Name = strings.TrimSpace(program.Name)
+	if program.Name == "" {
+		validation.add("name", "nome do curso é obrigatório")
+	}
+	program.Code = strings.ToUpper(strings.TrimSpace(program.Code))
+	if !programCodePattern.MatchString(program.Code) {
+		validation.add("code", "código deve ter de 2 a 10 letras (ex: SI)")
+	} else if program.Code != currentCode {
+		exists, err := s.programRepo.CodeExists(ctx, program.Code)
+		if err != nil {
+			return err
+		}
+		if exists {
+			validation.add("code", "código já cadastrado")
+		}
+	}
+	return validation.errOrNil()
+}
+
+// CreateCurriculum cria a próxima versão da grade curricular do curso. Cada matéria deve existir,
+// aparecer uma única vez e ter um ano positivo; eletivas podem pertencer a um dos grupos de
+// eletivas declarados na grade. A grade é gravada inteira ou não é gravada.
+func (s *ProgramService) CreateCurriculum(ctx context.Context, programID string, curriculum *models.Curriculum) error {
+	ctx, span := tracing.Start(ctx, "ProgramService.CreateCurriculum")
+	defer span.End()
+	if _, err := s.GetProgramByID(ctx, programID); err != nil {
+		return err
+	}
+	curriculum.ProgramID = programID
+	curriculum.Description = strings.TrimSpace(curriculum.Description)
+
+	validation := &ValidationError{}
+	if len(curriculum.Subjects) == 0 {
+		validation.add("subjects", "a grade deve ter ao menos uma matéria")
+	}
+	if curriculum.RequiredCredits < 0 {
+		validation.add("required_credits", "não pode ser negativo")
+	}
+	buckets := map[string]bool{}
+	for i := range curriculum.ElectiveBuckets {
+		bucket := &curriculum.ElectiveBuckets[i]
+		field := fmt.Sprintf("elective_buckets[%d]", i)
+		bucket.Name = strings.Join(strings.Fields(bucket.Name), " ")
+		if bucket.Name == "" {
+			validation.add(field+".name", "nome do grupo de eletivas é obrigatório")
+		} else if buckets[bucket.Name] {
+			validation.add(field+".name", "grupo de eletivas repetido na grade")
+		}
+		buckets[bucket.Name] = true
+		if bucket.MinCredits < 1 {
+			validation.add(field+".min_credits", "deve ser um inteiro positivo")
+		}
+	}
+	ids := make([]string, 0, len(curriculum.Subjects))
+	seen := map[string]bool{}
+	for i := range curriculum.Subjects {
+		subject := &curriculum.Subjects[i]
+		field := fmt.Sprintf("subjects[%d]", i)
+		if subject.Year < 1 {
+			validation.add(field+".year", "deve ser um inteiro positivo")
+		}
+		subject.Bucket = strings.Join(strings.Fields(subject.Bucket), " ")
+		if subject.Bucket != "" {
+			if subject.Mandatory {
+				validation.add(field+".bucket", "apenas eletivas pertencem a grupos de eletivas")
+			} else if !buckets[subject.Bucket] {
+				validation.add(field+".bucket", "grupo de eletivas não declarado em elective_buckets")
+			}
+		}
+		if seen[subject.SubjectID] {
+			validation.add(field+".subject_id", "matéria repetida na grade")
+		}
+		seen[subject.SubjectID] = true
+		ids = append(ids, subject.SubjectID)
+	}
+	active, err := s.subjectRepo.ActiveSubjectIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar matérias da grade: %w", err)
+	}
+	for i, subject := range curriculum.Subjects {
+		if !active[subject.SubjectID] {
+			validation.add(fmt.Sprintf("subjects[%d].subject_id", i), "matéria não encontrada")
+		}
+	}
+	if err := validation.errOrNil(); err != nil {
+		return err
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.programRepo.WithTx(tx).CreateCurriculum(ctx, curriculum); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityProgram, programID, AuditActionCreateCurriculum, nil, curriculum)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao gravar grade curricular: %w", err)
+	}
+	return nil
+}
+
+// GetCurricula busca as versões da grade de um curso, da mais recente para a mais antiga.
+func (s *ProgramService) GetCurricula(ctx context.Context, programID string) ([]models.Curriculum, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetCurricula")
+	defer span.End()
+	if _, err := s.GetProgramByID(ctx, programID); err != nil {
+		return nil, err
+	}
+	curricula, err := s.programRepo.GetCurriculaByProgramID(ctx, programID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar grades curriculares: %w", err)
+	}
+	return curricula, nil
+}
+
+// GetCurriculumByID busca uma versão da grade com suas matérias.
+func (s *ProgramService) GetCurriculumByID(ctx context.Context, id string) (*models.Curriculum, error) {
+	ctx, span := tracing.Start(ctx, "ProgramService.GetCurriculumByID")
+	defer span.End()
+	curriculum, err := s.programRepo.GetCurriculumByID(ctx, id)
+	if err != nil {
+		if err.Error() == "grade curricular não encontrada" {
+			return nil, fmt.Errorf("grade curricular com ID %s não encontrada", id)
+		}
+		return nil, fmt.Errorf("erro ao buscar grade curricular: %w", err)
+	}
+	return curriculum, nil
+}
+
+// resolveStudentProgram valida o curso e a grade de um aluno: sem curso, a grade não pode ser
+// informada; com curso, a grade padrão é a versão mais recente e, se informada, deve ser do curso.
+// Retorna o código a usar como prefixo da matrícula ("" se o curso não o usa).
+func resolveStudentProgram(ctx context.Context, programRepo *repositories.ProgramRepository, student *models.Student) (string, error) {
+	if student.ProgramID == "" {
+		if student.CurriculumID != "" {
+			return "", &ValidationError{Fields: map[string]string{"curriculum_id": "exige program_id"}}
+		}
+		return "", nil
+	}
+
+	program, err := programRepo.GetProgramByID(ctx, student.ProgramID)
+	if err != nil {
+		if err.Error() == "curso não encontrado" {
+			return "", &ValidationError{Fields: map[string]string{"program_id": "curso não encontrado"}}
+		}
+		return "", err
+	}
+
+	if student.CurriculumID == "" {
+		if student.CurriculumID, err = programRepo.GetLatestCurriculumID(ctx, program.ID); err != nil {
+			return "", err
+		}
+	} else {
+		curriculum, err := programRepo.GetCurriculumByID(ctx, student.CurriculumID)
+		if err != nil && err.Error() != "grade curricular não encontrada" {
+			return "", err
+		}
+		if curriculum == nil || curriculum.ProgramID != program.ID {
+			return "", &ValidationError{Fields: map[string]string{"curriculum_id": "grade curricular não encontrada para o curso"}}
+		}
+	}
+
+	if program.UseCodeInEnrollment {
+		return program.Code, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,84 @@
+// services/purge_service.go
+package services
+
+import (
+	"college-app-v1/repositories"
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PurgeResult resume uma execução da limpeza de registros excluídos.
+type PurgeResult struct {
+	OlderThan time.Time `json:"older_than"` // Registros excluídos antes deste momento foram removidos
+	Students  []string  `json:"students"`   // IDs de alunos removidos definitivamente
+	Teachers  []string  `json:"teachers"`   // IDs de professores removidos definitivamente
+	Subjects  []string  `json:"subjects"`   // IDs de matérias removidas definitivamente
+}
+
+// PurgeService remove definitivamente registros excluídos (soft delete) há mais tempo
+// que o período de retenção configurado.
+type PurgeService struct {
+	transactor  *repositories.Transactor
+	studentRepo *repositories.StudentRepository
+	teacherRepo *repositories.TeacherRepository
+	subjectRepo *repositories.SubjectRepository
+	audit       *AuditService
+	retention   time.Duration
+	logger      *slog.Logger
+}
+
+// NewPurgeService cria uma nova instância de PurgeService.
+func NewPurgeService(transactor *repositories.Transactor, sr *repositories.StudentRepository, tr *repositories.TeacherRepository, subR *repositories.SubjectRepository, audit *AuditService, retention time.Duration, logger *slog.Logger) *PurgeService {
+	return &PurgeService{transactor: transactor, studentRepo: sr, teacherRepo: tr, subjectRepo: subR, audit: audit, retention: retention, logger: logger}
+}
+
+// PurgeDeleted remove os registros excluídos antes de (agora - retenção).
+// As associações dos registros removidos são apagadas pelo ON DELETE CASCADE.
+func (s *PurgeService) PurgeDeleted(ctx context.Context) (*PurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "PurgeService.PurgeDeleted")
+	defer span.End()
+	result := &PurgeResult{OlderThan: time.Now().Add(-s.retention)}
+
+	// Alunos, professores e matérias são removidos em uma única transação, junto com os eventos de
+	// auditoria: uma falha em qualquer etapa desfaz a limpeza inteira
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if result.Students, err = s.studentRepo.WithTx(tx).PurgeDeletedStudents(ctx, result.OlderThan); err != nil {
+			return fmt.Errorf("erro ao purgar alunos excluídos: %w", err)
+		}
+		if err := s.recordPurge(ctx, tx, AuditEntityStudent, result.Students); err != nil {
+			return err
+		}
+		if result.Teachers, err = s.teacherRepo.WithTx(tx).PurgeDeletedTeachers(ctx, result.OlderThan); err != nil {
+			return fmt.Errorf("erro ao purgar professores excluídos: %w", err)
+		}
+		if err := s.recordPurge(ctx, tx, AuditEntityTeacher, result.Teachers); err != nil {
+			return err
+		}
+		if result.Subjects, err = s.subjectRepo.WithTx(tx).PurgeDeletedSubjects(ctx, result.OlderThan); err != nil {
+			return fmt.Errorf("erro ao purgar matérias excluídas: %w", err)
+		}
+		return s.recordPurge(ctx, tx, AuditEntitySubject, result.Subjects)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "registros excluídos removidos definitivamente",
+		"students", len(result.Students), "teachers", len(result.Teachers), "subjects", len(result.Subjects), "retention", s.retention.String())
+	return result, nil
+}
+
+// recordPurge registra na transação tx a remoção definitiva dos registros ids.
+func (s *PurgeService) recordPurge(ctx context.Context, tx *sql.Tx, entityType string, ids []string) error {
+	for _, id := range ids {
+		if err := s.audit.Record(ctx, tx, entityType, id, AuditActionPurge, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,12 +3,15 @@
 package services
 
 import (
+	"college-app-v1/metrics"
 	"college-app-v1/models"       // Ajuste o caminho do import
 	"college-app-v1/repositories" // Ajuste o caminho do import
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log"
-	"strconv"
+	"log/slog"
 	"strings"
 	"time" // Necessário para time.Now().Year()
 )
@@ -16,69 +19,122 @@ import (
 // StudentService representa as operações de negócio para alunos.
 // Ajustado para receber ponteiros para os repositórios.
 type StudentService struct {
+	transactor  *repositories.Transactor
 	studentRepo *repositories.StudentRepository
 	subjectRepo *repositories.SubjectRepository
+	programRepo *repositories.ProgramRepository
+	enrollment  EnrollmentPolicy
+	audit       *AuditService
+	logger      *slog.Logger
 }
 
 // NewStudentService cria uma nova instância de StudentService.
-func NewStudentService(sr *repositories.StudentRepository, subR *repositories.SubjectRepository) *StudentService {
-	return &StudentService{studentRepo: sr, subjectRepo: subR}
+func NewStudentService(transactor *repositories.Transactor, sr *repositories.StudentRepository, subR *repositories.SubjectRepository, pr *repositories.ProgramRepository, enrollment EnrollmentPolicy, audit *AuditService, logger *slog.Logger) *StudentService {
+	return &StudentService{transactor: transactor, studentRepo: sr, subjectRepo: subR, programRepo: pr, enrollment: enrollment, audit: audit, logger: logger}
 }
 
 // CreateStudent cria um novo aluno com matrícula gerada automaticamente.
-func (s *StudentService) CreateStudent(student *models.Student) error {
-	// 1. Validar o turno (Shift)
-	student.Shift = strings.ToUpper(student.Shift)
-	if student.Shift != "M" && student.Shift != "T" && student.Shift != "N" {
-		return fmt.Errorf("turno inválido: %s. Deve ser 'M' (Manhã), 'T' (Tarde) ou 'N' (Noite)", student.Shift)
+func (s *StudentService) CreateStudent(ctx context.Context, student *models.Student) error {
+	ctx, span := tracing.Start(ctx, "StudentService.CreateStudent")
+	defer span.End()
+	// 1. Validar nome, turno (Shift) e ano atual
+	if err := validateNewStudent(s.enrollment, student); err != nil {
+		return err
+	}
+
+	// 2. Validar curso e grade curricular (a grade padrão é a versão mais recente do curso)
+	programCode, err := resolveStudentProgram(ctx, s.programRepo, student)
+	if err != nil {
+		return err
 	}
 
-	// 2. Obter o ano atual (para a matrícula)
+	// 3. Gerar a matrícula e gravar. Um conflito de matrícula aborta a transação, então cada
+	// tentativa usa uma nova.
+	err = retryEnrollmentConflict(ctx, s.logger, student, func() error {
+		return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+			if err := createStudentWithEnrollment(ctx, s.logger, s.enrollment, s.studentRepo.WithTx(tx), programCode, student); err != nil {
+				return err
+			}
+			return s.audit.Record(ctx, tx, AuditEntityStudent, student.ID, AuditActionCreate, nil, student)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	metrics.StudentsCreated.WithLabelValues("api").Inc()
+	return nil
+}
+
+// createStudentWithEnrollment gera a matrícula de um aluno já validado (ex: 2025M0001, a partir
+// da última do ano e turno; SI2025M0001 com o código do curso) e o grava com repo, que pode
+// estar em uma transação.
+func createStudentWithEnrollment(ctx context.Context, logger *slog.Logger, policy EnrollmentPolicy, repo *repositories.StudentRepository, programCode string, student *models.Student) error {
 	currentYearForEnrollment := time.Now().Year()
 
-	// 3. Buscar a última matrícula para o ano e turno atuais
-	lastEnrollment, err := s.studentRepo.GetLastEnrollmentForYearAndShift(currentYearForEnrollment, student.Shift)
+	lastEnrollment, err := repo.GetLastEnrollmentForYearAndShift(ctx, programCode, currentYearForEnrollment, student.Shift)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar última matrícula para geração automática: %w", err)
 	}
+	student.Enrollment = policy.format(programCode, currentYearForEnrollment, student.Shift, policy.nextSequence(ctx, logger, lastEnrollment))
 
-	// 4. Gerar a nova matrícula
-	newSequence := 1
-	if lastEnrollment != "" {
-		if len(lastEnrollment) >= 5 { // Verifica se há caracteres suficientes para a sequência
-			// Assume que a sequência são os últimos 4 caracteres
-			seqStr := lastEnrollment[len(lastEnrollment)-4:]
-			lastSequence, err := strconv.Atoi(seqStr)
-			if err == nil {
-				newSequence = lastSequence + 1
-			} else {
-				log.Printf("Aviso: CreateStudent: Não foi possível converter sequência '%s' da última matrícula para int. Reiniciando sequência para 1. Erro: %v", seqStr, err)
-			}
-		} else {
-			log.Printf("Aviso: CreateStudent: Matrícula '%s' tem formato inesperado. Reiniciando sequência para 1.", lastEnrollment)
+	return repo.CreateStudent(ctx, student)
+}
+
+// maxEnrollmentAttempts limita as tentativas de gravar um aluno quando cadastros concorrentes
+// do mesmo ano e turno calculam a mesma matrícula.
+const maxEnrollmentAttempts = 3
+
+// retryEnrollmentConflict executa create novamente se ele falhar porque outro cadastro gravou a
+// mesma matrícula (restrição UNIQUE de students.enrollment). create deve recalcular a matrícula
+// e, se usar uma transação, abrir uma nova a cada tentativa.
+func retryEnrollmentConflict(ctx context.Context, logger *slog.Logger, student *models.Student, create func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := create()
+		if err == nil || attempt == maxEnrollmentAttempts || !isEnrollmentConflict(err) {
+			return err
 		}
+		metrics.EnrollmentAllocationRetries.Inc()
+		logger.WarnContext(ctx, "matrícula gerada já está em uso, tentando novamente", "enrollment", student.Enrollment, "attempt", attempt)
 	}
+}
 
-	// Formata a nova matrícula (ex: 2025M0001)
-	student.Enrollment = fmt.Sprintf("%d%s%04d", currentYearForEnrollment, student.Shift, newSequence)
+// isEnrollmentConflict identifica a violação da restrição UNIQUE de students.enrollment.
+func isEnrollmentConflict(err error) bool {
+	return strings.Contains(err.Error(), "students_enrollment_key")
+}
+
+// validateNewStudent aplica as regras de criação de aluno, compartilhadas com a importação em lote:
+// nome obrigatório, turno configurado em policy (normalizado para maiúscula) e ano atual positivo.
+func validateNewStudent(policy EnrollmentPolicy, student *models.Student) error {
+	validation := &ValidationError{}
+	if strings.TrimSpace(student.Name) == "" {
+		validation.add("name", "nome do aluno é obrigatório")
+	}
+
+	student.Shift = strings.ToUpper(student.Shift)
+	if !policy.validShift(student.Shift) {
+		validation.add("shift", fmt.Sprintf("turno inválido: %s. Deve ser %s", student.Shift, policy.shiftCodes(true)))
+	}
 
 	// O `CurrentYear` do aluno pode vir do frontend ou ser padronizado.
-	// Se `student.CurrentYear` vier do frontend e for válido, use-o.
-	// Se for 0 (não fornecido pelo frontend ou inválido), padronize para 1.
+	// Se for 0 (não fornecido pelo frontend), padroniza para o primeiro ano.
 	if student.CurrentYear == 0 {
-		student.CurrentYear = 1 // Padrão para o primeiro ano se não especificado ou for 0
+		student.CurrentYear = 1
 	}
-	// Adicionar validação se o CurrentYear vindo do frontend for um valor futuro absurdo, etc.
-
-	return s.studentRepo.CreateStudent(student)
+	if student.CurrentYear < 0 {
+		validation.add("current_year", "ano atual deve ser um inteiro positivo")
+	}
+	return validation.errOrNil()
 }
 
-// GetStudentByID busca um aluno pelo ID.
-func (s *StudentService) GetStudentByID(id string) (*models.Student, error) {
-	student, err := s.studentRepo.GetStudentByID(id)
+// GetStudentByID busca um aluno pelo ID. includeDeleted permite buscar alunos excluídos (uso administrativo).
+func (s *StudentService) GetStudentByID(ctx context.Context, id string, includeDeleted bool) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.GetStudentByID")
+	defer span.End()
+	student, err := s.studentRepo.GetStudentByID(ctx, id, includeDeleted)
 	if err != nil {
-		if errors.Is(err, errors.New("aluno não encontrado")) { // Verifique se é o erro de 'não encontrado' do repositório
-			return nil, fmt.Errorf("aluno com ID %s não encontrado", id)
+		if errors.Is(err, ErrStudentNotFound) {
+			return nil, err
 		}
 		return nil, fmt.Errorf("erro ao buscar aluno por ID: %w", err)
 	}
@@ -88,27 +144,48 @@ func (s *StudentService) GetStudentByID(id string) (*models.Student, error) {
 // GetAllStudents busca todos os alunos, com opções de filtro.
 // year: ponteiro para int para permitir nil (sem filtro de ano)
 // shift: string para o turno (vazio significa sem filtro de turno)
-func (s *StudentService) GetAllStudents(year *int, shift string) ([]models.Student, error) {
+// includeDeleted: incluir alunos excluídos (uso administrativo)
+func (s *StudentService) GetAllStudents(ctx context.Context, year *int, shift string, includeDeleted bool) ([]models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.GetAllStudents")
+	defer span.End()
 	// Aqui você pode adicionar lógica de negócio adicional ou validações para os filtros, se necessário.
-	// Por exemplo, validar se o ano é um número razoável, ou se o turno é "M", "T", "N".
+	// Por exemplo, validar se o ano é um número razoável, ou se o turno é um dos configurados.
 	if shift != "" {
 		shift = strings.ToUpper(shift)
-		if shift != "M" && shift != "T" && shift != "N" {
+		if !s.enrollment.validShift(shift) {
 			// Se o frontend enviar um turno inválido, podemos retornar um erro aqui
-			return nil, fmt.Errorf("turno inválido no filtro: %s. Deve ser 'M', 'T' ou 'N'", shift)
+			return nil, fmt.Errorf("turno inválido no filtro: %s. Deve ser %s", shift, s.enrollment.shiftCodes(false))
 		}
 	}
 
 	// Delega a chamada para o repositório com os filtros
-	students, err := s.studentRepo.GetAllStudents(year, shift)
+	students, err := s.studentRepo.GetAllStudents(ctx, year, shift, includeDeleted)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todos os alunos com filtros: %w", err)
 	}
 	return students, nil
 }
 
+// StreamStudents percorre os alunos filtrados chamando fn para cada um (usado na exportação).
+func (s *StudentService) StreamStudents(ctx context.Context, year *int, shift string, includeDeleted bool, fn func(*models.Student) error) error {
+	ctx, span := tracing.Start(ctx, "StudentService.StreamStudents")
+	defer span.End()
+	if shift != "" {
+		shift = strings.ToUpper(shift)
+		if !s.enrollment.validShift(shift) {
+			return fmt.Errorf("turno inválido no filtro: %s. Deve ser %s", shift, s.enrollment.shiftCodes(false))
+		}
+	}
+	if err := s.studentRepo.StreamStudents(ctx, year, shift, includeDeleted, fn); err != nil {
+		return fmt.Errorf("erro ao exportar alunos: %w", err)
+	}
+	return nil
+}
+
 // UpdateStudent atualiza um aluno existente.
-func (s *StudentService) UpdateStudent(student *models.Student) error {
+func (s *StudentService) UpdateStudent(ctx context.Context, student *models.Student) error {
+	ctx, span := tracing.Start(ctx, "StudentService.UpdateStudent")
+	defer span.End()
 	if student.ID == "" {
 		return errors.New("ID do aluno é obrigatório para atualização")
 	}
@@ -118,18 +195,22 @@ func (s *StudentService) UpdateStudent(student *models.Student) error {
 
 	// Normalizar o turno para maiúsculas antes de usar
 	student.Shift = strings.ToUpper(student.Shift)
-	if student.Shift != "M" && student.Shift != "T" && student.Shift != "N" {
-		return fmt.Errorf("turno inválido para atualização: %s. Deve ser 'M' (Manhã), 'T' (Tarde) ou 'N' (Noite)", student.Shift)
+	if !s.enrollment.validShift(student.Shift) {
+		return fmt.Errorf("turno inválido para atualização: %s. Deve ser %s", student.Shift, s.enrollment.shiftCodes(true))
 	}
 
-	existingStudent, err := s.studentRepo.GetStudentByID(student.ID)
+	existingStudent, err := s.studentRepo.GetStudentByID(ctx, student.ID, false)
 	if err != nil {
-		if errors.Is(err, errors.New("aluno não encontrado")) { // Verifique se é o erro de 'não encontrado' do repositório
-			return fmt.Errorf("aluno com ID %s não encontrado para atualização", student.ID)
+		if errors.Is(err, ErrStudentNotFound) {
+			return fmt.Errorf("%w para atualização", ErrStudentNotFound)
 		}
 		return fmt.Errorf("erro ao buscar aluno existente para atualização: %w", err)
 	}
 	// `existingStudent` já não será nil aqui se o erro for tratado acima.
+	if existingStudent.Version != student.Version {
+		return repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
+	before := *existingStudent // Estado anterior para a auditoria
 
 	// Copia os campos atualizáveis do 'student' (DTO de entrada) para 'existingStudent'
 	existingStudent.Name = student.Name
@@ -139,26 +220,170 @@ func (s *StudentService) UpdateStudent(student *models.Student) error {
 	// A matrícula (Enrollment) é gerada na criação e não deve ser alterada aqui.
 	// Ela já é parte do 'existingStudent' buscado do DB.
 
-	return s.studentRepo.UpdateStudent(existingStudent)
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.studentRepo.WithTx(tx).UpdateStudent(ctx, existingStudent); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityStudent, existingStudent.ID, AuditActionUpdate, before, existingStudent)
+	})
+	if err != nil {
+		return err
+	}
+	*student = *existingStudent // Devolve o estado persistido (com matrícula e nova versão)
+	return nil
 }
 
-// DeleteStudent deleta um aluno pelo ID.
-func (s *StudentService) DeleteStudent(id string) error {
-	err := s.studentRepo.DeleteStudent(id)
+// PatchStudent aplica uma atualização parcial (JSON Merge Patch) a um aluno.
+// version é a versão conhecida pelo cliente (If-Match). Apenas os campos enviados e de fato
+// alterados são gravados; sem alterações, o aluno é retornado sem nova versão.
+func (s *StudentService) PatchStudent(ctx context.Context, id string, version int, patch *models.StudentPatch) (*models.Student, error) {
+	ctx, span := tracing.Start(ctx, "StudentService.PatchStudent")
+	defer span.End()
+	validation := &ValidationError{}
+	changes := map[string]interface{}{}
+	if patch.Name != nil {
+		if strings.TrimSpace(*patch.Name) == "" {
+			validation.add("name", "não pode ser vazio")
+		}
+		changes["name"] = *patch.Name
+	}
+	if patch.CurrentYear != nil {
+		if *patch.CurrentYear < 1 {
+			validation.add("current_year", "deve ser um inteiro positivo")
+		}
+		changes["current_year"] = *patch.CurrentYear
+	}
+	if patch.Shift != nil {
+		shift := strings.ToUpper(*patch.Shift)
+		if !s.enrollment.validShift(shift) {
+			validation.add("shift", "deve ser "+s.enrollment.shiftCodes(true))
+		}
+		changes["shift"] = shift
+	}
+	if err := validation.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	existingStudent, err := s.studentRepo.GetStudentByID(ctx, id, false)
 	if err != nil {
-		if errors.Is(err, errors.New("aluno não encontrado para exclusão")) { // Verifique o erro específico do repositório
-			return fmt.Errorf("aluno com ID %s não encontrado para exclusão", id)
+		if errors.Is(err, ErrStudentNotFound) {
+			return nil, fmt.Errorf("%w para atualização", ErrStudentNotFound)
 		}
-		return fmt.Errorf("erro ao deletar aluno: %w", err)
+		return nil, fmt.Errorf("erro ao buscar aluno existente para atualização: %w", err)
 	}
-	return nil
+	if existingStudent.Version != version {
+		return nil, repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
+
+	// Descarta campos enviados com o mesmo valor atual: só as colunas alteradas são gravadas.
+	if name, ok := changes["name"]; ok && name == existingStudent.Name {
+		delete(changes, "name")
+	}
+	if year, ok := changes["current_year"]; ok && year == existingStudent.CurrentYear {
+		delete(changes, "current_year")
+	}
+	if shift, ok := changes["shift"]; ok && shift == existingStudent.Shift {
+		delete(changes, "shift")
+	}
+
+	// Curso e grade: trocar de curso sem informar a grade passa para a versão mais recente do novo curso.
+	target := models.Student{ProgramID: existingStudent.ProgramID, CurriculumID: existingStudent.CurriculumID}
+	if patch.ProgramID != nil && *patch.ProgramID != existingStudent.ProgramID {
+		target.ProgramID = *patch.ProgramID
+		target.CurriculumID = ""
+	}
+	if patch.CurriculumID != nil {
+		target.CurriculumID = *patch.CurriculumID
+	}
+	if target.ProgramID != existingStudent.ProgramID || target.CurriculumID != existingStudent.CurriculumID {
+		if _, err := resolveStudentProgram(ctx, s.programRepo, &target); err != nil {
+			return nil, err
+		}
+		if target.ProgramID != existingStudent.ProgramID {
+			changes["program_id"] = nullableID(target.ProgramID)
+		}
+		if target.CurriculumID != existingStudent.CurriculumID {
+			changes["curriculum_id"] = nullableID(target.CurriculumID)
+		}
+	}
+	if len(changes) == 0 {
+		return existingStudent, nil
+	}
+
+	before := *existingStudent // Estado anterior para a auditoria
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		newVersion, err := s.studentRepo.WithTx(tx).PatchStudent(ctx, id, version, changes)
+		if err != nil {
+			return err
+		}
+		if name, ok := changes["name"].(string); ok {
+			existingStudent.Name = name
+		}
+		if year, ok := changes["current_year"].(int); ok {
+			existingStudent.CurrentYear = year
+		}
+		if shift, ok := changes["shift"].(string); ok {
+			existingStudent.Shift = shift
+		}
+		existingStudent.ProgramID = target.ProgramID
+		existingStudent.CurriculumID = target.CurriculumID
+		existingStudent.Version = newVersion
+		return s.audit.Record(ctx, tx, AuditEntityStudent, id, AuditActionUpdate, before, existingStudent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existingStudent, nil
+}
+
+// nullableID converte um ID vazio em NULL para colunas de chave estrangeira opcionais.
+func nullableID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// DeleteStudent deleta um aluno pelo ID. version é a versão conhecida pelo cliente (If-Match).
+func (s *StudentService) DeleteStudent(ctx context.Context, id string, version int) error {
+	ctx, span := tracing.Start(ctx, "StudentService.DeleteStudent")
+	defer span.End()
+	// Estado anterior para a auditoria; se o aluno não existir, DeleteStudent abaixo reporta o erro.
+	before, _ := s.studentRepo.GetStudentByID(ctx, id, false)
+
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		err := s.studentRepo.WithTx(tx).DeleteStudent(ctx, id, version)
+		if err != nil {
+			if errors.Is(err, ErrStudentNotFound) {
+				return err
+			}
+			return fmt.Errorf("erro ao deletar aluno: %w", err)
+		}
+		return s.audit.Record(ctx, tx, AuditEntityStudent, id, AuditActionDelete, before, nil)
+	})
+}
+
+// RestoreStudent desfaz a exclusão de um aluno.
+func (s *StudentService) RestoreStudent(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.RestoreStudent")
+	defer span.End()
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		studentRepo := s.studentRepo.WithTx(tx)
+		if err := studentRepo.RestoreStudent(ctx, id); err != nil {
+			return err
+		}
+		after, _ := studentRepo.GetStudentByID(ctx, id, false)
+		return s.audit.Record(ctx, tx, AuditEntityStudent, id, AuditActionRestore, nil, after)
+	})
 }
 
 // AddSubjectToStudent associa uma matéria a um aluno.
-func (s *StudentService) AddSubjectToStudent(studentID, subjectID string) error {
-	student, err := s.studentRepo.GetStudentByID(studentID)
+func (s *StudentService) AddSubjectToStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.AddSubjectToStudent")
+	defer span.End()
+	student, err := s.studentRepo.GetStudentByID(ctx, studentID, false)
 	if err != nil {
-		if err.Error() == "aluno não encontrado" {
+		if errors.Is(err, ErrStudentNotFound) {
 			return fmt.Errorf("aluno com ID %s não encontrado para associação", studentID)
 		}
 		return fmt.Errorf("erro ao buscar aluno para associação: %w", err)
@@ -166,9 +391,9 @@ func (s *StudentService) AddSubjectToStudent(studentID, subjectID string) error
 	if student == nil {
 		return fmt.Errorf("aluno com ID %s não encontrado para associação", studentID)
 	}
-	subject, err := s.subjectRepo.GetSubjectByID(subjectID)
+	subject, err := s.subjectRepo.GetSubjectByID(ctx, subjectID, false)
 	if err != nil {
-		if err.Error() == "matéria não encontrada" {
+		if errors.Is(err, ErrSubjectNotFound) {
 			return fmt.Errorf("matéria com ID %s não encontrada para associação", subjectID)
 		}
 		return fmt.Errorf("erro ao buscar matéria para associação: %w", err)
@@ -177,15 +402,22 @@ func (s *StudentService) AddSubjectToStudent(studentID, subjectID string) error
 		return fmt.Errorf("matéria com ID %s não encontrada para associação", subjectID)
 	}
 
-	return s.studentRepo.AddSubjectToStudent(studentID, subjectID)
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.studentRepo.WithTx(tx).AddSubjectToStudent(ctx, studentID, subjectID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityStudent, studentID, AuditActionAddSubject, nil, map[string]string{"subject_id": subjectID})
+	})
 }
 
 // RemoveSubjectFromStudent desassocia uma matéria de um aluno.
-func (s *StudentService) RemoveSubjectFromStudent(studentID, subjectID string) error {
+func (s *StudentService) RemoveSubjectFromStudent(ctx context.Context, studentID, subjectID string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.RemoveSubjectFromStudent")
+	defer span.End()
 	// Verifica se o aluno existe
-	student, err := s.studentRepo.GetStudentByID(studentID)
+	student, err := s.studentRepo.GetStudentByID(ctx, studentID, false)
 	if err != nil {
-		if errors.Is(err, errors.New("aluno não encontrado")) {
+		if errors.Is(err, ErrStudentNotFound) {
 			return fmt.Errorf("aluno com ID %s não encontrado para desassociação", studentID)
 		}
 		return fmt.Errorf("erro ao buscar aluno para desassociação: %w", err)
@@ -195,7 +427,7 @@ func (s *StudentService) RemoveSubjectFromStudent(studentID, subjectID string) e
 	}
 
 	// Verifica se a matéria existe
-	subject, err := s.subjectRepo.GetSubjectByID(subjectID)
+	subject, err := s.subjectRepo.GetSubjectByID(ctx, subjectID, false)
 	if err != nil {
 		return fmt.Errorf("erro ao buscar matéria para desassociação: %w", err)
 	}
@@ -204,12 +436,45 @@ func (s *StudentService) RemoveSubjectFromStudent(studentID, subjectID string) e
 	}
 
 	// Tenta remover a associação
-	err = s.studentRepo.RemoveSubjectFromStudent(studentID, subjectID)
-	if err != nil {
-		if err.Error() == "associação não encontrada para desassociação" {
-			return fmt.Errorf("associação entre aluno %s e matéria %s não encontrada para desassociação", studentID, subjectID)
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		err := s.studentRepo.WithTx(tx).RemoveSubjectFromStudent(ctx, studentID, subjectID)
+		if err != nil {
+			if err.Error() == "associação não encontrada para desassociação" {
+				return fmt.Errorf("associação entre aluno %s e matéria %s não encontrada para desassociação", studentID, subjectID)
+			}
+			return fmt.Errorf("erro ao remover associação entre aluno e matéria: %w", err)
 		}
-		return fmt.Errorf("erro ao remover associação entre aluno e matéria: %w", err)
+		return s.audit.Record(ctx, tx, AuditEntityStudent, studentID, AuditActionRemoveSubject, map[string]string{"subject_id": subjectID}, nil)
+	})
+}
+
+// SetSubjectStatus altera a situação do aluno em uma matéria associada: "enrolled" (cursando),
+// "passed" (aprovado) ou "failed" (reprovado).
+func (s *StudentService) SetSubjectStatus(ctx context.Context, studentID, subjectID, status string) error {
+	ctx, span := tracing.Start(ctx, "StudentService.SetSubjectStatus")
+	defer span.End()
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != models.SubjectStatusEnrolled && status != models.SubjectStatusPassed && status != models.SubjectStatusFailed {
+		return &ValidationError{Fields: map[string]string{"status": "deve ser 'enrolled', 'passed' ou 'failed'"}}
 	}
-	return nil
+
+	statuses, err := s.studentRepo.GetSubjectStatuses(ctx, studentID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar situação do aluno nas matérias: %w", err)
+	}
+	previous, ok := statuses[subjectID]
+	if !ok {
+		return fmt.Errorf("associação entre aluno %s e matéria %s não encontrada", studentID, subjectID)
+	}
+	if previous == status {
+		return nil
+	}
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.studentRepo.WithTx(tx).SetSubjectStatus(ctx, studentID, subjectID, status); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityStudent, studentID, AuditActionSetSubjectStatus,
+			map[string]string{"subject_id": subjectID, "status": previous},
+			map[string]string{"subject_id": subjectID, "status": status})
+	})
 }
@@ -4,25 +4,33 @@ package services
 import (
 	"college-app-v1/models"
 	"college-app-v1/repositories" // Para verificar sql.ErrNoRows
-	"errors"                      // Para criar erros personalizados
-	"fmt"                         // Para formatar mensagens de erro
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
+	"errors" // Para criar erros personalizados
+	"fmt"    // Para formatar mensagens de erro
+	"strings"
 )
 
 // SubjectService define a interface para as operações de serviço de matérias.
 type SubjectService struct {
-	repo *repositories.SubjectRepository
+	transactor *repositories.Transactor
+	repo       *repositories.SubjectRepository
+	audit      *AuditService
 }
 
 // NewSubjectService cria uma nova instância de SubjectService.
-func NewSubjectService(repo *repositories.SubjectRepository) *SubjectService {
-	return &SubjectService{repo: repo}
+func NewSubjectService(transactor *repositories.Transactor, repo *repositories.SubjectRepository, audit *AuditService) *SubjectService {
+	return &SubjectService{transactor: transactor, repo: repo, audit: audit}
 }
 
 // CreateSubject adiciona uma nova matéria após validações.
-func (s *SubjectService) CreateSubject(subject *models.Subject) error {
+func (s *SubjectService) CreateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.CreateSubject")
+	defer span.End()
 	// --- MUDANÇA AQUI: Remover a validação de subject.ID para criação ---
-	if subject.Name == "" || subject.Year == 0 { // ID não é mais verificado aqui
-		return errors.New("nome e ano da matéria são obrigatórios") // Mensagem de erro atualizada
+	if err := validateNewSubject(subject); err != nil { // ID não é mais verificado aqui
+		return err
 	}
 
 	// Exemplo de validação: Matéria com o mesmo ID já existe
@@ -31,7 +39,7 @@ func (s *SubjectService) CreateSubject(subject *models.Subject) error {
 	// Se o ID é gerado pelo repositório, esta verificação pode ser removida ou adaptada.
 	// Por enquanto, vou comentá-la, pois o repositório gerará um UUID e garantirá unicidade.
 	/*
-		existingSubject, err := s.repo.GetSubjectByID(subject.ID) // subject.ID estaria vazio aqui
+		existingSubject, err := s.repo.GetSubjectByID(ctx, subject.ID, false) // subject.ID estaria vazio aqui
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("erro ao verificar matéria existente: %w", err)
 		}
@@ -41,37 +49,75 @@ func (s *SubjectService) CreateSubject(subject *models.Subject) error {
 	*/
 
 	// A geração do ID DEVE ocorrer no repositório antes de criar no DB
-	return s.repo.CreateSubject(subject)
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).CreateSubject(ctx, subject); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntitySubject, subject.ID, AuditActionCreate, nil, subject)
+	})
 }
 
-// GetSubjectByID busca uma matéria pelo ID.
-func (s *SubjectService) GetSubjectByID(id string) (*models.Subject, error) {
-	subject, err := s.repo.GetSubjectByID(id)
+// validateNewSubject aplica as regras de criação de matéria, compartilhadas com a importação em lote:
+// nome e ano obrigatórios, ano positivo e créditos não negativos.
+func validateNewSubject(subject *models.Subject) error {
+	validation := &ValidationError{}
+	if strings.TrimSpace(subject.Name) == "" {
+		validation.add("name", "nome da matéria é obrigatório")
+	}
+	if subject.Year == 0 {
+		validation.add("year", "ano da matéria é obrigatório")
+	} else if subject.Year < 0 {
+		validation.add("year", "ano da matéria deve ser um inteiro positivo")
+	}
+	if subject.Credits < 0 {
+		validation.add("credits", "créditos não podem ser negativos")
+	}
+	return validation.errOrNil()
+}
+
+// GetSubjectByID busca uma matéria pelo ID. includeDeleted permite buscar matérias excluídas (uso administrativo).
+func (s *SubjectService) GetSubjectByID(ctx context.Context, id string, includeDeleted bool) (*models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.GetSubjectByID")
+	defer span.End()
+	subject, err := s.repo.GetSubjectByID(ctx, id, includeDeleted)
 	if err != nil {
-		// Encapsular erros do repositório para a camada de serviço
-		if errors.Is(err, errors.New("matéria não encontrada")) { // Supondo que o repositório retorna este erro
-			return nil, errors.New("matéria não encontrada")
+		if errors.Is(err, ErrSubjectNotFound) {
+			return nil, err
 		}
 		return nil, fmt.Errorf("erro ao buscar matéria: %w", err)
 	}
 	// Se o repositório retornar (nil, nil) para não encontrado, esta verificação pega
 	if subject == nil {
-		return nil, errors.New("matéria não encontrada")
+		return nil, ErrSubjectNotFound
 	}
 	return subject, nil
 }
 
-// GetAllSubjects busca todas as matérias.
-func (s *SubjectService) GetAllSubjects() ([]models.Subject, error) {
-	subjects, err := s.repo.GetAllSubjects()
+// GetAllSubjects busca todas as matérias. includeDeleted inclui as excluídas (uso administrativo).
+func (s *SubjectService) GetAllSubjects(ctx context.Context, includeDeleted bool) ([]models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.GetAllSubjects")
+	defer span.End()
+	subjects, err := s.repo.GetAllSubjects(ctx, includeDeleted)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todas as matérias: %w", err)
 	}
 	return subjects, nil
 }
 
+// StreamSubjects percorre as matérias chamando fn para cada uma (usado na exportação).
+func (s *SubjectService) StreamSubjects(ctx context.Context, includeDeleted bool, fn func(*models.Subject) error) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.StreamSubjects")
+	defer span.End()
+	if err := s.repo.StreamSubjects(ctx, includeDeleted, fn); err != nil {
+		return fmt.Errorf("erro ao exportar matérias: %w", err)
+	}
+	return nil
+}
+
 // UpdateSubject atualiza uma matéria existente após validações.
-func (s *SubjectService) UpdateSubject(subject *models.Subject) error {
+func (s *SubjectService) UpdateSubject(ctx context.Context, subject *models.Subject) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.UpdateSubject")
+	defer span.End()
 	if subject.ID == "" {
 		return errors.New("ID da matéria é obrigatório para atualização")
 	}
@@ -81,12 +127,18 @@ func (s *SubjectService) UpdateSubject(subject *models.Subject) error {
 	}
 
 	// Validação: a matéria deve existir para ser atualizada
-	existingSubject, err := s.repo.GetSubjectByID(subject.ID)
+	existingSubject, err := s.repo.GetSubjectByID(ctx, subject.ID, false)
 	if err != nil {
+		if errors.Is(err, ErrSubjectNotFound) {
+			return fmt.Errorf("%w para atualização", ErrSubjectNotFound)
+		}
 		return fmt.Errorf("erro ao verificar matéria para atualização: %w", err)
 	}
 	if existingSubject == nil {
-		return errors.New("matéria não encontrada para atualização")
+		return fmt.Errorf("%w para atualização", ErrSubjectNotFound)
+	}
+	if existingSubject.Version != subject.Version {
+		return repositories.ErrVersionConflict // Alterada por outra requisição desde a leitura do cliente
 	}
 
 	// Copiar os campos atualizáveis (se necessário, para não sobrescrever o que não deve)
@@ -94,28 +146,149 @@ func (s *SubjectService) UpdateSubject(subject *models.Subject) error {
 	// existingSubject.Year = subject.Year
 	// existingSubject.Credits = subject.Credits // Se créditos forem atualizáveis
 
-	return s.repo.UpdateSubject(subject) // Passe o subject recebido que já tem o ID
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).UpdateSubject(ctx, subject); err != nil { // Passe o subject recebido que já tem o ID
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntitySubject, subject.ID, AuditActionUpdate, existingSubject, subject)
+	})
 }
 
-// DeleteSubject deleta uma matéria pelo ID.
-func (s *SubjectService) DeleteSubject(id string) error {
+// PatchSubject aplica uma atualização parcial (JSON Merge Patch) a uma matéria.
+// version é a versão conhecida pelo cliente (If-Match). Apenas os campos enviados e de fato
+// alterados são gravados; sem alterações, a matéria é retornada sem nova versão.
+func (s *SubjectService) PatchSubject(ctx context.Context, id string, version int, patch *models.SubjectPatch) (*models.Subject, error) {
+	ctx, span := tracing.Start(ctx, "SubjectService.PatchSubject")
+	defer span.End()
+	validation := &ValidationError{}
+	changes := map[string]interface{}{}
+	if patch.Name != nil {
+		if strings.TrimSpace(*patch.Name) == "" {
+			validation.add("name", "não pode ser vazio")
+		}
+		changes["name"] = *patch.Name
+	}
+	if patch.Year != nil {
+		if *patch.Year < 1 {
+			validation.add("year", "deve ser um inteiro positivo")
+		}
+		changes["year"] = *patch.Year
+	}
+	if patch.Credits != nil {
+		if *patch.Credits < 0 {
+			validation.add("credits", "não pode ser negativo")
+		}
+		changes["credits"] = *patch.Credits
+	}
+	if patch.Mandatory != nil {
+		changes["mandatory"] = *patch.Mandatory
+	}
+	if err := validation.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	existingSubject, err := s.repo.GetSubjectByID(ctx, id, false)
+	if err != nil {
+		if errors.Is(err, ErrSubjectNotFound) {
+			return nil, fmt.Errorf("%w para atualização", ErrSubjectNotFound)
+		}
+		return nil, fmt.Errorf("erro ao verificar matéria para atualização: %w", err)
+	}
+	if existingSubject == nil {
+		return nil, fmt.Errorf("%w para atualização", ErrSubjectNotFound)
+	}
+	if existingSubject.Version != version {
+		return nil, repositories.ErrVersionConflict // Alterada por outra requisição desde a leitura do cliente
+	}
+
+	// Descarta campos enviados com o mesmo valor atual: só as colunas alteradas são gravadas.
+	if name, ok := changes["name"]; ok && name == existingSubject.Name {
+		delete(changes, "name")
+	}
+	if year, ok := changes["year"]; ok && year == existingSubject.Year {
+		delete(changes, "year")
+	}
+	if credits, ok := changes["credits"]; ok && credits == existingSubject.Credits {
+		delete(changes, "credits")
+	}
+	if mandatory, ok := changes["mandatory"]; ok && mandatory == existingSubject.Mandatory {
+		delete(changes, "mandatory")
+	}
+	if len(changes) == 0 {
+		return existingSubject, nil
+	}
+
+	before := *existingSubject // Estado anterior para a auditoria
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		newVersion, err := s.repo.WithTx(tx).PatchSubject(ctx, id, version, changes)
+		if err != nil {
+			return err
+		}
+		applySubjectChanges(existingSubject, changes)
+		existingSubject.Version = newVersion
+		return s.audit.Record(ctx, tx, AuditEntitySubject, id, AuditActionUpdate, before, existingSubject)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existingSubject, nil
+}
+
+// applySubjectChanges copia para subject os campos alterados por PatchSubject.
+func applySubjectChanges(subject *models.Subject, changes map[string]interface{}) {
+	if name, ok := changes["name"].(string); ok {
+		subject.Name = name
+	}
+	if year, ok := changes["year"].(int); ok {
+		subject.Year = year
+	}
+	if credits, ok := changes["credits"].(int); ok {
+		subject.Credits = credits
+	}
+	if mandatory, ok := changes["mandatory"].(bool); ok {
+		subject.Mandatory = mandatory
+	}
+}
+
+// DeleteSubject deleta uma matéria pelo ID. version é a versão conhecida pelo cliente (If-Match).
+func (s *SubjectService) DeleteSubject(ctx context.Context, id string, version int) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.DeleteSubject")
+	defer span.End()
 	if id == "" {
 		return errors.New("ID da matéria é obrigatório para exclusão")
 	}
 	// Validação: a matéria deve existir para ser deletada
-	existingSubject, err := s.repo.GetSubjectByID(id)
+	existingSubject, err := s.repo.GetSubjectByID(ctx, id, false)
 	if err != nil {
-		// Se o erro do repositório for "matéria não encontrada", encapsule.
-		if errors.Is(err, errors.New("matéria não encontrada")) {
-			return errors.New("matéria não encontrada para exclusão")
+		if errors.Is(err, ErrSubjectNotFound) {
+			return fmt.Errorf("%w para exclusão", ErrSubjectNotFound)
 		}
 		return fmt.Errorf("erro ao verificar matéria para exclusão: %w", err)
 	}
 	// Se existingSubject for nil (significa que GetSubjectByID retornou nil, nil),
 	// então a matéria não foi encontrada.
 	if existingSubject == nil {
-		return errors.New("matéria não encontrada para exclusão")
+		return fmt.Errorf("%w para exclusão", ErrSubjectNotFound)
 	}
 
-	return s.repo.DeleteSubject(id)
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).DeleteSubject(ctx, id, version); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntitySubject, id, AuditActionDelete, existingSubject, nil)
+	})
+}
+
+// RestoreSubject desfaz a exclusão de uma matéria.
+func (s *SubjectService) RestoreSubject(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "SubjectService.RestoreSubject")
+	defer span.End()
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.RestoreSubject(ctx, id); err != nil {
+			return err
+		}
+		after, _ := repo.GetSubjectByID(ctx, id, false)
+		return s.audit.Record(ctx, tx, AuditEntitySubject, id, AuditActionRestore, nil, after)
+	})
 }
@@ -0,0 +1,35 @@
+// services/subject_service_test.go
+package services
+
+import (
+	"college-app-v1/models"
+	"testing"
+)
+
+func TestApplySubjectChanges(t *testing.T) {
+	original := models.Subject{ID: "s1", Name: "Cálculo I", Year: 1, Credits: 4, Mandatory: true, Version: 3}
+	tests := []struct {
+		name    string
+		changes map[string]interface{}
+		want    models.Subject
+	}{
+		{name: "sem alterações", changes: map[string]interface{}{}, want: original},
+		{name: "nome", changes: map[string]interface{}{"name": "Cálculo II"},
+			want: models.Subject{ID: "s1", Name: "Cálculo II", Year: 1, Credits: 4, Mandatory: true, Version: 3}},
+		{name: "todos os campos", changes: map[string]interface{}{"name": "Álgebra", "year": 2, "credits": 6, "mandatory": false},
+			want: models.Subject{ID: "s1", Name: "Álgebra", Year: 2, Credits: 6, Mandatory: false, Version: 3}},
+		{name: "zero e false são valores enviados", changes: map[string]interface{}{"credits": 0, "mandatory": false},
+			want: models.Subject{ID: "s1", Name: "Cálculo I", Year: 1, Credits: 0, Mandatory: false, Version: 3}},
+		{name: "colunas desconhecidas são ignoradas", changes: map[string]interface{}{"version": 9, "id": "s2"}, want: original},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject := original
+			applySubjectChanges(&subject, tt.changes)
+			if subject.ID != tt.want.ID || subject.Name != tt.want.Name || subject.Year != tt.want.Year ||
+				subject.Credits != tt.want.Credits || subject.Mandatory != tt.want.Mandatory || subject.Version != tt.want.Version {
+				t.Errorf("matéria = %+v, esperava %+v", subject, tt.want)
+			}
+		})
+	}
+}
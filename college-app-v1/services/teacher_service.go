@@ -5,41 +5,98 @@ package services
 import (
 	"college-app-v1/models"       // Certifique-se de que este caminho está correto
 	"college-app-v1/repositories" // Certifique-se de que este caminho está correto
+	"college-app-v1/tracing"
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log" // <-- ADICIONADO: Importar log para depuração
-	// Para usar strings.ToUpper (se necessário para normalização de filtros)
+	"log/slog"
+	"net/mail"
+	"strings" // Para usar strings.ToUpper (se necessário para normalização de filtros)
 )
 
 // TeacherService define a interface para operações de negócio de professor.
 // Assinatura atualizada para GetAllTeachers.
 type TeacherService struct {
-	teacherRepo *repositories.TeacherRepository
-	subjectRepo *repositories.SubjectRepository // Se o serviço precisar interagir com matérias
+	transactor     *repositories.Transactor
+	teacherRepo    *repositories.TeacherRepository
+	subjectRepo    *repositories.SubjectRepository // Se o serviço precisar interagir com matérias
+	departmentRepo *repositories.DepartmentRepository
+	workload       WorkloadPolicy
+	audit          *AuditService
+	logger         *slog.Logger
 }
 
 // NewTeacherService cria uma nova instância de TeacherService.
-func NewTeacherService(tr *repositories.TeacherRepository, sr *repositories.SubjectRepository) *TeacherService {
-	return &TeacherService{teacherRepo: tr, subjectRepo: sr}
+func NewTeacherService(transactor *repositories.Transactor, tr *repositories.TeacherRepository, sr *repositories.SubjectRepository, dr *repositories.DepartmentRepository, workload WorkloadPolicy, audit *AuditService, logger *slog.Logger) *TeacherService {
+	return &TeacherService{transactor: transactor, teacherRepo: tr, subjectRepo: sr, departmentRepo: dr, workload: workload, audit: audit, logger: logger}
 }
 
 // CreateTeacher implementa a criação de um novo professor.
-func (s *TeacherService) CreateTeacher(teacher *models.Teacher) error {
+func (s *TeacherService) CreateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.CreateTeacher")
+	defer span.End()
 	// Validações de negócio para criação (Name, Department, Email)
-	if teacher.Name == "" || teacher.Department == "" || teacher.Email == "" {
-		return errors.New("nome, departamento e email do professor são obrigatórios")
+	if err := validateNewTeacher(teacher); err != nil {
+		return err
 	}
-	// Você pode adicionar validação de formato de email aqui.
+	// O departamento deve existir (informado por ID, código ou nome)
+	if err := resolveTeacherDepartment(ctx, s.departmentRepo, teacher); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.teacherRepo.WithTx(tx).CreateTeacher(ctx, teacher); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityTeacher, teacher.ID, AuditActionCreate, nil, teacher)
+	})
+}
+
+// validateNewTeacher aplica as regras de criação de professor, compartilhadas com a importação em lote:
+// nome e email obrigatórios, com email em formato válido, e regime de contratação conhecido
+// (padrão: integral). O departamento é validado à parte por resolveTeacherDepartment, que consulta o banco.
+func validateNewTeacher(teacher *models.Teacher) error {
+	validation := &ValidationError{}
+	if strings.TrimSpace(teacher.Name) == "" {
+		validation.add("name", "nome do professor é obrigatório")
+	}
+	if teacher.ContractType == "" {
+		teacher.ContractType = models.ContractTypeIntegral
+	}
+	if !validContractType(teacher) {
+		validation.add("contract_type", contractTypeMessage)
+	}
+	if teacher.Email == "" {
+		validation.add("email", "email do professor é obrigatório")
+	} else if _, err := mail.ParseAddress(teacher.Email); err != nil {
+		validation.add("email", "endereço de email inválido")
+	}
+	return validation.errOrNil()
+}
+
+// contractTypeMessage é o erro de validação de um regime de contratação desconhecido.
+const contractTypeMessage = "deve ser 'integral', 'parcial' ou 'horista'"
 
-	return s.teacherRepo.CreateTeacher(teacher)
+// validContractType normaliza teacher.ContractType para minúsculas e informa se é um regime conhecido.
+func validContractType(teacher *models.Teacher) bool {
+	teacher.ContractType = strings.ToLower(strings.TrimSpace(teacher.ContractType))
+	switch teacher.ContractType {
+	case models.ContractTypeIntegral, models.ContractTypeParcial, models.ContractTypeHorista:
+		return true
+	}
+	return false
 }
 
 // GetTeacherByID implementa a busca de professor por ID.
-func (s *TeacherService) GetTeacherByID(id string) (*models.Teacher, error) {
-	teacher, err := s.teacherRepo.GetTeacherByID(id)
+// includeDeleted permite buscar professores excluídos (uso administrativo).
+func (s *TeacherService) GetTeacherByID(ctx context.Context, id string, includeDeleted bool) (*models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.GetTeacherByID")
+	defer span.End()
+	teacher, err := s.teacherRepo.GetTeacherByID(ctx, id, includeDeleted)
 	if err != nil {
-		if errors.Is(err, errors.New("professor não encontrado")) {
-			return nil, fmt.Errorf("professor com ID %s não encontrado", id)
+		if errors.Is(err, ErrTeacherNotFound) {
+			return nil, err
 		}
 		return nil, fmt.Errorf("erro ao buscar professor por ID: %w", err)
 	}
@@ -47,12 +104,10 @@ func (s *TeacherService) GetTeacherByID(id string) (*models.Teacher, error) {
 }
 
 // GetAllTeachers implementa a busca de todos os professores com filtros.
-// Adicionado nameFilter e emailFilter.
-func (s *TeacherService) GetAllTeachers(nameFilter, departmentFilter, emailFilter string) ([]models.Teacher, error) {
-	// --- ADICIONADO: LOG TEMPORÁRIO AQUI ---
-	log.Printf("Service: Chamando GetAllTeachers com filtros -> Nome: '%s', Departamento: '%s', Email: '%s'", nameFilter, departmentFilter, emailFilter)
-	// --- FIM DO LOG TEMPORÁRIO ---
-
+// Adicionado nameFilter e emailFilter. includeDeleted inclui professores excluídos (uso administrativo).
+func (s *TeacherService) GetAllTeachers(ctx context.Context, nameFilter, departmentFilter, emailFilter string, includeDeleted bool) ([]models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.GetAllTeachers")
+	defer span.End()
 	// Exemplo de normalização do filtro (opcional, mas boa prática)
 	// if departmentFilter != "" {
 	//     departmentFilter = strings.ToUpper(departmentFilter)
@@ -61,79 +116,334 @@ func (s *TeacherService) GetAllTeachers(nameFilter, departmentFilter, emailFilte
 	//     nameFilter = strings.ToLower(nameFilter) // Para buscas case-insensitive no repositório
 	// }
 
-	teachers, err := s.teacherRepo.GetAllTeachers(nameFilter, departmentFilter, emailFilter)
+	teachers, err := s.teacherRepo.GetAllTeachers(ctx, nameFilter, departmentFilter, emailFilter, includeDeleted)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todos os professores com filtros: %w", err)
 	}
 	return teachers, nil
 }
 
+// StreamTeachers percorre os professores filtrados chamando fn para cada um (usado na exportação).
+func (s *TeacherService) StreamTeachers(ctx context.Context, nameFilter, departmentFilter, emailFilter string, includeDeleted bool, fn func(*models.Teacher) error) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.StreamTeachers")
+	defer span.End()
+	if err := s.teacherRepo.StreamTeachers(ctx, nameFilter, departmentFilter, emailFilter, includeDeleted, fn); err != nil {
+		return fmt.Errorf("erro ao exportar professores: %w", err)
+	}
+	return nil
+}
+
 // UpdateTeacher implementa a atualização de um professor.
-func (s *TeacherService) UpdateTeacher(teacher *models.Teacher) error {
+func (s *TeacherService) UpdateTeacher(ctx context.Context, teacher *models.Teacher) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.UpdateTeacher")
+	defer span.End()
 	if teacher.ID == "" {
 		return errors.New("ID do professor é obrigatório para atualização")
 	}
-	if teacher.Name == "" || teacher.Department == "" || teacher.Email == "" { // Validação completa
-		return errors.New("nome, departamento e email do professor são obrigatórios para atualização")
+	if teacher.Name == "" || teacher.Email == "" { // Validação completa
+		return errors.New("nome e email do professor são obrigatórios para atualização")
+	}
+	if teacher.ContractType != "" && !validContractType(teacher) {
+		return &ValidationError{Fields: map[string]string{"contract_type": contractTypeMessage}}
+	}
+	if err := resolveTeacherDepartment(ctx, s.departmentRepo, teacher); err != nil {
+		return err
 	}
 
-	existingTeacher, err := s.teacherRepo.GetTeacherByID(teacher.ID)
+	existingTeacher, err := s.teacherRepo.GetTeacherByID(ctx, teacher.ID, false)
 	if err != nil {
-		if errors.Is(err, errors.New("professor não encontrado")) {
-			return fmt.Errorf("professor com ID %s não encontrado para atualização", teacher.ID)
+		if errors.Is(err, ErrTeacherNotFound) {
+			return fmt.Errorf("%w para atualização", ErrTeacherNotFound)
 		}
 		return fmt.Errorf("erro ao buscar professor existente para atualização: %w", err)
 	}
 
+	if existingTeacher.Version != teacher.Version {
+		return repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
+	before := *existingTeacher // Estado anterior para a auditoria
+
 	// Atualiza os campos do professor existente
 	existingTeacher.Name = teacher.Name
+	existingTeacher.DepartmentID = teacher.DepartmentID
 	existingTeacher.Department = teacher.Department
 	existingTeacher.Email = teacher.Email
+	if teacher.ContractType != "" { // Sem regime no corpo, mantém o atual
+		existingTeacher.ContractType = teacher.ContractType
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.teacherRepo.WithTx(tx).UpdateTeacher(ctx, existingTeacher); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, tx, AuditEntityTeacher, existingTeacher.ID, AuditActionUpdate, before, existingTeacher); err != nil {
+			return err
+		}
+		if existingTeacher.DepartmentID != before.DepartmentID {
+			return s.clearHeadOfOtherDepartments(ctx, tx, existingTeacher)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*teacher = *existingTeacher // Devolve o estado persistido (com a nova versão)
+	return nil
+}
+
+// PatchTeacher aplica uma atualização parcial (JSON Merge Patch) a um professor.
+// version é a versão conhecida pelo cliente (If-Match). Apenas os campos enviados e de fato
+// alterados são gravados; sem alterações, o professor é retornado sem nova versão.
+func (s *TeacherService) PatchTeacher(ctx context.Context, id string, version int, patch *models.TeacherPatch) (*models.Teacher, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.PatchTeacher")
+	defer span.End()
+	validation := &ValidationError{}
+	changes := map[string]interface{}{}
+	if patch.Name != nil {
+		if strings.TrimSpace(*patch.Name) == "" {
+			validation.add("name", "não pode ser vazio")
+		}
+		changes["name"] = *patch.Name
+	}
+	if patch.Email != nil {
+		if _, err := mail.ParseAddress(*patch.Email); err != nil {
+			validation.add("email", "endereço de email inválido")
+		}
+		changes["email"] = *patch.Email
+	}
+	if patch.DepartmentID != nil && strings.TrimSpace(*patch.DepartmentID) == "" {
+		validation.add("department_id", "não pode ser vazio")
+	}
+	if patch.Department != nil && strings.TrimSpace(*patch.Department) == "" {
+		validation.add("department", "não pode ser vazio")
+	}
+	if patch.ContractType != nil {
+		contract := models.Teacher{ContractType: *patch.ContractType}
+		if !validContractType(&contract) {
+			validation.add("contract_type", contractTypeMessage)
+		}
+		changes["contract_type"] = contract.ContractType
+	}
+	if err := validation.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	existingTeacher, err := s.teacherRepo.GetTeacherByID(ctx, id, false)
+	if err != nil {
+		if errors.Is(err, ErrTeacherNotFound) {
+			return nil, fmt.Errorf("%w para atualização", ErrTeacherNotFound)
+		}
+		return nil, fmt.Errorf("erro ao buscar professor existente para atualização: %w", err)
+	}
+	if existingTeacher.Version != version {
+		return nil, repositories.ErrVersionConflict // Alterado por outra requisição desde a leitura do cliente
+	}
 
-	return s.teacherRepo.UpdateTeacher(existingTeacher)
+	// Descarta campos enviados com o mesmo valor atual: só as colunas alteradas são gravadas.
+	if name, ok := changes["name"]; ok && name == existingTeacher.Name {
+		delete(changes, "name")
+	}
+	if email, ok := changes["email"]; ok && email == existingTeacher.Email {
+		delete(changes, "email")
+	}
+	if contractType, ok := changes["contract_type"]; ok && contractType == existingTeacher.ContractType {
+		delete(changes, "contract_type")
+	}
+	var department models.Teacher // Departamento de destino, se enviado
+	if patch.DepartmentID != nil || patch.Department != nil {
+		if patch.DepartmentID != nil {
+			department.DepartmentID = *patch.DepartmentID
+		} else {
+			department.Department = *patch.Department
+		}
+		if err := resolveTeacherDepartment(ctx, s.departmentRepo, &department); err != nil {
+			return nil, err
+		}
+		if department.DepartmentID != existingTeacher.DepartmentID {
+			changes["department_id"] = department.DepartmentID
+		}
+	}
+	if len(changes) == 0 {
+		return existingTeacher, nil
+	}
+
+	before := *existingTeacher // Estado anterior para a auditoria
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		newVersion, err := s.teacherRepo.WithTx(tx).PatchTeacher(ctx, id, version, changes)
+		if err != nil {
+			return err
+		}
+		if name, ok := changes["name"].(string); ok {
+			existingTeacher.Name = name
+		}
+		if email, ok := changes["email"].(string); ok {
+			existingTeacher.Email = email
+		}
+		if contractType, ok := changes["contract_type"].(string); ok {
+			existingTeacher.ContractType = contractType
+		}
+		if _, ok := changes["department_id"]; ok {
+			existingTeacher.DepartmentID = department.DepartmentID
+			existingTeacher.Department = department.Department
+		}
+		existingTeacher.Version = newVersion
+		if err := s.audit.Record(ctx, tx, AuditEntityTeacher, id, AuditActionUpdate, before, existingTeacher); err != nil {
+			return err
+		}
+		if _, ok := changes["department_id"]; ok {
+			return s.clearHeadOfOtherDepartments(ctx, tx, existingTeacher)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existingTeacher, nil
 }
 
-// DeleteTeacher implementa a exclusão de um professor.
-func (s *TeacherService) DeleteTeacher(id string) error {
-	err := s.teacherRepo.DeleteTeacher(id)
+// clearHeadOfOtherDepartments tira da chefia o professor que mudou de departamento: o chefe deve
+// ser sempre um professor do próprio departamento. Executa na transação tx da mudança de departamento.
+func (s *TeacherService) clearHeadOfOtherDepartments(ctx context.Context, tx *sql.Tx, teacher *models.Teacher) error {
+	cleared, err := s.departmentRepo.WithTx(tx).ClearHeadTeacher(ctx, teacher.ID, teacher.DepartmentID)
 	if err != nil {
-		if errors.Is(err, errors.New("professor não encontrado para exclusão")) {
-			return fmt.Errorf("professor com ID %s não encontrado para exclusão", id)
+		return fmt.Errorf("erro ao atualizar chefia de departamento: %w", err)
+	}
+	for _, departmentID := range cleared {
+		s.logger.InfoContext(ctx, "professor removido da chefia do departamento", "teacher_id", teacher.ID, "department_id", departmentID)
+		err := s.audit.Record(ctx, tx, AuditEntityDepartment, departmentID, AuditActionUpdate,
+			map[string]string{"head_teacher_id": teacher.ID}, map[string]string{"head_teacher_id": ""})
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("erro ao deletar professor: %w", err)
 	}
 	return nil
 }
 
-// AddSubjectToTeacher associa uma matéria a um professor.
-func (s *TeacherService) AddSubjectToTeacher(teacherID, subjectID string) error {
-	_, err := s.teacherRepo.GetTeacherByID(teacherID)
+// DeleteTeacher implementa a exclusão de um professor. version é a versão conhecida pelo cliente (If-Match).
+func (s *TeacherService) DeleteTeacher(ctx context.Context, id string, version int) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.DeleteTeacher")
+	defer span.End()
+	// Estado anterior para a auditoria; se o professor não existir, DeleteTeacher abaixo reporta o erro.
+	before, _ := s.teacherRepo.GetTeacherByID(ctx, id, false)
+
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		err := s.teacherRepo.WithTx(tx).DeleteTeacher(ctx, id, version)
+		if err != nil {
+			if errors.Is(err, ErrTeacherNotFound) {
+				return err
+			}
+			return fmt.Errorf("erro ao deletar professor: %w", err)
+		}
+		return s.audit.Record(ctx, tx, AuditEntityTeacher, id, AuditActionDelete, before, nil)
+	})
+}
+
+// RestoreTeacher desfaz a exclusão de um professor.
+func (s *TeacherService) RestoreTeacher(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.RestoreTeacher")
+	defer span.End()
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		teacherRepo := s.teacherRepo.WithTx(tx)
+		if err := teacherRepo.RestoreTeacher(ctx, id); err != nil {
+			return err
+		}
+		after, _ := teacherRepo.GetTeacherByID(ctx, id, false)
+		return s.audit.Record(ctx, tx, AuditEntityTeacher, id, AuditActionRestore, nil, after)
+	})
+}
+
+// AddSubjectToTeacher associa uma matéria a um professor e retorna a carga horária resultante.
+// Se ela passar do limite do regime do professor, a associação é recusada com ErrWorkloadExceeded
+// (política "reject") ou feita com Overloaded true, para o handler avisar o cliente (política "warn").
+func (s *TeacherService) AddSubjectToTeacher(ctx context.Context, teacherID, subjectID string) (*models.TeacherWorkload, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.AddSubjectToTeacher")
+	defer span.End()
+	teacher, err := s.teacherRepo.GetTeacherByID(ctx, teacherID, false)
 	if err != nil {
-		if errors.Is(err, errors.New("professor não encontrado")) {
-			return fmt.Errorf("professor com ID %s não encontrado para associação", teacherID)
+		if errors.Is(err, ErrTeacherNotFound) {
+			return nil, fmt.Errorf("%w para associação", ErrTeacherNotFound)
 		}
-		return fmt.Errorf("erro ao buscar professor para associação: %w", err)
+		return nil, fmt.Errorf("erro ao buscar professor para associação: %w", err)
 	}
 	// Supondo que GetSubjectByID no subjectRepo retorna (nil, nil) se não encontrar
-	subject, err := s.subjectRepo.GetSubjectByID(subjectID)
+	subject, err := s.subjectRepo.GetSubjectByID(ctx, subjectID, false)
 	if err != nil {
-		return fmt.Errorf("erro ao buscar matéria para associação: %w", err)
+		return nil, fmt.Errorf("erro ao buscar matéria para associação: %w", err)
 	}
 	if subject == nil {
-		return fmt.Errorf("matéria com ID %s não encontrada para associação", subjectID)
+		return nil, fmt.Errorf("matéria com ID %s não encontrada para associação", subjectID)
 	}
 
-	return s.teacherRepo.AddSubjectToTeacher(teacherID, subjectID)
+	// Carga horária com a nova matéria (se já associada, não muda)
+	workload := &models.TeacherWorkload{TeacherID: teacher.ID, TeacherName: teacher.Name, DepartmentID: teacher.DepartmentID,
+		Department: teacher.Department, ContractType: teacher.ContractType}
+	alreadyLinked := false
+	for _, current := range teacher.Subjects {
+		workload.Subjects++
+		workload.Credits += current.Credits
+		alreadyLinked = alreadyLinked || current.ID == subject.ID
+	}
+	if !alreadyLinked {
+		workload.Subjects++
+		workload.Credits += subject.Credits
+	}
+	s.workload.apply(workload)
+	if workload.Overloaded && !alreadyLinked {
+		if s.workload.Reject {
+			return nil, fmt.Errorf("%w: %s", ErrWorkloadExceeded, exceededMessage(workload))
+		}
+		s.logger.WarnContext(ctx, "professor acima da carga horária máxima", "teacher_id", teacherID, "subject_id", subjectID,
+			"weekly_hours", workload.WeeklyHours, "max_weekly_hours", workload.MaxWeeklyHours, "contract_type", workload.ContractType)
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := s.teacherRepo.WithTx(tx).AddSubjectToTeacher(ctx, teacherID, subjectID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, AuditEntityTeacher, teacherID, AuditActionAddSubject, nil, map[string]string{"subject_id": subjectID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workload, nil
 }
 
-// RemoveSubjectFromTeacher desassocia uma matéria de um professor.
-func (s *TeacherService) RemoveSubjectFromTeacher(teacherID, subjectID string) error {
-	err := s.teacherRepo.RemoveSubjectFromTeacher(teacherID, subjectID)
+// GetWorkloadReport calcula a carga horária semanal dos professores ativos, opcionalmente
+// filtrados por departamento (ID, código ou parte do nome).
+func (s *TeacherService) GetWorkloadReport(ctx context.Context, departmentFilter string) (*WorkloadReport, error) {
+	ctx, span := tracing.Start(ctx, "TeacherService.GetWorkloadReport")
+	defer span.End()
+	departmentFilter = strings.TrimSpace(departmentFilter)
+	workloads, err := s.teacherRepo.GetTeacherWorkloads(ctx, departmentFilter, nil)
 	if err != nil {
-		if errors.Is(err, errors.New("associação não encontrada para desassociação")) {
-			return fmt.Errorf("associação entre professor %s e matéria %s não encontrada para desassociação", teacherID, subjectID)
+		return nil, fmt.Errorf("erro ao calcular carga horária dos professores: %w", err)
+	}
+
+	report := &WorkloadReport{Department: departmentFilter, HoursPerCredit: s.workload.HoursPerCredit, Policy: "warn", Teachers: workloads}
+	if s.workload.Reject {
+		report.Policy = "reject"
+	}
+	for i := range report.Teachers {
+		s.workload.apply(&report.Teachers[i])
+		if report.Teachers[i].Overloaded {
+			report.Overloaded++
 		}
-		return fmt.Errorf("erro ao desassociar matéria do professor: %w", err)
 	}
-	return nil
+	return report, nil
+}
+
+// RemoveSubjectFromTeacher desassocia uma matéria de um professor.
+func (s *TeacherService) RemoveSubjectFromTeacher(ctx context.Context, teacherID, subjectID string) error {
+	ctx, span := tracing.Start(ctx, "TeacherService.RemoveSubjectFromTeacher")
+	defer span.End()
+	return s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		err := s.teacherRepo.WithTx(tx).RemoveSubjectFromTeacher(ctx, teacherID, subjectID)
+		if err != nil {
+			if errors.Is(err, errors.New("associação não encontrada para desassociação")) {
+				return fmt.Errorf("associação entre professor %s e matéria %s não encontrada para desassociação", teacherID, subjectID)
+			}
+			return fmt.Errorf("erro ao desassociar matéria do professor: %w", err)
+		}
+		return s.audit.Record(ctx, tx, AuditEntityTeacher, teacherID, AuditActionRemoveSubject, map[string]string{"subject_id": subjectID}, nil)
+	})
 }
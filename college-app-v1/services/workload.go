@@ -0,0 +1,40 @@
+// services/workload.go
+package services
+
+import (
+	"college-app-v1/models"
+	"errors"
+	"fmt"
+)
+
+// ErrWorkloadExceeded indica que a associação deixaria o professor acima da carga horária
+// máxima do seu regime de contratação, com a política "reject".
+var ErrWorkloadExceeded = errors.New("carga horária máxima excedida")
+
+// WorkloadPolicy define como a carga horária semanal dos professores é calculada e limitada.
+type WorkloadPolicy struct {
+	HoursPerCredit int            // Horas semanais por crédito de matéria
+	MaxWeeklyHours map[string]int // Limite por regime de contratação; ausente ou 0 = sem limite
+	Reject         bool           // Recusa associações acima do limite (senão apenas avisa)
+}
+
+// apply calcula horas, limite e sobrecarga de w a partir dos créditos.
+func (p WorkloadPolicy) apply(w *models.TeacherWorkload) {
+	w.WeeklyHours = w.Credits * p.HoursPerCredit
+	w.MaxWeeklyHours = p.MaxWeeklyHours[w.ContractType]
+	w.Overloaded = w.MaxWeeklyHours > 0 && w.WeeklyHours > w.MaxWeeklyHours
+}
+
+// exceededMessage descreve a sobrecarga de w (ex: "carga horária de 24h semanais acima do limite de 20h (integral)").
+func exceededMessage(w *models.TeacherWorkload) string {
+	return fmt.Sprintf("carga horária de %dh semanais acima do limite de %dh (%s)", w.WeeklyHours, w.MaxWeeklyHours, w.ContractType)
+}
+
+// WorkloadReport é o relatório de carga horária dos professores ativos.
+type WorkloadReport struct {
+	Department     string                   `json:"department,omitempty"` // Filtro aplicado
+	HoursPerCredit int                      `json:"hours_per_credit"`
+	Policy         string                   `json:"policy"` // "warn" ou "reject"
+	Overloaded     int                      `json:"overloaded"`
+	Teachers       []models.TeacherWorkload `json:"teachers"`
+}